@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"os"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -8,6 +10,14 @@ import (
 
 var JWTSecret = []byte(os.Getenv("JWT_SECRET"))
 
+// DerivedSecret returns a key for purpose derived from JWTSecret, so that other
+// signatures never share the key that signs login tokens
+func DerivedSecret(purpose string) []byte {
+	mac := hmac.New(sha256.New, JWTSecret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
 type Claims struct {
 	Id    uint   `json:"id"`
 	Email string `json:"email"`
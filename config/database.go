@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/farhapartex/real_estate_be/lib/money"
 	"github.com/farhapartex/real_estate_be/models"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
@@ -20,16 +21,7 @@ func ConnectDB() {
 		log.Fatal("Error loading env file")
 	}
 
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
-	)
-
-	db, dbErr := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, dbErr := gorm.Open(postgres.Open(DSN()), &gorm.Config{})
 
 	if dbErr != nil {
 		log.Fatal("Error connecting DB")
@@ -39,6 +31,18 @@ func ConnectDB() {
 	fmt.Println("DB connection successfull!")
 }
 
+// DSN is the Postgres connection string built from the DB_* variables
+func DSN() string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_NAME"),
+		os.Getenv("DB_PORT"),
+	)
+}
+
 func MigrateDB() {
 	fmt.Println("Running DB migration ...")
 
@@ -49,8 +53,52 @@ func MigrateDB() {
 		&models.Country{},
 		&models.Division{},
 		&models.District{},
+		&models.Project{},
 		&models.Property{},
 		&models.PropertyFeature{},
+		&models.PropertyStatusHistory{},
+		&models.PropertyMedia{},
+		&models.PropertyMediaRendition{},
+		&models.WatermarkSetting{},
+		&models.PropertyAttachment{},
+		&models.PropertyDailyStat{},
+		&models.PropertyViewVisitor{},
+		&models.Inquiry{},
+		&models.InquiryReply{},
+		&models.Conversation{},
+		&models.ConversationParticipant{},
+		&models.Message{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.Favorite{},
+		&models.SavedSearch{},
+		&models.SavedSearchMatch{},
+		&models.PropertyPriceChange{},
+		&models.AuditLog{},
+		&models.PropertyImport{},
+		&models.PropertyImportRow{},
+		&models.Partner{},
+		&models.ExchangeRate{},
+		&models.PropertyRentalTerms{},
+		&models.StreamTicket{},
+	}
+
+	// "pending" was renamed to "pending_review" when the status check constraint was added
+	if DB.Migrator().HasTable(&models.Property{}) {
+		DB.Exec("UPDATE properties SET status = ? WHERE status = ?", models.StatusPendingReview, "pending")
+	}
+
+	// Photos could only belong to a listing before projects had galleries
+	if DB.Migrator().HasTable(&models.PropertyMedia{}) {
+		DB.Exec("ALTER TABLE property_media ALTER COLUMN property_id DROP NOT NULL")
+	}
+
+	// Prices were all in DEFAULT_CURRENCY before listings and saved searches had a currency
+	for _, table := range []string{"properties", "saved_searches"} {
+		if DB.Migrator().HasTable(table) && !DB.Migrator().HasColumn(table, "currency") {
+			DB.Exec("ALTER TABLE " + table + " ADD COLUMN currency varchar(3)")
+			DB.Exec("UPDATE "+table+" SET currency = ?", money.DefaultCurrency())
+		}
 	}
 
 	for _, model := range dbModels {
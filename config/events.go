@@ -0,0 +1,34 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/farhapartex/real_estate_be/lib/events"
+)
+
+// NewEventHub creates the real-time event hub. EVENTS_BACKEND picks how events
+// reach the other API instances: "memory" (default, single instance) or "postgres".
+func NewEventHub() *events.Hub {
+	var backend events.Backend
+
+	switch os.Getenv("EVENTS_BACKEND") {
+	case "", "memory":
+		backend = events.NewMemoryBackend()
+	case "postgres":
+		sqlDB, err := DB.DB()
+		if err != nil {
+			log.Fatal("Error getting DB handle for events: ", err)
+		}
+		backend = events.NewPostgresBackend(sqlDB, DSN(), "app_events")
+	default:
+		log.Fatal("Unknown EVENTS_BACKEND: ", os.Getenv("EVENTS_BACKEND"))
+	}
+
+	hub, err := events.NewHub(backend)
+	if err != nil {
+		log.Fatal("Error starting event hub: ", err)
+	}
+
+	return hub
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/farhapartex/real_estate_be/lib/sms"
+)
+
+// NewSMSSender picks the SMS provider from SMS_PROVIDER. Without one, messages
+// are only logged.
+func NewSMSSender() sms.Sender {
+	switch os.Getenv("SMS_PROVIDER") {
+	case "":
+		return sms.LogSender{}
+	case "twilio":
+		accountSID := os.Getenv("TWILIO_ACCOUNT_SID")
+		authToken := os.Getenv("TWILIO_AUTH_TOKEN")
+		from := os.Getenv("TWILIO_FROM_NUMBER")
+		if accountSID == "" || authToken == "" || from == "" {
+			log.Fatal("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER must be set for the twilio SMS provider")
+		}
+		return sms.NewTwilioSender(accountSID, authToken, from)
+	default:
+		log.Fatal("Unknown SMS_PROVIDER: ", os.Getenv("SMS_PROVIDER"))
+		return nil
+	}
+}
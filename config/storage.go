@@ -0,0 +1,50 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	s3client "github.com/farhapartex/real_estate_be/lib/aws"
+	"github.com/farhapartex/real_estate_be/lib/storage"
+)
+
+// NewStorage picks the file storage backend from STORAGE_BACKEND ("s3" or "local").
+// Without it, S3 is used when AWS_S3_BUCKET is set and the local disk otherwise.
+func NewStorage() storage.Storage {
+	backend := os.Getenv("STORAGE_BACKEND")
+	if backend == "" {
+		backend = "local"
+		if os.Getenv("AWS_S3_BUCKET") != "" {
+			backend = "s3"
+		}
+	}
+
+	switch backend {
+	case "s3":
+		client := s3client.NewS3ClientFromEnv()
+		if client == nil {
+			log.Fatal("AWS_S3_BUCKET must be set for the s3 storage backend")
+		}
+		return storage.NewS3Storage(client)
+	case "local":
+		root := os.Getenv("LOCAL_STORAGE_PATH")
+		if root == "" {
+			root = "storage"
+		}
+
+		baseURL := os.Getenv("LOCAL_STORAGE_BASE_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:" + os.Getenv("port")
+		}
+
+		secret := []byte(os.Getenv("STORAGE_SIGNING_SECRET"))
+		if len(secret) == 0 {
+			secret = DerivedSecret("storage-url")
+		}
+
+		return storage.NewLocalStorage(root, baseURL, secret)
+	default:
+		log.Fatal("Unknown STORAGE_BACKEND: ", backend)
+		return nil
+	}
+}
@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/storage"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+// Downloads are handed out per request, so the URL only has to live long enough
+// for the browser to follow it
+const attachmentDownloadURLExpiry = 5 * time.Minute
+
+var attachmentExtensions = map[string]string{
+	"application/pdf": "pdf",
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+	"image/webp":      "webp",
+}
+
+// maxPropertyAttachments is the per-listing limit, MAX_PROPERTY_ATTACHMENTS (default 20)
+func maxPropertyAttachments() int64 {
+	return envInt64("MAX_PROPERTY_ATTACHMENTS", 20)
+}
+
+// maxPropertyAttachmentSize is the per-file limit, MAX_PROPERTY_ATTACHMENT_SIZE_MB (default 25)
+func maxPropertyAttachmentSize() int64 {
+	return envInt64("MAX_PROPERTY_ATTACHMENT_SIZE_MB", 25) * 1024 * 1024
+}
+
+func (c *AuthController) PropertyAttachmentList(propertyId uint32, userId uint) ([]dto.PropertyAttachmentDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []models.PropertyAttachment
+	err = c.DB.Where("property_id = ? AND status = ?", property.ID, models.MediaUploaded).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, errors.New("Failed to fetch property attachments")
+	}
+
+	responseDTOs := []dto.PropertyAttachmentDTO{}
+	for _, attachment := range attachments {
+		responseDTOs = append(responseDTOs, mapper.PropertyAttachmentToDTO(attachment, true))
+	}
+
+	return responseDTOs, nil
+}
+
+// CreatePropertyAttachmentUpload reserves an attachment and returns a presigned
+// URL to upload the file to. The attachment is listed once the upload is confirmed.
+func (c *AuthController) CreatePropertyAttachmentUpload(propertyId uint32, userId uint, request dto.PropertyAttachmentUploadRequestDTO) (*dto.PropertyAttachmentUploadResponseDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	extension, ok := attachmentExtensions[request.ContentType]
+	if !ok {
+		return nil, errors.New("Unsupported file type")
+	}
+
+	if request.SizeBytes > maxPropertyAttachmentSize() {
+		return nil, fmt.Errorf("File must not be larger than %d MB", maxPropertyAttachmentSize()/1024/1024)
+	}
+
+	// Unconfirmed uploads hold a slot until their upload URL expires
+	var count int64
+	c.DB.Model(&models.PropertyAttachment{}).
+		Where("property_id = ? AND (status = ? OR created_at > ?)", property.ID, models.MediaUploaded, time.Now().Add(-mediaUploadURLExpiry)).
+		Count(&count)
+	if count >= maxPropertyAttachments() {
+		return nil, fmt.Errorf("A property can have at most %d attachments", maxPropertyAttachments())
+	}
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, errors.New("Failed to prepare upload")
+	}
+
+	attachment := models.PropertyAttachment{
+		PropertyID:  property.ID,
+		Kind:        models.AttachmentKind(request.Kind),
+		Title:       strings.TrimSpace(request.Title),
+		FileName:    path.Base(strings.ReplaceAll(strings.TrimSpace(request.FileName), "\\", "/")),
+		S3Key:       fmt.Sprintf("properties/%d/attachments/%s.%s", property.ID, hex.EncodeToString(suffix), extension),
+		ContentType: request.ContentType,
+		SizeBytes:   request.SizeBytes,
+		Visibility:  models.AttachmentVisibility(request.Visibility),
+		Status:      models.MediaPending,
+	}
+
+	uploadURL, err := c.Storage.PresignUpload(context.Background(), attachment.S3Key, attachment.ContentType, attachment.SizeBytes, mediaUploadURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.DB.Create(&attachment).Error; err != nil {
+		return nil, errors.New("Failed to prepare upload")
+	}
+
+	response := dto.PropertyAttachmentUploadResponseDTO{
+		Attachment: mapper.PropertyAttachmentToDTO(attachment, true),
+		UploadURL:  uploadURL,
+		ExpiresAt:  time.Now().Add(mediaUploadURLExpiry).Format("2006-01-02 15:04:05"),
+	}
+
+	return &response, nil
+}
+
+// ConfirmPropertyAttachmentUpload checks the uploaded file and publishes the
+// attachment. The file content is sniffed so that an executable uploaded with a
+// PDF content type is thrown away instead of being served to buyers.
+func (c *AuthController) ConfirmPropertyAttachmentUpload(propertyId uint32, attachmentId uint, userId uint) (*dto.PropertyAttachmentDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachment models.PropertyAttachment
+	if err := c.DB.Where("property_id = ? AND id = ?", property.ID, attachmentId).First(&attachment).Error; err != nil {
+		return nil, errors.New("Attachment not found")
+	}
+
+	if attachment.Status == models.MediaUploaded {
+		response := mapper.PropertyAttachmentToDTO(attachment, true)
+		return &response, nil
+	}
+
+	info, err := c.Storage.Head(context.Background(), attachment.S3Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, errors.New("File has not been uploaded yet")
+		}
+		return nil, err
+	}
+
+	if info.Size != attachment.SizeBytes {
+		return nil, errors.New("Uploaded file size does not match")
+	}
+
+	detected, err := c.sniffContentType(attachment.S3Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if detected != attachment.ContentType {
+		if err := c.Storage.Delete(context.Background(), attachment.S3Key); err != nil {
+			log.Printf("Failed to delete rejected attachment %s: %v", attachment.S3Key, err)
+		}
+		c.DB.Unscoped().Delete(&attachment)
+		return nil, fmt.Errorf("File content does not match %s", attachment.ContentType)
+	}
+
+	if err := c.DB.Model(&attachment).Update("status", models.MediaUploaded).Error; err != nil {
+		return nil, errors.New("Failed to confirm upload")
+	}
+
+	response := mapper.PropertyAttachmentToDTO(attachment, true)
+	return &response, nil
+}
+
+// sniffContentType detects the type of a stored file from its first 512 bytes
+func (c *AuthController) sniffContentType(key string) (string, error) {
+	body, err := c.Storage.Get(context.Background(), key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", errors.New("Failed to read uploaded file")
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	return contentType, nil
+}
+
+func (c *AuthController) UpdatePropertyAttachment(propertyId uint32, attachmentId uint, userId uint, request dto.PropertyAttachmentUpdateRequestDTO) (*dto.PropertyAttachmentDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachment models.PropertyAttachment
+	if err := c.DB.Where("property_id = ? AND id = ?", property.ID, attachmentId).First(&attachment).Error; err != nil {
+		return nil, errors.New("Attachment not found")
+	}
+
+	err = c.DB.Model(&attachment).Updates(map[string]interface{}{
+		"title":      strings.TrimSpace(request.Title),
+		"visibility": request.Visibility,
+	}).Error
+	if err != nil {
+		return nil, errors.New("Failed to update attachment")
+	}
+
+	response := mapper.PropertyAttachmentToDTO(attachment, true)
+	return &response, nil
+}
+
+func (c *AuthController) DeletePropertyAttachment(propertyId uint32, attachmentId uint, userId uint) error {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return err
+	}
+
+	var attachment models.PropertyAttachment
+	if err := c.DB.Where("property_id = ? AND id = ?", property.ID, attachmentId).First(&attachment).Error; err != nil {
+		return errors.New("Attachment not found")
+	}
+
+	if err := c.Storage.Delete(context.Background(), attachment.S3Key); err != nil {
+		return err
+	}
+
+	if err := c.DB.Unscoped().Delete(&attachment).Error; err != nil {
+		return errors.New("Failed to delete attachment")
+	}
+
+	return nil
+}
+
+// PublicPropertyAttachments lists the attachments of a live listing, telling the
+// viewer which of them they may download. user is nil for anonymous visitors.
+func (c *AuthController) PublicPropertyAttachments(propertyId uint32, user *models.User) ([]dto.PropertyAttachmentDTO, error) {
+	var property models.Property
+	if err := c.DB.Where("status IN ?", models.SearchableStatuses).First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	var attachments []models.PropertyAttachment
+	err := c.DB.Where("property_id = ? AND status = ?", property.ID, models.MediaUploaded).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, errors.New("Failed to fetch property attachments")
+	}
+
+	responseDTOs := []dto.PropertyAttachmentDTO{}
+	for _, attachment := range attachments {
+		responseDTOs = append(responseDTOs, mapper.PropertyAttachmentToDTO(attachment, c.canDownloadAttachment(property, attachment, user)))
+	}
+
+	return responseDTOs, nil
+}
+
+// PropertyAttachmentDownload returns a short-lived download URL if user may see
+// the attachment. Owners and admins can download any attachment of a property,
+// everyone else only those of live listings.
+func (c *AuthController) PropertyAttachmentDownload(propertyId uint32, attachmentId uint, user *models.User) (*dto.PropertyAttachmentDownloadDTO, error) {
+	var property models.Property
+	if err := c.DB.First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	if !isPropertyManager(property, user) && !property.Status.IsSearchable() {
+		return nil, errors.New("Property not found")
+	}
+
+	var attachment models.PropertyAttachment
+	err := c.DB.Where("property_id = ? AND id = ? AND status = ?", property.ID, attachmentId, models.MediaUploaded).
+		First(&attachment).Error
+	if err != nil {
+		return nil, errors.New("Attachment not found")
+	}
+
+	if !c.canDownloadAttachment(property, attachment, user) {
+		return nil, errors.New("You do not have access to this attachment")
+	}
+
+	url, err := c.Storage.PresignDownload(context.Background(), attachment.S3Key, attachmentDownloadURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PropertyAttachmentDownloadDTO{
+		URL:       url,
+		ExpiresAt: time.Now().Add(attachmentDownloadURLExpiry).Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+func (c *AuthController) canDownloadAttachment(property models.Property, attachment models.PropertyAttachment, user *models.User) bool {
+	if isPropertyManager(property, user) {
+		return true
+	}
+
+	switch attachment.Visibility {
+	case models.VisibilityPublic:
+		return true
+	case models.VisibilityRegistered:
+		return user != nil
+	case models.VisibilityInquiry:
+		return user != nil && c.hasAnsweredInquiry(property.ID, user.ID)
+	default:
+		return false
+	}
+}
+
+func isPropertyManager(property models.Property, user *models.User) bool {
+	return user != nil && (user.ID == property.OwnerID || user.IsAdmin())
+}
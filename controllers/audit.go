@@ -0,0 +1,337 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+type auditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// recordAudit stores entry with the fields that differ between the before and
+// after snapshots. before is nil for creations and after is nil for deletions.
+// Updates that change nothing are not recorded.
+func recordAudit(tx *gorm.DB, entry models.AuditLog, before, after interface{}) error {
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return errors.New("Failed to record audit trail")
+	}
+
+	if len(changes) == 0 && entry.Action == models.AuditActionUpdate {
+		return nil
+	}
+
+	snapshot := after
+	if entry.Action == models.AuditActionDelete {
+		snapshot = before
+	}
+
+	if entry.Changes, err = json.Marshal(changes); err != nil {
+		return errors.New("Failed to record audit trail")
+	}
+	if entry.Snapshot, err = json.Marshal(snapshot); err != nil {
+		return errors.New("Failed to record audit trail")
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return errors.New("Failed to record audit trail")
+	}
+
+	return nil
+}
+
+// auditChanges compares two snapshots field by field through their JSON form
+func auditChanges(before, after interface{}) (map[string]auditChange, error) {
+	oldFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	newFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]auditChange{}
+	for field, value := range newFields {
+		if old, ok := oldFields[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = auditChange{Old: oldFields[field], New: value}
+		}
+	}
+
+	for field, value := range oldFields {
+		if _, ok := newFields[field]; !ok {
+			changes[field] = auditChange{Old: value}
+		}
+	}
+
+	return changes, nil
+}
+
+func auditFields(snapshot interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if snapshot == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// PropertyAuditLog lists every recorded change of a listing, its features and
+// rental terms, newest first
+func (c *AuthController) PropertyAuditLog(propertyId uint32, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var property models.Property
+	if err := c.DB.Unscoped().First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	var entries []models.AuditLog
+	var total int64
+
+	query := c.DB.Model(&models.AuditLog{}).Where("property_id = ?", property.ID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting audit records")
+	}
+
+	offset := (page - 1) * pageSize
+
+	err := query.Preload("Actor").
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&entries).Error
+	if err != nil {
+		return nil, errors.New("error retrieving audit records")
+	}
+
+	responseDTOs := []dto.AuditLogDTO{}
+	for _, entry := range entries {
+		responseDTOs = append(responseDTOs, mapper.AuditLogToDTO(entry))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+// RestorePropertyVersion writes the version stored in an audit record back to
+// the listing, its features or rental terms. The restore is itself recorded, so it can be undone.
+func (c *AuthController) RestorePropertyVersion(propertyId uint32, auditId uint32, admin models.User, requestID string) (*dto.PropertyResponseDTO, error) {
+	var entry models.AuditLog
+	if err := c.DB.Where("property_id = ?", propertyId).First(&entry, auditId).Error; err != nil {
+		return nil, errors.New("Audit record not found")
+	}
+
+	if len(entry.Snapshot) == 0 || string(entry.Snapshot) == "null" {
+		return nil, errors.New("This audit record has no version to restore")
+	}
+
+	var property models.Property
+	if err := c.DB.First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	var priceChange *models.PropertyPriceChange
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		switch entry.EntityType {
+		case models.AuditEntityProperty:
+			priceChange, err = restorePropertySnapshot(tx, property, entry, admin.ID, requestID)
+		case models.AuditEntityPropertyFeature:
+			err = restorePropertyFeatureSnapshot(tx, property, entry, admin.ID, requestID)
+		case models.AuditEntityRentalTerms:
+			err = restoreRentalTermsSnapshot(tx, property, entry, admin.ID, requestID)
+		default:
+			err = errors.New("This audit record cannot be restored")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if priceChange != nil && property.Status.IsSearchable() {
+		property.Price = priceChange.NewPrice
+		c.notifyFavoritePriceChange(property, *priceChange)
+		if priceChange.IsDrop() {
+			c.publishPriceDrop(PriceDrop{Property: property, Change: *priceChange})
+		}
+	}
+
+	err = c.DB.Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		Preload("PriceHistory", priceHistoryPreload).
+		Preload("RentalTerms").
+		Scopes(projectPreload).
+		First(&property, propertyId).Error
+	if err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	response := mapper.PropertyModelToDetailsResponseDTOMapper(property)
+	return &response, nil
+}
+
+func restorePropertySnapshot(tx *gorm.DB, property models.Property, entry models.AuditLog, adminId uint, requestID string) (*models.PropertyPriceChange, error) {
+	var snapshot models.PropertySnapshot
+	if err := json.Unmarshal(entry.Snapshot, &snapshot); err != nil {
+		return nil, errors.New("This audit record cannot be restored")
+	}
+
+	if snapshot.Currency != "" && snapshot.Currency != property.Currency && property.ApprovedAt != nil {
+		return nil, errors.New("This version is in another currency and cannot be restored on an approved listing")
+	}
+
+	before := models.NewPropertySnapshot(property)
+
+	priceChange, err := recordPriceChange(tx, property, snapshot.Price, adminId)
+	if err != nil {
+		return nil, errors.New("Failed to restore property")
+	}
+
+	columns := snapshot.RestoreColumns()
+
+	// A project unit keeps the project's location, whatever the old version said
+	if property.ProjectID != nil {
+		var project models.Project
+		err := tx.First(&project, *property.ProjectID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Failed to restore property")
+		}
+		if err == nil {
+			columns["country_id"] = project.CountryID
+			columns["division_id"] = project.DivisionID
+			columns["district_id"] = project.DistrictID
+			columns["address"] = project.Address
+			columns["latitude"] = project.Latitude
+			columns["longitude"] = project.Longitude
+		}
+	}
+
+	if err := tx.Model(&property).Updates(columns).Error; err != nil {
+		return nil, errors.New("Failed to restore property")
+	}
+
+	var restored models.Property
+	if err := tx.First(&restored, property.ID).Error; err != nil {
+		return nil, errors.New("Failed to restore property")
+	}
+
+	audit := models.AuditLog{
+		PropertyID:     property.ID,
+		EntityType:     models.AuditEntityProperty,
+		EntityID:       property.ID,
+		Action:         models.AuditActionRestore,
+		ActorID:        &adminId,
+		RequestID:      requestID,
+		RestoredFromID: &entry.ID,
+	}
+	if err := recordAudit(tx, audit, before, models.NewPropertySnapshot(restored)); err != nil {
+		return nil, err
+	}
+
+	// A version that was not for rent takes the rental terms away
+	if _, err := syncRentalTerms(tx, restored, nil, adminId, requestID); err != nil {
+		return nil, err
+	}
+
+	return priceChange, nil
+}
+
+// restorePropertyFeatureSnapshot brings the feature row the audit record is
+// about back to the stored version, undeleting it if it was removed since and
+// recreating it only when the row is gone for good
+func restorePropertyFeatureSnapshot(tx *gorm.DB, property models.Property, entry models.AuditLog, adminId uint, requestID string) error {
+	var snapshot models.PropertyFeatureSnapshot
+	if err := json.Unmarshal(entry.Snapshot, &snapshot); err != nil {
+		return errors.New("This audit record cannot be restored")
+	}
+
+	var feature models.PropertyFeature
+	var before interface{}
+
+	err := tx.Unscoped().Where("id = ? AND property_id = ?", entry.EntityID, property.ID).First(&feature).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		feature = models.PropertyFeature{PropertyID: property.ID}
+	} else if err != nil {
+		return errors.New("Failed to restore property feature")
+	} else if feature.DeletedAt.Valid {
+		feature.DeletedAt = gorm.DeletedAt{}
+	} else {
+		before = models.NewPropertyFeatureSnapshot(feature)
+	}
+
+	snapshot.Apply(&feature)
+	if err := tx.Unscoped().Save(&feature).Error; err != nil {
+		return errors.New("Failed to restore property feature")
+	}
+
+	audit := models.AuditLog{
+		PropertyID:     property.ID,
+		EntityType:     models.AuditEntityPropertyFeature,
+		EntityID:       feature.ID,
+		Action:         models.AuditActionRestore,
+		ActorID:        &adminId,
+		RequestID:      requestID,
+		RestoredFromID: &entry.ID,
+	}
+	return recordAudit(tx, audit, before, models.NewPropertyFeatureSnapshot(feature))
+}
+
+// restoreRentalTermsSnapshot brings the rental terms back to the stored
+// version, recreating them if they were removed since
+func restoreRentalTermsSnapshot(tx *gorm.DB, property models.Property, entry models.AuditLog, adminId uint, requestID string) error {
+	if property.Purpose != models.PurposeRent {
+		return errors.New("Rental terms can only be restored on a rent listing")
+	}
+
+	var snapshot models.RentalTermsSnapshot
+	if err := json.Unmarshal(entry.Snapshot, &snapshot); err != nil {
+		return errors.New("This audit record cannot be restored")
+	}
+
+	var terms models.PropertyRentalTerms
+	var before interface{}
+
+	err := tx.Where("property_id = ?", property.ID).First(&terms).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		terms = models.PropertyRentalTerms{PropertyID: property.ID}
+	} else if err != nil {
+		return errors.New("Failed to restore rental terms")
+	} else {
+		before = models.NewRentalTermsSnapshot(terms)
+	}
+
+	snapshot.Apply(&terms)
+	if err := tx.Save(&terms).Error; err != nil {
+		return errors.New("Failed to restore rental terms")
+	}
+
+	audit := models.AuditLog{
+		PropertyID:     property.ID,
+		EntityType:     models.AuditEntityRentalTerms,
+		EntityID:       terms.ID,
+		Action:         models.AuditActionRestore,
+		ActorID:        &adminId,
+		RequestID:      requestID,
+		RestoredFromID: &entry.ID,
+	}
+	return recordAudit(tx, audit, before, models.NewRentalTermsSnapshot(terms))
+}
@@ -13,6 +13,9 @@ import (
 
 	"github.com/farhapartex/real_estate_be/config"
 	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/events"
+	"github.com/farhapartex/real_estate_be/lib/sms"
+	"github.com/farhapartex/real_estate_be/lib/storage"
 	"github.com/farhapartex/real_estate_be/mapper"
 	"github.com/farhapartex/real_estate_be/models"
 	"github.com/farhapartex/real_estate_be/utils"
@@ -22,13 +25,33 @@ import (
 )
 
 type AuthController struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Storage storage.Storage
+	Events  *events.Hub
+	SMS     sms.Sender
+
+	// mediaQueue wakes the image processing workers, see StartMediaProcessing
+	mediaQueue chan struct{}
+	// importQueue wakes the property import worker, see StartPropertyImports
+	importQueue chan struct{}
+	// viewBuffer collects listing views until they are flushed, see StartViewTracking
+	viewBuffer *propertyViewBuffer
+	// priceDropHandlers are the subscribers of OnPriceDrop
+	priceDropHandlers []func(PriceDrop)
 }
 
 func NewAuthController(db *gorm.DB) *AuthController {
-	return &AuthController{
-		DB: db,
+	controller := &AuthController{
+		DB:         db,
+		mediaQueue: make(chan struct{}, 1),
+		viewBuffer: newPropertyViewBuffer(),
+
+		importQueue: make(chan struct{}, 1),
 	}
+
+	controller.OnPriceDrop(controller.rematchPriceDrop)
+
+	return controller
 }
 
 func (c *AuthController) Login(request dto.LoginRequestDTO) (*dto.LoginResponseDTO, error) {
@@ -148,6 +171,7 @@ func (c *AuthController) UserMeData(ctx *gin.Context) (*dto.UserMeDTO, error) {
 	}
 
 	response := mapper.UserToMeResponse(userMode)
+	response.UnreadNotifications = c.UnreadNotificationCount(userMode.ID)
 	return &response, nil
 }
 
@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/farhapartex/real_estate_be/utils"
+	"gorm.io/gorm"
+)
+
+// maskMessageContacts hides phone numbers and emails in messages until both sides
+// agree to share them. Set MASK_MESSAGE_CONTACTS=false to turn it off.
+func maskMessageContacts() bool {
+	return os.Getenv("MASK_MESSAGE_CONTACTS") != "false"
+}
+
+// StartConversation opens (or reuses) the customer's conversation about a
+// property and posts the first message
+func (c *AuthController) StartConversation(user models.User, request dto.ConversationStartRequestDTO) (*dto.ConversationDTO, error) {
+	var property models.Property
+	if err := c.DB.Where("status IN ?", models.SearchableStatuses).First(&property, request.PropertyID).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	if property.OwnerID == user.ID {
+		return nil, errors.New("You cannot start a conversation about your own property")
+	}
+
+	var conversation models.Conversation
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("property_id = ? AND customer_id = ?", property.ID, user.ID).First(&conversation).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		conversation = models.Conversation{
+			PropertyID: property.ID,
+			CustomerID: user.ID,
+			Participants: []models.ConversationParticipant{
+				{UserID: user.ID, Role: models.ParticipantCustomer},
+				{UserID: property.OwnerID, Role: models.ParticipantOwner},
+			},
+		}
+		return tx.Create(&conversation).Error
+	})
+	if err != nil {
+		return nil, errors.New("Failed to start conversation")
+	}
+
+	if _, err := c.SendMessage(user.ID, conversation.ID, dto.MessageRequestDTO{Body: request.Message}); err != nil {
+		return nil, err
+	}
+
+	return c.Conversation(user.ID, conversation.ID)
+}
+
+// Conversations lists the user's conversations, most recent activity first
+func (c *AuthController) Conversations(userId uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var conversations []models.Conversation
+	var total int64
+
+	query := c.DB.Model(&models.Conversation{}).
+		Where("id IN (?)", c.DB.Model(&models.ConversationParticipant{}).Select("conversation_id").Where("user_id = ?", userId))
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting conversations")
+	}
+
+	offset := (page - 1) * pageSize
+
+	err := query.Preload("Property").
+		Preload("Participants.User").
+		Order("last_message_at DESC NULLS LAST, id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&conversations).Error
+	if err != nil {
+		return nil, errors.New("error retrieving conversations")
+	}
+
+	responseDTOs := []dto.ConversationDTO{}
+	for _, conversation := range conversations {
+		responseDTOs = append(responseDTOs, c.conversationToDTO(conversation, userId))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+func (c *AuthController) Conversation(userId uint, conversationId uint) (*dto.ConversationDTO, error) {
+	conversation, _, _, err := c.userConversation(userId, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := c.conversationToDTO(conversation, userId)
+	return &response, nil
+}
+
+// ConversationMessages pages through a conversation, newest message first
+func (c *AuthController) ConversationMessages(userId uint, conversationId uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	conversation, me, other, err := c.userConversation(userId, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []models.Message
+	var total int64
+
+	query := c.DB.Model(&models.Message{}).Where("conversation_id = ?", conversation.ID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting messages")
+	}
+
+	offset := (page - 1) * pageSize
+
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&messages).Error; err != nil {
+		return nil, errors.New("error retrieving messages")
+	}
+
+	hideContacts := contactsHidden(me, other)
+
+	responseDTOs := []dto.MessageDTO{}
+	for _, message := range messages {
+		responseDTOs = append(responseDTOs, mapper.MessageToDTO(message, userId, hideContacts, messageRead(message, me, other)))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+// SendMessage posts a sanitized message and bumps the other side's unread count
+func (c *AuthController) SendMessage(userId uint, conversationId uint, request dto.MessageRequestDTO) (*dto.MessageDTO, error) {
+	conversation, me, other, err := c.userConversation(userId, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	body := utils.SanitizeMessage(request.Body)
+	if body == "" {
+		return nil, errors.New("message is required")
+	}
+
+	message := models.Message{
+		ConversationID: conversation.ID,
+		SenderID:       userId,
+		Body:           body,
+		HasContactInfo: utils.ContainsContactInfo(body),
+	}
+
+	now := time.Now()
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&conversation).Update("last_message_at", now).Error; err != nil {
+			return err
+		}
+
+		// Writing in a conversation means having read it
+		err := tx.Model(&me).Updates(map[string]interface{}{
+			"unread_count":         0,
+			"last_read_message_id": message.ID,
+			"last_read_at":         now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&other).Update("unread_count", gorm.Expr("unread_count + 1")).Error
+	})
+	if err != nil {
+		return nil, errors.New("Failed to send message")
+	}
+
+	// The recipient sees the message the way their own message list would show it
+	received := mapper.MessageToDTO(message, other.UserID, contactsHidden(other, me), false)
+	c.notify(userRecipient(other.User), notification{
+		Type:  models.NotificationMessageReceived,
+		Title: fmt.Sprintf("New message from %s about %s", me.User.FirstName, conversation.Property.Title),
+		Body:  received.Body,
+		Data: map[string]interface{}{
+			"conversation_id": conversation.ID,
+			"property_id":     conversation.PropertyID,
+			"message":         received,
+		},
+	})
+
+	response := mapper.MessageToDTO(message, userId, contactsHidden(me, other), false)
+	return &response, nil
+}
+
+// MarkConversationRead moves the user's read receipt to the latest message
+func (c *AuthController) MarkConversationRead(userId uint, conversationId uint) (*dto.ConversationDTO, error) {
+	conversation, me, _, err := c.userConversation(userId, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastMessageId uint
+	c.DB.Model(&models.Message{}).
+		Where("conversation_id = ?", conversation.ID).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&lastMessageId)
+
+	err = c.DB.Model(&me).Updates(map[string]interface{}{
+		"unread_count":         0,
+		"last_read_message_id": lastMessageId,
+		"last_read_at":         time.Now(),
+	}).Error
+	if err != nil {
+		return nil, errors.New("Failed to update read state")
+	}
+
+	return c.Conversation(userId, conversationId)
+}
+
+// SetContactSharing records whether the user agrees to show contact details.
+// They are only shown once both sides agreed.
+func (c *AuthController) SetContactSharing(userId uint, conversationId uint, request dto.ContactSharingRequestDTO) (*dto.ConversationDTO, error) {
+	_, me, _, err := c.userConversation(userId, conversationId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.DB.Model(&me).Update("share_contact", request.Share).Error; err != nil {
+		return nil, errors.New("Failed to update contact sharing")
+	}
+
+	return c.Conversation(userId, conversationId)
+}
+
+func (c *AuthController) UnreadMessageCount(userId uint) (*dto.UnreadCountDTO, error) {
+	var unread int64
+	err := c.DB.Model(&models.ConversationParticipant{}).
+		Where("user_id = ?", userId).
+		Select("COALESCE(SUM(unread_count), 0)").
+		Scan(&unread).Error
+	if err != nil {
+		return nil, errors.New("Failed to count unread messages")
+	}
+
+	return &dto.UnreadCountDTO{Unread: unread}, nil
+}
+
+// userConversation loads a conversation the user takes part in, with both sides
+func (c *AuthController) userConversation(userId uint, conversationId uint) (models.Conversation, models.ConversationParticipant, models.ConversationParticipant, error) {
+	var conversation models.Conversation
+	var me, other models.ConversationParticipant
+
+	err := c.DB.Preload("Property").Preload("Participants.User").First(&conversation, conversationId).Error
+	if err != nil {
+		return conversation, me, other, errors.New("Conversation not found")
+	}
+
+	found := false
+	for _, participant := range conversation.Participants {
+		if participant.UserID == userId {
+			me = participant
+			found = true
+		} else {
+			other = participant
+		}
+	}
+
+	if !found {
+		return conversation, me, other, errors.New("Conversation not found")
+	}
+
+	return conversation, me, other, nil
+}
+
+func (c *AuthController) conversationToDTO(conversation models.Conversation, userId uint) dto.ConversationDTO {
+	var me, other models.ConversationParticipant
+	for _, participant := range conversation.Participants {
+		if participant.UserID == userId {
+			me = participant
+		} else {
+			other = participant
+		}
+	}
+
+	var lastMessage *dto.MessageDTO
+	var message models.Message
+	if err := c.DB.Where("conversation_id = ?", conversation.ID).Order("id DESC").First(&message).Error; err == nil {
+		response := mapper.MessageToDTO(message, userId, contactsHidden(me, other), messageRead(message, me, other))
+		lastMessage = &response
+	}
+
+	return mapper.ConversationToDTO(conversation, userId, lastMessage)
+}
+
+func contactsHidden(me, other models.ConversationParticipant) bool {
+	return maskMessageContacts() && !(me.ShareContact && other.ShareContact)
+}
+
+// messageRead tells whether the recipient of a message has read it
+func messageRead(message models.Message, me, other models.ConversationParticipant) bool {
+	if message.SenderID == me.UserID {
+		return other.LastReadMessageID >= message.ID
+	}
+	return me.LastReadMessageID >= message.ID
+}
@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+// ErrUnsupportedCurrency is returned for a currency without an exchange rate
+var ErrUnsupportedCurrency = errors.New("Unsupported currency, it has no exchange rate")
+
+// normalizeCurrency upper-cases a currency code; an empty one is the default currency
+func normalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return money.DefaultCurrency()
+	}
+	return code
+}
+
+// exchangeRates are the rates in effect at one moment, by currency. The base
+// currency is worth 1 and has no row.
+type exchangeRates struct {
+	base  string
+	rates map[string]models.ExchangeRate
+}
+
+// currentExchangeRates loads the latest rate of every currency that is in effect
+func (c *AuthController) currentExchangeRates() (*exchangeRates, error) {
+	base := money.DefaultCurrency()
+
+	var rates []models.ExchangeRate
+	err := c.DB.Select("DISTINCT ON (currency) *").
+		Where("base_currency = ? AND effective_at <= ?", base, time.Now()).
+		Order("currency, effective_at DESC, id DESC").
+		Find(&rates).Error
+	if err != nil {
+		return nil, errors.New("Failed to load exchange rates")
+	}
+
+	current := &exchangeRates{base: base, rates: map[string]models.ExchangeRate{}}
+	for _, rate := range rates {
+		current.rates[rate.Currency] = rate
+	}
+	return current, nil
+}
+
+// supports reports whether prices can be in or converted to the currency
+func (r *exchangeRates) supports(currency string) bool {
+	_, ok := r.toBase(normalizeCurrency(currency))
+	return ok
+}
+
+// currencies are the base currency and every currency with a rate
+func (r *exchangeRates) currencies() []string {
+	currencies := []string{r.base}
+	for currency := range r.rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies[1:])
+	return currencies
+}
+
+func (r *exchangeRates) toBase(currency string) (money.Rate, bool) {
+	if currency == r.base {
+		return money.One(), true
+	}
+	rate, ok := r.rates[currency]
+	if !ok {
+		return money.Rate{}, false
+	}
+	return rate.Rate, true
+}
+
+// rate is what one unit of from is worth in to, with the rates it is made of
+func (r *exchangeRates) rate(from, to string) (money.Rate, []models.ExchangeRate, bool) {
+	from, to = normalizeCurrency(from), normalizeCurrency(to)
+
+	fromBase, ok := r.toBase(from)
+	if !ok {
+		return money.Rate{}, nil, false
+	}
+	toBase, ok := r.toBase(to)
+	if !ok {
+		return money.Rate{}, nil, false
+	}
+
+	var used []models.ExchangeRate
+	if from != to {
+		for _, currency := range []string{from, to} {
+			if rate, ok := r.rates[currency]; ok {
+				used = append(used, rate)
+			}
+		}
+	}
+
+	return fromBase.Quo(toBase), used, true
+}
+
+// convert converts an amount, reporting false when either currency has no rate
+func (r *exchangeRates) convert(amount money.Amount, from, to string) (money.Amount, bool) {
+	rate, _, ok := r.rate(from, to)
+	if !ok {
+		return 0, false
+	}
+	if normalizeCurrency(from) == normalizeCurrency(to) {
+		return amount, true
+	}
+	return amount.Convert(rate), true
+}
+
+// displayPrice converts a listing price for display, recording the rates used.
+// nil when the listing currency has no rate anymore.
+func (r *exchangeRates) displayPrice(price money.Amount, from, to string) *dto.DisplayPriceDTO {
+	rate, used, ok := r.rate(from, to)
+	if !ok {
+		return nil
+	}
+	amount, _ := r.convert(price, from, to)
+
+	display := &dto.DisplayPriceDTO{
+		Amount:          amount,
+		Currency:        normalizeCurrency(to),
+		Rate:            rate,
+		ExchangeRateIDs: []uint{},
+	}
+
+	var asOf *time.Time
+	for i, exchangeRate := range used {
+		display.ExchangeRateIDs = append(display.ExchangeRateIDs, exchangeRate.ID)
+		if asOf == nil || exchangeRate.EffectiveAt.Before(*asOf) {
+			asOf = &used[i].EffectiveAt
+		}
+	}
+	display.RatesAsOf = mapper.FormatOptionalTime(asOf)
+
+	return display
+}
+
+// amountRangeCondition filters listings on a range of an amount column, such
+// as the price, given in currency. The bounds are converted to each listing
+// currency, so the condition stays on the stored amounts; listings in a
+// currency without a rate never match.
+func (r *exchangeRates) amountRangeCondition(column string, minAmount, maxAmount money.Amount, currency string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	for _, listingCurrency := range r.currencies() {
+		condition := "(properties.currency = ?"
+		args = append(args, listingCurrency)
+
+		if minAmount > 0 {
+			bound, _ := r.convert(minAmount, currency, listingCurrency)
+			condition += " AND " + column + " >= ?"
+			args = append(args, bound)
+		}
+		if maxAmount > 0 {
+			bound, _ := r.convert(maxAmount, currency, listingCurrency)
+			condition += " AND " + column + " <= ?"
+			args = append(args, bound)
+		}
+
+		conditions = append(conditions, condition+")")
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// amountCondition compares an amount column with an amount given in currency,
+// converted to each listing currency the same way as amountRangeCondition. op
+// is the SQL comparison operator.
+func (r *exchangeRates) amountCondition(column, op string, amount money.Amount, currency string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	for _, listingCurrency := range r.currencies() {
+		bound, _ := r.convert(amount, currency, listingCurrency)
+		conditions = append(conditions, "(properties.currency = ? AND "+column+" "+op+" ?)")
+		args = append(args, listingCurrency, bound)
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// ExchangeRates returns the rates in effect now, for the currency pickers
+func (c *AuthController) ExchangeRates() (*dto.ExchangeRateListDTO, error) {
+	rates, err := c.currentExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ExchangeRateListDTO{BaseCurrency: rates.base, Rates: []dto.ExchangeRateDTO{}}
+	for _, currency := range rates.currencies()[1:] {
+		response.Rates = append(response.Rates, mapper.ExchangeRateToDTO(rates.rates[currency]))
+	}
+	return response, nil
+}
+
+// ExchangeRateHistory lists every rate that was set, newest first, for one
+// currency or all of them
+func (c *AuthController) ExchangeRateHistory(currency string, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var rates []models.ExchangeRate
+	var total int64
+
+	query := c.DB.Model(&models.ExchangeRate{}).Where("base_currency = ?", money.DefaultCurrency())
+	if currency != "" {
+		query = query.Where("currency = ?", strings.ToUpper(strings.TrimSpace(currency)))
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting exchange rates")
+	}
+
+	offset := (page - 1) * pageSize
+
+	err := query.Preload("CreatedBy").
+		Order("effective_at DESC, id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&rates).Error
+	if err != nil {
+		return nil, errors.New("error retrieving exchange rates")
+	}
+
+	responseDTOs := []dto.ExchangeRateDTO{}
+	for _, rate := range rates {
+		responseDTOs = append(responseDTOs, mapper.ExchangeRateToDTO(rate))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+// CreateExchangeRate sets a new rate for a currency. The previous rate stays in
+// the history; prices convert at the new one from its effective time on.
+func (c *AuthController) CreateExchangeRate(request dto.ExchangeRateRequestDTO, admin models.User) (*dto.ExchangeRateDTO, error) {
+	currency := strings.ToUpper(strings.TrimSpace(request.Currency))
+	if !money.ValidCurrency(currency) {
+		return nil, errors.New("currency must be a three letter ISO 4217 code")
+	}
+	if currency == money.DefaultCurrency() {
+		return nil, errors.New("the base currency is always worth 1")
+	}
+	if request.Rate.IsZero() {
+		return nil, money.ErrInvalidRate
+	}
+
+	effectiveAt := time.Now()
+	if request.EffectiveAt != nil {
+		effectiveAt = *request.EffectiveAt
+	}
+
+	rate := models.ExchangeRate{
+		BaseCurrency: money.DefaultCurrency(),
+		Currency:     currency,
+		Rate:         request.Rate,
+		EffectiveAt:  effectiveAt,
+		CreatedByID:  &admin.ID,
+	}
+	if err := c.DB.Create(&rate).Error; err != nil {
+		return nil, errors.New("Failed to save exchange rate")
+	}
+	rate.CreatedBy = &admin
+
+	response := mapper.ExchangeRateToDTO(rate)
+	return &response, nil
+}
+
+// supportedCurrency normalizes a currency code and checks it has an exchange rate
+func (c *AuthController) supportedCurrency(code string) (string, error) {
+	currency := normalizeCurrency(code)
+
+	rates, err := c.currentExchangeRates()
+	if err != nil {
+		return "", err
+	}
+	if !rates.supports(currency) {
+		return "", ErrUnsupportedCurrency
+	}
+	return currency, nil
+}
+
+// listingCurrency checks the currency asked for a new listing, or a change to
+// property. An empty one keeps the current currency or uses the default one.
+// Price history compares prices in one currency, so it cannot change once the
+// listing was approved.
+func (c *AuthController) listingCurrency(requested string, property *models.Property) (string, error) {
+	if strings.TrimSpace(requested) == "" && property != nil {
+		return property.Currency, nil
+	}
+
+	currency := normalizeCurrency(requested)
+	if property != nil && currency == property.Currency {
+		return currency, nil
+	}
+
+	currency, err := c.supportedCurrency(currency)
+	if err != nil {
+		return "", err
+	}
+
+	if property != nil && property.ApprovedAt != nil {
+		return "", errors.New("the currency cannot change once the listing has been approved")
+	}
+	return currency, nil
+}
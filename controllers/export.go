@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/filters"
+	"github.com/farhapartex/real_estate_be/lib/spreadsheet"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+// exportFlushRows is how many rows are written between flushes of the response
+const exportFlushRows = 500
+
+// Export is a spreadsheet that is written straight to the response. Rows are
+// read from a database cursor while writing, so the export is never held in memory.
+type Export struct {
+	FileName    string
+	ContentType string
+	write       func(ctx context.Context, w io.Writer) error
+}
+
+// Write streams the export to w. Once it has started the response status can no
+// longer change, so a failure part way through leaves a truncated file.
+func (e *Export) Write(ctx context.Context, w io.Writer) error {
+	return e.write(ctx, w)
+}
+
+// exportColumns returns the indexes of the requested comma separated columns,
+// in the requested order, or every column when none are requested
+func exportColumns(requested string, names []string) ([]int, error) {
+	var indexes []int
+	if strings.TrimSpace(requested) == "" {
+		for i := range names {
+			indexes = append(indexes, i)
+		}
+		return indexes, nil
+	}
+
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for i, column := range names {
+			if column == name {
+				indexes = append(indexes, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q, available columns: %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	if len(indexes) == 0 {
+		return nil, errors.New("no columns selected")
+	}
+	return indexes, nil
+}
+
+func exportFormat(format string) (string, error) {
+	if format == "" {
+		return spreadsheet.FormatCSV, nil
+	}
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		return "", errors.New("format must be csv or xlsx")
+	}
+	return format, nil
+}
+
+// newExport builds an export that runs query and writes a row per result,
+// turned into cells by scan
+func newExport(name string, request dto.ExportRequestDTO, names []string, query *gorm.DB, scan func(rows *sql.Rows) ([]interface{}, error)) (*Export, error) {
+	format, err := exportFormat(request.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := exportColumns(request.Columns, names)
+	if err != nil {
+		return nil, err
+	}
+
+	write := func(ctx context.Context, w io.Writer) error {
+		writer, err := spreadsheet.NewWriter(format, w)
+		if err != nil {
+			return err
+		}
+
+		selected := make([]interface{}, len(indexes))
+		for i, index := range indexes {
+			selected[i] = names[index]
+		}
+		if err := writer.WriteRow(selected); err != nil {
+			return err
+		}
+
+		rows, err := query.WithContext(ctx).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		written := 0
+		for rows.Next() {
+			cells, err := scan(rows)
+			if err != nil {
+				return err
+			}
+
+			for i, index := range indexes {
+				selected[i] = cells[index]
+			}
+			if err := writer.WriteRow(selected); err != nil {
+				return err
+			}
+
+			written++
+			if written%exportFlushRows == 0 {
+				if err := writer.Flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return writer.Close()
+	}
+
+	return &Export{
+		FileName:    fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format),
+		ContentType: spreadsheet.ContentType(format),
+		write: func(ctx context.Context, w io.Writer) error {
+			err := write(ctx, w)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Failed to write %s export: %v", name, err)
+			}
+			return err
+		},
+	}, nil
+}
+
+// propertyExportColumns are the columns of a listings export, in their default order
+var propertyExportColumns = []string{
+	"id", "external_ref", "title", "purpose", "status", "price", "currency", "property_type",
+	"bedrooms", "bathrooms", "size", "built_year", "country", "division", "district",
+	"address", "latitude", "longitude", "distance_km", "description", "created_at", "updated_at",
+}
+
+// ExportProperties exports the listings matched by the same filters as
+// GetProperties, in the same order. Paging is ignored: every match is exported.
+func (c *AuthController) ExportProperties(filter dto.PropertyFilterDTO, request dto.ExportRequestDTO) (*Export, error) {
+	rates, err := c.currentExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+
+	query, center, err := c.propertyFilterQuery(filter, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	if center != nil {
+		query = query.Select("properties.*, "+haversineSQL+" AS distance", center.Lat, center.Lat, center.Lng).
+			Order("distance ASC")
+	}
+	query = query.Order("created_at DESC")
+
+	countries, divisions, districts, err := c.locationNames()
+	if err != nil {
+		return nil, errors.New("Failed to export properties")
+	}
+
+	scan := func(rows *sql.Rows) ([]interface{}, error) {
+		var property models.Property
+		if err := c.DB.ScanRows(rows, &property); err != nil {
+			return nil, err
+		}
+
+		return []interface{}{
+			property.ID,
+			optionalString(property.ExternalRef),
+			property.Title,
+			string(property.Purpose),
+			string(property.Status),
+			property.Price.Float64(),
+			property.Currency,
+			property.PropertyType,
+			property.Bedrooms,
+			property.Bathrooms,
+			property.Size,
+			property.BuiltYear,
+			countries[property.CountryID],
+			divisions[property.DivisionID],
+			districts[property.DistrictID],
+			property.Address,
+			optionalFloat(property.Latitude),
+			optionalFloat(property.Longitude),
+			optionalFloat(property.Distance),
+			property.Description,
+			property.CreatedAt,
+			property.UpdatedAt,
+		}, nil
+	}
+
+	return newExport("properties", request, propertyExportColumns, query, scan)
+}
+
+// locationNames returns the names of all countries, divisions and districts by ID
+func (c *AuthController) locationNames() (map[uint32]string, map[uint32]string, map[uint32]string, error) {
+	names := make([]map[uint32]string, 3)
+
+	for i, model := range []interface{}{&models.Country{}, &models.Division{}, &models.District{}} {
+		var locations []struct {
+			ID   uint32
+			Name string
+		}
+		if err := c.DB.Model(model).Select("id, name").Find(&locations).Error; err != nil {
+			return nil, nil, nil, err
+		}
+
+		names[i] = make(map[uint32]string, len(locations))
+		for _, location := range locations {
+			names[i][location.ID] = location.Name
+		}
+	}
+
+	return names[0], names[1], names[2], nil
+}
+
+// userExportRow is a user with the contact details of their owner profile
+type userExportRow struct {
+	models.User
+	CompanyName *string
+	PhoneNumber *string
+}
+
+// userExportColumns are the columns of a users export, in their default order
+var userExportColumns = []string{
+	"id", "first_name", "last_name", "email", "role", "status", "email_verified",
+	"company_name", "phone_number", "joined_at", "last_login_at", "verified_at",
+}
+
+// ExportUsers exports the users matched by the same filters and sorting as GetSystemAllUsers
+func (c *AuthController) ExportUsers(filterDTO dto.UserFilterDTO, request dto.ExportRequestDTO) (*Export, error) {
+	filterManager := filters.NewUserFilterManager(filterDTO)
+
+	// The profile columns come from subqueries rather than a join, so the
+	// filter and sort columns stay unambiguous
+	query := c.DB.Model(&models.User{}).Select("users.*, " +
+		"(SELECT company_name FROM owner_profiles WHERE owner_profiles.user_id = users.id ORDER BY owner_profiles.id LIMIT 1) AS company_name, " +
+		"(SELECT phone_number FROM owner_profiles WHERE owner_profiles.user_id = users.id ORDER BY owner_profiles.id LIMIT 1) AS phone_number")
+	query = filterManager.Apply(query, filterDTO)
+
+	scan := func(rows *sql.Rows) ([]interface{}, error) {
+		var user userExportRow
+		if err := c.DB.ScanRows(rows, &user); err != nil {
+			return nil, err
+		}
+
+		return []interface{}{
+			user.ID,
+			user.FirstName,
+			user.LastName,
+			user.Email,
+			string(user.Role),
+			user.Status,
+			user.EmailVerified,
+			optionalString(user.CompanyName),
+			optionalString(user.PhoneNumber),
+			user.JoinedAt,
+			optionalTime(user.LastLoginAt),
+			optionalTime(user.VerifiedAt),
+		}, nil
+	}
+
+	return newExport("users", request, userExportColumns, query, scan)
+}
+
+// optionalString, optionalFloat and optionalTime turn a missing value into an empty cell
+func optionalString(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func optionalFloat(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func optionalTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm/clause"
+)
+
+// Favorited listings are followed until they leave the market, or come back to it
+var favoriteStatusMessages = map[models.PropertyStatus]string{
+	models.StatusUnderOffer: "is now under offer",
+	models.StatusSold:       "has been sold",
+	models.StatusRented:     "has been rented",
+	models.StatusExpired:    "is no longer listed",
+	models.StatusArchived:   "is no longer listed",
+}
+
+// AddFavorite shortlists a live listing for the user. Adding it twice is a no-op.
+func (c *AuthController) AddFavorite(userId uint, propertyId uint32) (*dto.FavoriteStatusDTO, error) {
+	var property models.Property
+	if err := c.DB.Where("status IN ?", models.SearchableStatuses).First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	favorite := models.Favorite{UserID: userId, PropertyID: property.ID}
+	if err := c.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite).Error; err != nil {
+		return nil, errors.New("Failed to add favorite")
+	}
+
+	return &dto.FavoriteStatusDTO{PropertyID: property.ID, IsFavorited: true}, nil
+}
+
+func (c *AuthController) RemoveFavorite(userId uint, propertyId uint32) error {
+	err := c.DB.Where("user_id = ? AND property_id = ?", userId, propertyId).Delete(&models.Favorite{}).Error
+	if err != nil {
+		return errors.New("Failed to remove favorite")
+	}
+
+	return nil
+}
+
+// Favorites lists the user's shortlist, most recently added first. Listings that
+// went off the market stay in the list so buyers can see what happened to them.
+func (c *AuthController) Favorites(userId uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var favorites []models.Favorite
+	var total int64
+
+	query := c.DB.Model(&models.Favorite{}).
+		Joins("JOIN properties ON properties.id = favorites.property_id AND properties.deleted_at IS NULL").
+		Where("favorites.user_id = ?", userId)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting favorites")
+	}
+
+	offset := (page - 1) * pageSize
+
+	err := query.Preload("Property.Country").
+		Preload("Property.Division").
+		Preload("Property.District").
+		Preload("Property.Media", galleryPreload).
+		Preload("Property.Media.Renditions").
+		Order("favorites.created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&favorites).Error
+	if err != nil {
+		return nil, errors.New("error retrieving favorites")
+	}
+
+	properties := make([]models.Property, 0, len(favorites))
+	for _, favorite := range favorites {
+		properties = append(properties, favorite.Property)
+	}
+	c.loadPropertyFavorites(properties, userId)
+	c.loadPropertyOriginalPrices(properties)
+
+	responseDTOs := []dto.PropertyListDTO{}
+	for _, property := range properties {
+		responseDTOs = append(responseDTOs, mapper.PropertyModelToResponseDTOMapper(property))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+func (c *AuthController) isFavorite(userId uint, propertyId uint) bool {
+	var count int64
+	c.DB.Model(&models.Favorite{}).Where("user_id = ? AND property_id = ?", userId, propertyId).Count(&count)
+	return count > 0
+}
+
+// loadPropertyFavorites sets the favorite count on each property and flags the
+// ones viewerId favorited. viewerId is 0 for anonymous visitors.
+func (c *AuthController) loadPropertyFavorites(properties []models.Property, viewerId uint) {
+	if len(properties) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(properties))
+	for _, property := range properties {
+		ids = append(ids, property.ID)
+	}
+
+	var totals []struct {
+		PropertyID uint
+		Total      int64
+	}
+	err := c.DB.Model(&models.Favorite{}).
+		Select("property_id, COUNT(*) AS total").
+		Where("property_id IN ?", ids).
+		Group("property_id").
+		Scan(&totals).Error
+	if err != nil {
+		log.Printf("Failed to load property favorites: %v", err)
+		return
+	}
+
+	counts := map[uint]int64{}
+	for _, total := range totals {
+		counts[total.PropertyID] = total.Total
+	}
+
+	favorited := map[uint]bool{}
+	if viewerId > 0 {
+		var favoriteIds []uint
+		c.DB.Model(&models.Favorite{}).
+			Where("user_id = ? AND property_id IN ?", viewerId, ids).
+			Pluck("property_id", &favoriteIds)
+		for _, id := range favoriteIds {
+			favorited[id] = true
+		}
+	}
+
+	for i := range properties {
+		properties[i].Favorites = counts[properties[i].ID]
+		properties[i].IsFavorited = favorited[properties[i].ID]
+	}
+}
+
+// notifyFavoritePriceChange tells everyone who favorited the property about a
+// new price, up or down
+func (c *AuthController) notifyFavoritePriceChange(property models.Property, change models.PropertyPriceChange) {
+	direction := "increased"
+	if change.IsDrop() {
+		direction = "dropped"
+	}
+
+	body := fmt.Sprintf("The price of %s %s from %s to %s %s.", property.Title, direction, change.OldPrice, change.NewPrice, change.Currency)
+	if change.IsDrop() && change.OldPrice > 0 {
+		percent := (change.OldPrice - change.NewPrice).Float64() / change.OldPrice.Float64() * 100
+		body = fmt.Sprintf("The price of %s dropped by %.0f%% from %s to %s %s.", property.Title, percent, change.OldPrice, change.NewPrice, change.Currency)
+	}
+
+	c.notifyFavoriters(property, notification{
+		Type:  models.NotificationFavoritePrice,
+		Title: fmt.Sprintf("Price %s on a saved listing", direction),
+		Body:  body,
+		Data: map[string]interface{}{
+			"property_id": property.ID,
+			"old_price":   change.OldPrice,
+			"price":       change.NewPrice,
+			"currency":    change.Currency,
+		},
+	})
+}
+
+// notifyFavoriteStatusChange tells everyone who favorited the property when it
+// leaves the market or becomes available again
+func (c *AuthController) notifyFavoriteStatusChange(property models.Property, from, to models.PropertyStatus) {
+	message, ok := favoriteStatusMessages[to]
+	if !ok {
+		// Only a listing that was under offer is "back"; going live after a review
+		// is not news to someone who favorited it while it was live
+		if to != models.StatusActive || from != models.StatusUnderOffer {
+			return
+		}
+		message = "is available again"
+	}
+
+	c.notifyFavoriters(property, notification{
+		Type:  models.NotificationFavoriteStatus,
+		Title: "Update on a saved listing",
+		Body:  fmt.Sprintf("%s %s.", property.Title, message),
+		Data: map[string]interface{}{
+			"property_id": property.ID,
+			"status":      to,
+		},
+	})
+}
+
+// notifyFavoriters sends n to each user who favorited the property. It runs in
+// the background since popular listings can have many followers.
+func (c *AuthController) notifyFavoriters(property models.Property, n notification) {
+	go func() {
+		var users []models.User
+		err := c.DB.Where("id IN (?)", c.DB.Model(&models.Favorite{}).Select("user_id").Where("property_id = ?", property.ID)).
+			Where("status = ?", "active").
+			Find(&users).Error
+		if err != nil {
+			log.Printf("Failed to load users who favorited property %d: %v", property.ID, err)
+			return
+		}
+
+		for _, user := range users {
+			c.notify(userRecipient(user), n)
+		}
+	}()
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/farhapartex/real_estate_be/utils"
+	"gorm.io/gorm"
+)
+
+const maxSearchRadiusKm = 500
+
+// haversineSQL computes the distance in km from properties.latitude/longitude to
+// a point. Placeholders are (lat, lat, lng). Plain SQL keeps us off PostGIS.
+const haversineSQL = `(6371 * 2 * ASIN(LEAST(1, SQRT(
+	POWER(SIN(RADIANS(properties.latitude - ?) / 2), 2) +
+	COS(RADIANS(?)) * COS(RADIANS(properties.latitude)) *
+	POWER(SIN(RADIANS(properties.longitude - ?) / 2), 2)))))`
+
+// applyGeoFilters adds the near/radius_km and bbox filters to a property query.
+// When near is given the parsed center is returned so the caller can sort by distance.
+func applyGeoFilters(query *gorm.DB, filter dto.PropertyFilterDTO) (*gorm.DB, *utils.LatLng, error) {
+	if filter.BBox != "" {
+		box, err := utils.ParseBoundingBox(filter.BBox)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		query = query.Where("properties.latitude BETWEEN ? AND ? AND properties.longitude BETWEEN ? AND ?",
+			box.MinLat, box.MaxLat, box.MinLng, box.MaxLng)
+	}
+
+	if filter.Near == "" {
+		if filter.RadiusKm > 0 {
+			return nil, nil, errors.New("radius_km requires near")
+		}
+		return query, nil, nil
+	}
+
+	center, err := utils.ParseLatLng(filter.Near)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query = query.Where("properties.latitude IS NOT NULL AND properties.longitude IS NOT NULL")
+
+	if filter.RadiusKm < 0 || filter.RadiusKm > maxSearchRadiusKm {
+		return nil, nil, errors.New("radius_km must be between 0 and 500")
+	}
+
+	if filter.RadiusKm > 0 {
+		// Narrow down with the (latitude, longitude) index before the exact distance check
+		box := utils.BoundingBoxAround(center, filter.RadiusKm)
+		query = query.Where("properties.latitude BETWEEN ? AND ? AND properties.longitude BETWEEN ? AND ?",
+			box.MinLat, box.MaxLat, box.MinLng, box.MaxLng).
+			Where(haversineSQL+" <= ?", center.Lat, center.Lat, center.Lng, filter.RadiusKm)
+	}
+
+	return query, &center, nil
+}
+
+// validatePropertyCoordinates checks that latitude and longitude come as a pair and,
+// when the district has a configured area, that the point lies within it. The
+// area check is optional: districts have no area until an admin sets one, and
+// until then any valid coordinates are accepted.
+func validatePropertyCoordinates(latitude, longitude *float64, district models.District) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+
+	if latitude == nil || longitude == nil {
+		return errors.New("latitude and longitude must be provided together")
+	}
+
+	if !utils.ValidCoordinates(*latitude, *longitude) {
+		return errors.New("coordinates are out of range")
+	}
+
+	if district.Latitude == nil || district.Longitude == nil || district.RadiusKm == nil {
+		return nil
+	}
+
+	distance := utils.HaversineKm(
+		utils.LatLng{Lat: *district.Latitude, Lng: *district.Longitude},
+		utils.LatLng{Lat: *latitude, Lng: *longitude},
+	)
+	if distance > *district.RadiusKm {
+		return errors.New("coordinates are outside the selected district")
+	}
+
+	return nil
+}
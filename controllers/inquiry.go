@@ -0,0 +1,318 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// inquiriesPerIPPerHour limits how many inquiries one address can send
+	inquiriesPerIPPerHour = 5
+	// maxInquiryLinks rejects messages that are mostly links
+	maxInquiryLinks = 2
+)
+
+var inquiryLinkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// CreateInquiry stores a buyer's inquiry and emails the owner. user is nil for
+// guests, who must leave a name and email. A nil response without error means
+// the inquiry was dropped as spam; callers answer as if it was sent.
+func (c *AuthController) CreateInquiry(propertyId uint32, user *models.User, clientIP string, request dto.InquiryRequestDTO) (*dto.InquiryDTO, error) {
+	if request.Website != "" {
+		log.Printf("Dropped inquiry for property %d: honeypot filled", propertyId)
+		return nil, nil
+	}
+
+	var property models.Property
+	if err := c.DB.Preload("Owner").Where("status IN ?", models.SearchableStatuses).First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	inquiry := models.Inquiry{
+		PropertyID: property.ID,
+		Property:   property,
+		OwnerID:    property.OwnerID,
+		Name:       strings.TrimSpace(request.Name),
+		Email:      strings.ToLower(strings.TrimSpace(request.Email)),
+		Phone:      strings.TrimSpace(request.Phone),
+		Message:    strings.TrimSpace(request.Message),
+		Status:     models.InquiryNew,
+		IPHash:     hashIdentifier(clientIP),
+	}
+
+	if user != nil {
+		if user.ID == property.OwnerID {
+			return nil, errors.New("You cannot send an inquiry for your own property")
+		}
+
+		inquiry.SenderID = &user.ID
+		inquiry.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		inquiry.Email = user.Email
+	}
+
+	if inquiry.Name == "" || inquiry.Email == "" {
+		return nil, errors.New("name and email are required")
+	}
+
+	if len(inquiryLinkPattern.FindAllString(inquiry.Message, -1)) > maxInquiryLinks {
+		return nil, errors.New("Message contains too many links")
+	}
+
+	var recent int64
+	c.DB.Model(&models.Inquiry{}).
+		Where("ip_hash = ? AND created_at > ?", inquiry.IPHash, time.Now().Add(-time.Hour)).
+		Count(&recent)
+	if recent >= inquiriesPerIPPerHour {
+		return nil, errors.New("Too many inquiries, please try again later")
+	}
+
+	var duplicates int64
+	c.DB.Model(&models.Inquiry{}).
+		Where("property_id = ? AND email = ? AND created_at > ?", property.ID, inquiry.Email, time.Now().Add(-24*time.Hour)).
+		Count(&duplicates)
+	if duplicates > 0 {
+		return nil, errors.New("You have already sent an inquiry for this property")
+	}
+
+	if err := c.DB.Create(&inquiry).Error; err != nil {
+		return nil, errors.New("Failed to send inquiry")
+	}
+
+	c.notify(userRecipient(property.Owner), notification{
+		Type:  models.NotificationInquiryReceived,
+		Title: "New inquiry for your listing",
+		Body:  fmt.Sprintf("%s sent an inquiry about %s.", inquiry.Name, property.Title),
+		Data: map[string]interface{}{
+			"inquiry_id":  inquiry.ID,
+			"property_id": property.ID,
+		},
+		EmailTemplate: "inquiry",
+		EmailData: map[string]interface{}{
+			"Headline":      "New inquiry for your listing",
+			"Intro":         "You have received a new inquiry. You can reply from your inbox.",
+			"PropertyTitle": property.Title,
+			"SenderName":    inquiry.Name,
+			"SenderEmail":   inquiry.Email,
+			"SenderPhone":   inquiry.Phone,
+			"Body":          inquiry.Message,
+			"Quote":         "",
+		},
+	})
+
+	response := mapper.InquiryToDTO(inquiry)
+	return &response, nil
+}
+
+// OwnerInquiries is the owner's inbox, newest first
+func (c *AuthController) OwnerInquiries(ownerId uint, filter dto.InquiryFilterDTO, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var inquiries []models.Inquiry
+	var total int64
+
+	query := c.DB.Model(&models.Inquiry{}).Where("owner_id = ?", ownerId)
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.PropertyID > 0 {
+		query = query.Where("property_id = ?", filter.PropertyID)
+	}
+
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR message ILIKE ?", pattern, pattern, pattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting inquiries")
+	}
+
+	offset := (page - 1) * pageSize
+
+	err := query.Preload("Property").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&inquiries).Error
+	if err != nil {
+		return nil, errors.New("error retrieving inquiries")
+	}
+
+	responseDTOs := []dto.InquiryDTO{}
+	for _, inquiry := range inquiries {
+		responseDTOs = append(responseDTOs, mapper.InquiryToDTO(inquiry))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+func (c *AuthController) OwnerInquiry(ownerId uint, inquiryId uint) (*dto.InquiryDTO, error) {
+	inquiry, err := c.ownerInquiry(ownerId, inquiryId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapper.InquiryToDTO(inquiry)
+	return &response, nil
+}
+
+// ReplyToInquiry records the owner's answer and emails it to the sender
+func (c *AuthController) ReplyToInquiry(owner models.User, inquiryId uint, request dto.InquiryReplyRequestDTO) (*dto.InquiryDTO, error) {
+	inquiry, err := c.ownerInquiry(owner.ID, inquiryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if inquiry.Status == models.InquiryClosed {
+		return nil, errors.New("Inquiry is closed")
+	}
+
+	reply := models.InquiryReply{
+		InquiryID: inquiry.ID,
+		AuthorID:  owner.ID,
+		Message:   strings.TrimSpace(request.Message),
+	}
+	if reply.Message == "" {
+		return nil, errors.New("message is required")
+	}
+
+	now := time.Now()
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reply).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&inquiry).Updates(map[string]interface{}{
+			"status":     models.InquiryReplied,
+			"replied_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, errors.New("Failed to send reply")
+	}
+
+	inquiry.Replies = append(inquiry.Replies, reply)
+
+	ownerName := strings.TrimSpace(owner.FirstName + " " + owner.LastName)
+
+	// Guests have no account and are only emailed
+	recipient := notificationRecipient{
+		UserID: inquiry.SenderID,
+		Name:   inquiry.Name,
+		Email:  inquiry.Email,
+		Phone:  inquiry.Phone,
+	}
+	c.notify(recipient, notification{
+		Type:  models.NotificationInquiryReplied,
+		Title: fmt.Sprintf("Reply to your inquiry about %s", inquiry.Property.Title),
+		Body:  fmt.Sprintf("%s replied to your inquiry.", ownerName),
+		Data: map[string]interface{}{
+			"inquiry_id":  inquiry.ID,
+			"property_id": inquiry.PropertyID,
+		},
+		EmailTemplate: "inquiry",
+		EmailData: map[string]interface{}{
+			"Headline":      "You have a reply",
+			"Intro":         fmt.Sprintf("%s replied to your inquiry.", ownerName),
+			"PropertyTitle": inquiry.Property.Title,
+			"SenderName":    "",
+			"SenderEmail":   "",
+			"SenderPhone":   "",
+			"Body":          reply.Message,
+			"Quote":         inquiry.Message,
+		},
+	})
+
+	response := mapper.InquiryToDTO(inquiry)
+	return &response, nil
+}
+
+func (c *AuthController) CloseInquiry(ownerId uint, inquiryId uint) (*dto.InquiryDTO, error) {
+	inquiry, err := c.ownerInquiry(ownerId, inquiryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if inquiry.Status != models.InquiryClosed {
+		err := c.DB.Model(&inquiry).Updates(map[string]interface{}{
+			"status":    models.InquiryClosed,
+			"closed_at": time.Now(),
+		}).Error
+		if err != nil {
+			return nil, errors.New("Failed to close inquiry")
+		}
+	}
+
+	response := mapper.InquiryToDTO(inquiry)
+	return &response, nil
+}
+
+func (c *AuthController) ownerInquiry(ownerId uint, inquiryId uint) (models.Inquiry, error) {
+	var inquiry models.Inquiry
+
+	err := c.DB.Preload("Property").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("owner_id = ? AND id = ?", ownerId, inquiryId).
+		First(&inquiry).Error
+	if err != nil {
+		return inquiry, errors.New("Inquiry not found")
+	}
+
+	return inquiry, nil
+}
+
+// hasAnsweredInquiry reports whether the owner replied to an inquiry the user sent
+// about the property. Inquiry-only attachments unlock with it.
+func (c *AuthController) hasAnsweredInquiry(propertyID uint, userID uint) bool {
+	var count int64
+	c.DB.Model(&models.Inquiry{}).
+		Where("property_id = ? AND sender_id = ? AND replied_at IS NOT NULL", propertyID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// loadPropertyInquiries sets the number of inquiries on each property
+func (c *AuthController) loadPropertyInquiries(properties []models.Property) {
+	if len(properties) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(properties))
+	for _, property := range properties {
+		ids = append(ids, property.ID)
+	}
+
+	var totals []struct {
+		PropertyID uint
+		Total      int64
+	}
+	err := c.DB.Model(&models.Inquiry{}).
+		Select("property_id, COUNT(*) AS total").
+		Where("property_id IN ?", ids).
+		Group("property_id").
+		Scan(&totals).Error
+	if err != nil {
+		log.Printf("Failed to load property inquiries: %v", err)
+		return
+	}
+
+	counts := map[uint]int64{}
+	for _, total := range totals {
+		counts[total.PropertyID] = total.Total
+	}
+
+	for i := range properties {
+		properties[i].Inquiries = counts[properties[i].ID]
+	}
+}
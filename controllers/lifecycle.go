@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+type propertyStatusEmail struct {
+	Subject string
+	Message string
+}
+
+// Owners are notified about transitions they did not make themselves
+var propertyStatusEmails = map[models.PropertyStatus]propertyStatusEmail{
+	models.StatusPendingReview: {"Listing sent back to review", "Your listing was changed and will be visible again once our team has reviewed the update."},
+	models.StatusActive:        {"Listing approved", "Good news! Your listing has been approved and is now live."},
+	models.StatusRejected:      {"Listing needs changes", "Your listing was not approved. Please update it and submit it again."},
+	models.StatusExpired:       {"Listing expired", "Your listing has expired and is no longer shown in search. Submit it again to relist it."},
+}
+
+// TransitionProperty moves a property to another lifecycle status. Owners can only
+// change their own listings, admins can change any listing.
+func (c *AuthController) TransitionProperty(propertyId uint32, user models.User, asAdmin bool, request dto.PropertyTransitionRequestDTO, requestID string) (*dto.PropertyResponseDTO, error) {
+	var property models.Property
+
+	query := c.DB.Preload("Owner")
+	if !asAdmin {
+		query = query.Where("owner_id = ?", user.ID)
+	}
+
+	if err := query.First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	actor := models.ActorOwner
+	if asAdmin {
+		actor = models.ActorAdmin
+	}
+
+	if err := c.transitionProperty(&property, models.PropertyStatus(request.Status), actor, &user.ID, request.Reason, requestID); err != nil {
+		return nil, err
+	}
+
+	response := mapper.PropertyModelToDetailsResponseDTOMapper(property)
+	return &response, nil
+}
+
+func (c *AuthController) PropertyStatusHistory(propertyId uint32, userId uint, asAdmin bool) ([]dto.PropertyStatusHistoryDTO, error) {
+	var property models.Property
+
+	query := c.DB.Model(&models.Property{})
+	if !asAdmin {
+		query = query.Where("owner_id = ?", userId)
+	}
+
+	if err := query.First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	var history []models.PropertyStatusHistory
+	err := c.DB.Preload("Actor").
+		Where("property_id = ?", property.ID).
+		Order("created_at ASC, id ASC").
+		Find(&history).Error
+	if err != nil {
+		return nil, errors.New("Failed to fetch property history")
+	}
+
+	responseDTOs := []dto.PropertyStatusHistoryDTO{}
+	for _, entry := range history {
+		responseDTOs = append(responseDTOs, mapper.PropertyStatusHistoryToDTO(entry))
+	}
+
+	return responseDTOs, nil
+}
+
+// transitionProperty validates and commits a status change, records it in the
+// history table and lets the owner know
+func (c *AuthController) transitionProperty(property *models.Property, to models.PropertyStatus, actor models.TransitionActor, actorID *uint, reason *string, requestID string) error {
+	from := property.Status
+	previousApproval := property.ApprovedAt
+
+	tx := c.DB.Begin()
+	if err := applyPropertyTransition(tx, property, to, actor, actorID, reason, requestID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("Failed to update property status")
+	}
+
+	c.notifyFavoriteStatusChange(*property, from, to)
+
+	if from == models.StatusPendingReview && to == models.StatusActive {
+		c.announceReviewedPriceChange(*property, previousApproval)
+	}
+
+	notificationType := models.NotificationListingUpdated
+	switch to {
+	case models.StatusActive:
+		notificationType = models.NotificationListingApproved
+	case models.StatusRejected:
+		notificationType = models.NotificationListingRejected
+	}
+
+	if message, ok := propertyStatusEmails[to]; ok && actor != models.ActorOwner {
+		c.notifyPropertyOwner(*property, notificationType, message.Subject, message.Message, reason)
+	} else if to == models.StatusPendingReview {
+		c.notifyPropertyOwner(*property, notificationType, "Listing submitted for review",
+			"Your listing has been submitted and is waiting for review by our team.", nil)
+	}
+
+	return nil
+}
+
+// applyPropertyTransition is the only place that writes properties.status
+func applyPropertyTransition(tx *gorm.DB, property *models.Property, to models.PropertyStatus, actor models.TransitionActor, actorID *uint, reason *string, requestID string) error {
+	from := property.Status
+	before := models.NewPropertySnapshot(*property)
+	if err := models.ValidatePropertyTransition(from, to, property.Purpose, actor); err != nil {
+		return err
+	}
+
+	if reason != nil && strings.TrimSpace(*reason) == "" {
+		reason = nil
+	}
+
+	if to == models.StatusRejected && reason == nil {
+		return errors.New("a reason is required to reject a property")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": to}
+
+	switch to {
+	case models.StatusPendingReview:
+		updates["submitted_at"] = now
+		updates["approved_at"] = nil
+		updates["approved_by_id"] = nil
+		updates["rejected_at"] = nil
+		updates["rejection_reason"] = nil
+	case models.StatusActive:
+		if from == models.StatusPendingReview {
+			updates["approved_at"] = now
+			updates["approved_by_id"] = actorID
+		}
+	case models.StatusRejected:
+		updates["rejected_at"] = now
+		updates["rejection_reason"] = *reason
+	}
+
+	if err := tx.Model(property).Updates(updates).Error; err != nil {
+		return errors.New("Failed to update property status")
+	}
+
+	history := models.PropertyStatusHistory{
+		PropertyID: property.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorType:  actor,
+		ActorID:    actorID,
+		Reason:     reason,
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		return errors.New("Failed to record property history")
+	}
+
+	after := before
+	after.Status = to
+
+	audit := models.AuditLog{
+		PropertyID: property.ID,
+		EntityType: models.AuditEntityProperty,
+		EntityID:   property.ID,
+		Action:     models.AuditActionUpdate,
+		ActorID:    actorID,
+		RequestID:  requestID,
+	}
+	if err := recordAudit(tx, audit, before, after); err != nil {
+		return err
+	}
+
+	return nil
+}
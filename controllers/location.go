@@ -388,10 +388,23 @@ func (c *AuthController) UpdateDistrict(id uint32, request dto.DistrictUpdateReq
 		}
 	}
 
+	// The area used to check listing coordinates is kept unless it is sent, so
+	// a rename does not switch the check off
+	latitude, longitude, radiusKm := district.Latitude, district.Longitude, district.RadiusKm
+	if request.Latitude != nil {
+		latitude = request.Latitude
+	}
+	if request.Longitude != nil {
+		longitude = request.Longitude
+	}
+	if request.RadiusKm != nil {
+		radiusKm = request.RadiusKm
+	}
+
 	tx := c.DB.Begin()
 
-	err := tx.Exec("UPDATE districts SET division_id = ?, name = ?, status = ?, updated_at = NOW() WHERE id = ?",
-		request.DivisionId, request.Name, request.Status, id).Error
+	err := tx.Exec("UPDATE districts SET division_id = ?, name = ?, status = ?, latitude = ?, longitude = ?, radius_km = ?, updated_at = NOW() WHERE id = ?",
+		request.DivisionId, request.Name, request.Status, latitude, longitude, radiusKm, id).Error
 
 	if err != nil {
 		tx.Rollback()
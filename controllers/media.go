@@ -0,0 +1,426 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/storage"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+const mediaUploadURLExpiry = 15 * time.Minute
+
+var mediaExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// maxPropertyImages is the per-listing photo limit, MAX_PROPERTY_IMAGES (default 20)
+func maxPropertyImages() int64 {
+	return envInt64("MAX_PROPERTY_IMAGES", 20)
+}
+
+// maxPropertyImageSize is the per-photo size limit, MAX_PROPERTY_IMAGE_SIZE_MB (default 10)
+func maxPropertyImageSize() int64 {
+	return envInt64("MAX_PROPERTY_IMAGE_SIZE_MB", 10) * 1024 * 1024
+}
+
+func envInt64(name string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// galleryPreload limits a Media preload to confirmed photos in display order
+func galleryPreload(db *gorm.DB) *gorm.DB {
+	return db.Where("status = ?", models.MediaUploaded).Order("sort_order ASC, id ASC")
+}
+
+func (c *AuthController) ownerProperty(propertyId uint32, userId uint) (models.Property, error) {
+	var property models.Property
+
+	if err := c.DB.Where("owner_id = ? AND id = ?", userId, propertyId).First(&property).Error; err != nil {
+		return property, errors.New("Property not found")
+	}
+
+	return property, nil
+}
+
+// mediaGallery is the photo gallery of a listing or of a project
+type mediaGallery struct {
+	column    string // property_id or project_id
+	id        uint
+	keyPrefix string
+	name      string // for messages
+}
+
+func propertyGallery(property models.Property) mediaGallery {
+	return mediaGallery{column: "property_id", id: property.ID, keyPrefix: fmt.Sprintf("properties/%d", property.ID), name: "property"}
+}
+
+func projectGallery(project models.Project) mediaGallery {
+	return mediaGallery{column: "project_id", id: project.ID, keyPrefix: fmt.Sprintf("projects/%d", project.ID), name: "project"}
+}
+
+// where limits a media query to the gallery
+func (g mediaGallery) where(db *gorm.DB) *gorm.DB {
+	return db.Where(g.column+" = ?", g.id)
+}
+
+func (g mediaGallery) limitError() error {
+	return fmt.Errorf("A %s can have at most %d images", g.name, maxPropertyImages())
+}
+
+func (c *AuthController) PropertyMediaList(propertyId uint32, userId uint) ([]dto.PropertyMediaDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.galleryMediaList(propertyGallery(property))
+}
+
+// CreatePropertyMediaUpload reserves a gallery slot and returns a presigned URL the
+// client uploads the photo to. The photo shows up once the upload is confirmed.
+func (c *AuthController) CreatePropertyMediaUpload(propertyId uint32, userId uint, request dto.PropertyMediaUploadRequestDTO) (*dto.PropertyMediaUploadResponseDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.createGalleryMediaUpload(propertyGallery(property), request)
+}
+
+// ConfirmPropertyMediaUpload adds an uploaded photo to the end of the gallery and
+// queues it for processing. Width and height are replaced by the real dimensions
+// once the renditions are generated.
+func (c *AuthController) ConfirmPropertyMediaUpload(propertyId uint32, mediaId uint, userId uint, request dto.PropertyMediaConfirmRequestDTO) (*dto.PropertyMediaDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.confirmGalleryMediaUpload(propertyGallery(property), mediaId, request)
+}
+
+// ReorderPropertyMedia sets the gallery order. The request must list every photo.
+func (c *AuthController) ReorderPropertyMedia(propertyId uint32, userId uint, request dto.PropertyMediaOrderRequestDTO) ([]dto.PropertyMediaDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.reorderGalleryMedia(propertyGallery(property), request)
+}
+
+func (c *AuthController) SetPropertyCover(propertyId uint32, mediaId uint, userId uint) ([]dto.PropertyMediaDTO, error) {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.setGalleryCover(propertyGallery(property), mediaId)
+}
+
+// DeletePropertyMedia removes the stored files and the gallery entry. If the cover
+// is deleted the next photo in order becomes the cover.
+func (c *AuthController) DeletePropertyMedia(propertyId uint32, mediaId uint, userId uint) error {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return err
+	}
+
+	return c.deleteGalleryMedia(propertyGallery(property), mediaId)
+}
+
+// Project photos go through the same pipeline as listing photos
+
+func (c *AuthController) ProjectMediaList(projectId uint32, userId uint) ([]dto.PropertyMediaDTO, error) {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.galleryMediaList(projectGallery(project))
+}
+
+func (c *AuthController) CreateProjectMediaUpload(projectId uint32, userId uint, request dto.PropertyMediaUploadRequestDTO) (*dto.PropertyMediaUploadResponseDTO, error) {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.createGalleryMediaUpload(projectGallery(project), request)
+}
+
+func (c *AuthController) ConfirmProjectMediaUpload(projectId uint32, mediaId uint, userId uint, request dto.PropertyMediaConfirmRequestDTO) (*dto.PropertyMediaDTO, error) {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.confirmGalleryMediaUpload(projectGallery(project), mediaId, request)
+}
+
+func (c *AuthController) ReorderProjectMedia(projectId uint32, userId uint, request dto.PropertyMediaOrderRequestDTO) ([]dto.PropertyMediaDTO, error) {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.reorderGalleryMedia(projectGallery(project), request)
+}
+
+func (c *AuthController) SetProjectCover(projectId uint32, mediaId uint, userId uint) ([]dto.PropertyMediaDTO, error) {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.setGalleryCover(projectGallery(project), mediaId)
+}
+
+func (c *AuthController) DeleteProjectMedia(projectId uint32, mediaId uint, userId uint) error {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return err
+	}
+
+	return c.deleteGalleryMedia(projectGallery(project), mediaId)
+}
+
+func (c *AuthController) galleryMediaList(gallery mediaGallery) ([]dto.PropertyMediaDTO, error) {
+	var media []models.PropertyMedia
+	if err := gallery.where(galleryPreload(c.DB).Preload("Renditions")).Find(&media).Error; err != nil {
+		return nil, errors.New("Failed to fetch " + gallery.name + " media")
+	}
+
+	return mapper.PropertyGalleryToDTO(media), nil
+}
+
+func (c *AuthController) createGalleryMediaUpload(gallery mediaGallery, request dto.PropertyMediaUploadRequestDTO) (*dto.PropertyMediaUploadResponseDTO, error) {
+	extension, ok := mediaExtensions[request.ContentType]
+	if !ok {
+		return nil, errors.New("Unsupported image type")
+	}
+
+	if request.SizeBytes > maxPropertyImageSize() {
+		return nil, fmt.Errorf("Image must not be larger than %d MB", maxPropertyImageSize()/1024/1024)
+	}
+
+	// Unconfirmed uploads hold a slot until their upload URL expires
+	var count int64
+	gallery.where(c.DB.Model(&models.PropertyMedia{})).
+		Where("status = ? OR created_at > ?", models.MediaUploaded, time.Now().Add(-mediaUploadURLExpiry)).
+		Count(&count)
+	if count >= maxPropertyImages() {
+		return nil, gallery.limitError()
+	}
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, errors.New("Failed to prepare upload")
+	}
+
+	media := models.PropertyMedia{
+		S3Key:       fmt.Sprintf("%s/media/%s.%s", gallery.keyPrefix, hex.EncodeToString(suffix), extension),
+		ContentType: request.ContentType,
+		SizeBytes:   request.SizeBytes,
+		Caption:     request.Caption,
+		Status:      models.MediaPending,
+	}
+	if gallery.column == "project_id" {
+		media.ProjectID = &gallery.id
+	} else {
+		media.PropertyID = &gallery.id
+	}
+
+	uploadURL, err := c.Storage.PresignUpload(context.Background(), media.S3Key, media.ContentType, media.SizeBytes, mediaUploadURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.DB.Create(&media).Error; err != nil {
+		return nil, errors.New("Failed to prepare upload")
+	}
+
+	response := dto.PropertyMediaUploadResponseDTO{
+		Media:     mapper.PropertyMediaToDTO(media),
+		UploadURL: uploadURL,
+		ExpiresAt: time.Now().Add(mediaUploadURLExpiry).Format("2006-01-02 15:04:05"),
+	}
+
+	return &response, nil
+}
+
+func (c *AuthController) confirmGalleryMediaUpload(gallery mediaGallery, mediaId uint, request dto.PropertyMediaConfirmRequestDTO) (*dto.PropertyMediaDTO, error) {
+	var media models.PropertyMedia
+	if err := gallery.where(c.DB).Where("id = ?", mediaId).First(&media).Error; err != nil {
+		return nil, errors.New("Media not found")
+	}
+
+	if media.Status == models.MediaUploaded {
+		response := mapper.PropertyMediaToDTO(media)
+		return &response, nil
+	}
+
+	info, err := c.Storage.Head(context.Background(), media.S3Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, errors.New("Image has not been uploaded yet")
+		}
+		return nil, err
+	}
+
+	if info.Size != media.SizeBytes {
+		return nil, errors.New("Uploaded image size does not match")
+	}
+
+	var uploadedCount int64
+	gallery.where(c.DB.Model(&models.PropertyMedia{})).
+		Where("status = ?", models.MediaUploaded).
+		Count(&uploadedCount)
+	if uploadedCount >= maxPropertyImages() {
+		return nil, gallery.limitError()
+	}
+
+	var maxSortOrder int
+	gallery.where(c.DB.Model(&models.PropertyMedia{})).
+		Where("status = ?", models.MediaUploaded).
+		Select("COALESCE(MAX(sort_order), -1)").
+		Scan(&maxSortOrder)
+
+	err = c.DB.Model(&media).Updates(map[string]interface{}{
+		"status":            models.MediaUploaded,
+		"width":             request.Width,
+		"height":            request.Height,
+		"sort_order":        maxSortOrder + 1,
+		"is_cover":          uploadedCount == 0,
+		"processing_status": models.ProcessingPending,
+	}).Error
+	if err != nil {
+		return nil, errors.New("Failed to confirm upload")
+	}
+
+	c.NotifyMediaProcessing()
+
+	response := mapper.PropertyMediaToDTO(media)
+	return &response, nil
+}
+
+func (c *AuthController) reorderGalleryMedia(gallery mediaGallery, request dto.PropertyMediaOrderRequestDTO) ([]dto.PropertyMediaDTO, error) {
+	var media []models.PropertyMedia
+	if err := gallery.where(galleryPreload(c.DB)).Find(&media).Error; err != nil {
+		return nil, errors.New("Failed to fetch " + gallery.name + " media")
+	}
+
+	orderError := errors.New("media_ids must contain every image of the " + gallery.name + " exactly once")
+	if len(request.MediaIDs) != len(media) {
+		return nil, orderError
+	}
+
+	existing := map[uint]bool{}
+	for _, item := range media {
+		existing[item.ID] = true
+	}
+
+	tx := c.DB.Begin()
+	for index, mediaId := range request.MediaIDs {
+		if !existing[mediaId] {
+			tx.Rollback()
+			return nil, orderError
+		}
+		delete(existing, mediaId)
+
+		if err := tx.Model(&models.PropertyMedia{}).Where("id = ?", mediaId).Update("sort_order", index).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.New("Failed to reorder media")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("Failed to reorder media")
+	}
+
+	return c.galleryMediaList(gallery)
+}
+
+func (c *AuthController) setGalleryCover(gallery mediaGallery, mediaId uint) ([]dto.PropertyMediaDTO, error) {
+	var media models.PropertyMedia
+	if err := gallery.where(c.DB).Where("id = ? AND status = ?", mediaId, models.MediaUploaded).First(&media).Error; err != nil {
+		return nil, errors.New("Media not found")
+	}
+
+	tx := c.DB.Begin()
+	if err := gallery.where(tx.Model(&models.PropertyMedia{})).Update("is_cover", false).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("Failed to set cover image")
+	}
+
+	if err := tx.Model(&media).Update("is_cover", true).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("Failed to set cover image")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.New("Failed to set cover image")
+	}
+
+	return c.galleryMediaList(gallery)
+}
+
+func (c *AuthController) deleteGalleryMedia(gallery mediaGallery, mediaId uint) error {
+	var media models.PropertyMedia
+	if err := gallery.where(c.DB.Preload("Renditions")).Where("id = ?", mediaId).First(&media).Error; err != nil {
+		return errors.New("Media not found")
+	}
+
+	for _, rendition := range media.Renditions {
+		if err := c.Storage.Delete(context.Background(), rendition.S3Key); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Storage.Delete(context.Background(), media.S3Key); err != nil {
+		return err
+	}
+
+	tx := c.DB.Begin()
+	if err := tx.Where("media_id = ?", media.ID).Delete(&models.PropertyMediaRendition{}).Error; err != nil {
+		tx.Rollback()
+		return errors.New("Failed to delete media")
+	}
+
+	if err := tx.Unscoped().Delete(&media).Error; err != nil {
+		tx.Rollback()
+		return errors.New("Failed to delete media")
+	}
+
+	if media.IsCover {
+		var next models.PropertyMedia
+		if err := gallery.where(galleryPreload(tx)).First(&next).Error; err == nil {
+			if err := tx.Model(&next).Update("is_cover", true).Error; err != nil {
+				tx.Rollback()
+				return errors.New("Failed to delete media")
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("Failed to delete media")
+	}
+
+	return nil
+}
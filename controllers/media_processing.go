@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/imaging"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mediaPollInterval is how often idle workers look for work they were not woken for,
+// e.g. photos confirmed on another instance
+const mediaPollInterval = time.Minute
+
+// mediaProcessingTimeout is how long a photo may stay in processing before another
+// worker assumes the first one died and picks it up again
+const mediaProcessingTimeout = 10 * time.Minute
+
+// StartMediaProcessing starts the background workers that turn confirmed uploads
+// into renditions, MEDIA_WORKERS of them (default 2). They stop when ctx is done.
+func (c *AuthController) StartMediaProcessing(ctx context.Context) {
+	workers := envInt64("MEDIA_WORKERS", 2)
+	for i := int64(0); i < workers; i++ {
+		go c.mediaWorker(ctx)
+	}
+}
+
+// NotifyMediaProcessing wakes a worker after a photo was queued
+func (c *AuthController) NotifyMediaProcessing() {
+	select {
+	case c.mediaQueue <- struct{}{}:
+	default:
+	}
+}
+
+func (c *AuthController) mediaWorker(ctx context.Context) {
+	ticker := time.NewTicker(mediaPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil && c.processNextMedia(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.mediaQueue:
+		case <-ticker.C:
+		}
+	}
+}
+
+// processNextMedia claims one queued photo and processes it. It reports whether
+// there was anything to do.
+func (c *AuthController) processNextMedia(ctx context.Context) bool {
+	media, err := c.claimMedia()
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Failed to claim media for processing: %v", err)
+		}
+		return false
+	}
+
+	if err := c.processMedia(ctx, media); err != nil {
+		log.Printf("Failed to process media %d: %v", media.ID, err)
+
+		message := err.Error()
+		c.DB.Model(&media).Updates(map[string]interface{}{
+			"processing_status": models.ProcessingFailed,
+			"processing_error":  message,
+		})
+	}
+
+	return true
+}
+
+// claimMedia marks the oldest queued photo as processing. SKIP LOCKED keeps two
+// workers from picking the same row.
+func (c *AuthController) claimMedia() (models.PropertyMedia, error) {
+	var media models.PropertyMedia
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.MediaUploaded).
+			Where("processing_status = ? OR (processing_status = ? AND processing_started_at < ?)",
+				models.ProcessingPending, models.ProcessingInProgress, time.Now().Add(-mediaProcessingTimeout)).
+			Order("id ASC").
+			First(&media).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&media).Updates(map[string]interface{}{
+			"processing_status":     models.ProcessingInProgress,
+			"processing_started_at": time.Now(),
+		}).Error
+	})
+
+	return media, err
+}
+
+// processMedia strips GPS data from the original, stores the renditions next to it
+// and records the real dimensions and placeholders
+func (c *AuthController) processMedia(ctx context.Context, media models.PropertyMedia) (err error) {
+	// A malformed file must not take the worker down with it
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("image processing panicked: %v", r)
+		}
+	}()
+
+	body, err := c.Storage.Get(ctx, media.S3Key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxPropertyImageSize()+1))
+	body.Close()
+	if err != nil {
+		return errors.New("failed to read image")
+	}
+	if int64(len(data)) > maxPropertyImageSize() {
+		return errors.New("image is too large")
+	}
+
+	img, format, err := imaging.Decode(data, media.ContentType)
+	if err != nil {
+		return err
+	}
+
+	stripped, err := imaging.StripGPS(data, format)
+	if err != nil {
+		return err
+	}
+	if stripped {
+		if err := c.Storage.Put(ctx, media.S3Key, media.ContentType, bytes.NewReader(data), int64(len(data))); err != nil {
+			return err
+		}
+	}
+
+	watermark, err := c.mediaWatermark(ctx, media)
+	if err != nil {
+		return err
+	}
+
+	// Renditions get a new key on every run so caches never serve a stale
+	// (e.g. not yet watermarked) copy
+	version := time.Now().Unix()
+
+	var renditions []models.PropertyMediaRendition
+	for _, spec := range imaging.Renditions {
+		resized := imaging.Fit(img, spec.MaxWidth, spec.MaxHeight)
+
+		watermarked := spec.Watermark && watermark != nil
+		if watermarked {
+			if resized, err = imaging.ApplyWatermark(resized, *watermark); err != nil {
+				return fmt.Errorf("failed to watermark %s rendition", spec.Name)
+			}
+		}
+
+		encoded, err := imaging.EncodeJPEG(resized)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s rendition", spec.Name)
+		}
+
+		key := renditionKey(media.S3Key, spec.Name, version)
+		if err := c.Storage.Put(ctx, key, "image/jpeg", bytes.NewReader(encoded), int64(len(encoded))); err != nil {
+			return err
+		}
+
+		renditions = append(renditions, models.PropertyMediaRendition{
+			MediaID:     media.ID,
+			Name:        spec.Name,
+			S3Key:       key,
+			ContentType: "image/jpeg",
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			SizeBytes:   int64(len(encoded)),
+			Watermarked: watermarked,
+		})
+	}
+
+	var previous []models.PropertyMediaRendition
+	c.DB.Where("media_id = ?", media.ID).Find(&previous)
+
+	bounds := img.Bounds()
+	now := time.Now()
+
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", media.ID).Delete(&models.PropertyMediaRendition{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&renditions).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&media).Updates(map[string]interface{}{
+			"width":             bounds.Dx(),
+			"height":            bounds.Dy(),
+			"size_bytes":        len(data),
+			"blur_hash":         imaging.BlurHash(img),
+			"dominant_color":    imaging.DominantColor(img),
+			"processing_status": models.ProcessingReady,
+			"processing_error":  nil,
+			"processed_at":      now,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+	for _, rendition := range renditions {
+		current[rendition.S3Key] = true
+	}
+
+	for _, rendition := range previous {
+		if current[rendition.S3Key] {
+			continue
+		}
+		if err := c.Storage.Delete(ctx, rendition.S3Key); err != nil {
+			log.Printf("Failed to delete old rendition %s: %v", rendition.S3Key, err)
+		}
+	}
+
+	return nil
+}
+
+// renditionKey places a rendition in a folder named after the original,
+// e.g. properties/1/media/abc.png -> properties/1/media/abc/thumb-1700000000.jpg
+func renditionKey(originalKey, name string, version int64) string {
+	return fmt.Sprintf("%s/%s-%d.jpg", strings.TrimSuffix(originalKey, path.Ext(originalKey)), name, version)
+}
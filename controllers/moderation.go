@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+// SubmitPropertyForReview moves an owner's draft (or rejected) listing into the review queue
+func (c *AuthController) SubmitPropertyForReview(propertyId uint32, user models.User, requestID string) (*dto.PropertyResponseDTO, error) {
+	request := dto.PropertyTransitionRequestDTO{Status: string(models.StatusPendingReview)}
+	return c.TransitionProperty(propertyId, user, false, request, requestID)
+}
+
+// ArchiveProperty takes an owner's listing off the market without deleting it;
+// archived listings can be moved back to draft
+func (c *AuthController) ArchiveProperty(propertyId uint32, user models.User, requestID string) (*dto.PropertyResponseDTO, error) {
+	request := dto.PropertyTransitionRequestDTO{Status: string(models.StatusArchived)}
+	return c.TransitionProperty(propertyId, user, false, request, requestID)
+}
+
+func (c *AuthController) PendingProperties(page, pageSize int) (*dto.PaginatedResponse, error) {
+	var properties []models.Property
+	var total int64
+
+	query := c.DB.Model(&models.Property{}).Where("status = ?", models.StatusPendingReview)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting properties")
+	}
+
+	offset := (page - 1) * pageSize
+
+	// Oldest submissions are reviewed first
+	err := query.Preload("Country").
+		Preload("Division").
+		Preload("District").
+		Preload("Owner").
+		Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		Order("submitted_at ASC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&properties).Error
+	if err != nil {
+		return nil, errors.New("error retrieving properties")
+	}
+
+	var responseDTOs []dto.PropertyModerationDTO
+	for _, property := range properties {
+		responseDTOs = append(responseDTOs, mapper.PropertyModelToModerationDTOMapper(property))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+func (c *AuthController) ApproveProperty(propertyId uint32, admin models.User, requestID string) (*dto.PropertyResponseDTO, error) {
+	request := dto.PropertyTransitionRequestDTO{Status: string(models.StatusActive)}
+	return c.TransitionProperty(propertyId, admin, true, request, requestID)
+}
+
+func (c *AuthController) RejectProperty(propertyId uint32, admin models.User, request dto.PropertyRejectRequestDTO, requestID string) (*dto.PropertyResponseDTO, error) {
+	transition := dto.PropertyTransitionRequestDTO{Status: string(models.StatusRejected), Reason: &request.Reason}
+	return c.TransitionProperty(propertyId, admin, true, transition, requestID)
+}
+
+// propertyKeyFieldsChanged reports whether an update touches fields that require
+// an active listing to be reviewed again
+func propertyKeyFieldsChanged(property models.Property, request dto.PropertyRequestDTO) bool {
+	return property.Title != request.Title ||
+		string(property.Purpose) != request.Purpose ||
+		property.Price != request.Price ||
+		property.Currency != request.Currency ||
+		property.PropertyType != request.PropertyType ||
+		property.Address != request.Address ||
+		property.Description != request.Description ||
+		property.CountryID != request.CountryID ||
+		property.DivisionID != request.DivisionID ||
+		property.DistrictID != request.DistrictID
+}
+
+// notifyPropertyOwner tells the owner about a listing status change
+func (c *AuthController) notifyPropertyOwner(property models.Property, notificationType models.NotificationType, headline, message string, reason *string) {
+	owner := property.Owner
+	if owner.ID == 0 {
+		if err := c.DB.First(&owner, property.OwnerID).Error; err != nil {
+			log.Printf("Could not load owner %d for property %d: %v", property.OwnerID, property.ID, err)
+			return
+		}
+	}
+
+	data := map[string]interface{}{
+		"property_id": property.ID,
+		"status":      property.Status,
+	}
+	emailData := map[string]interface{}{
+		"Headline":      headline,
+		"Message":       message,
+		"PropertyTitle": property.Title,
+		"Reason":        "",
+	}
+	if reason != nil {
+		data["reason"] = *reason
+		emailData["Reason"] = *reason
+	}
+
+	c.notify(userRecipient(owner), notification{
+		Type:          notificationType,
+		Title:         headline,
+		Body:          message,
+		Data:          data,
+		EmailTemplate: "listing_update",
+		EmailData:     emailData,
+	})
+}
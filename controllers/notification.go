@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm/clause"
+)
+
+// Notifications lists the user's notification center, newest first
+func (c *AuthController) Notifications(userId uint, filter dto.NotificationFilterDTO, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := c.DB.Model(&models.Notification{}).Where("user_id = ?", userId)
+
+	if filter.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting notifications")
+	}
+
+	offset := (page - 1) * pageSize
+
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&notifications).Error; err != nil {
+		return nil, errors.New("error retrieving notifications")
+	}
+
+	responseDTOs := []dto.NotificationDTO{}
+	for _, notification := range notifications {
+		responseDTOs = append(responseDTOs, mapper.NotificationToDTO(notification))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+func (c *AuthController) MarkNotificationRead(userId uint, notificationId uint) (*dto.NotificationDTO, error) {
+	var notification models.Notification
+	if err := c.DB.Where("user_id = ? AND id = ?", userId, notificationId).First(&notification).Error; err != nil {
+		return nil, errors.New("Notification not found")
+	}
+
+	if notification.ReadAt == nil {
+		if err := c.DB.Model(&notification).Update("read_at", time.Now()).Error; err != nil {
+			return nil, errors.New("Failed to update notification")
+		}
+	}
+
+	response := mapper.NotificationToDTO(notification)
+	return &response, nil
+}
+
+func (c *AuthController) MarkAllNotificationsRead(userId uint) (*dto.UnreadCountDTO, error) {
+	err := c.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Update("read_at", time.Now()).Error
+	if err != nil {
+		return nil, errors.New("Failed to update notifications")
+	}
+
+	return &dto.UnreadCountDTO{Unread: 0}, nil
+}
+
+func (c *AuthController) UnreadNotificationCount(userId uint) int64 {
+	var unread int64
+	c.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Count(&unread)
+	return unread
+}
+
+// NotificationPreferences returns the user's channels for every notification type
+func (c *AuthController) NotificationPreferences(userId uint) ([]dto.NotificationPreferenceDTO, error) {
+	var preferences []models.NotificationPreference
+	if err := c.DB.Where("user_id = ?", userId).Find(&preferences).Error; err != nil {
+		return nil, errors.New("Failed to fetch notification preferences")
+	}
+
+	saved := map[models.NotificationType]models.NotificationChannels{}
+	for _, preference := range preferences {
+		saved[preference.Type] = models.NotificationChannels{
+			InApp: preference.InApp,
+			Email: preference.Email,
+			SMS:   preference.SMS,
+		}
+	}
+
+	responseDTOs := []dto.NotificationPreferenceDTO{}
+	for _, notificationType := range models.NotificationTypes {
+		channels, ok := saved[notificationType]
+		if !ok {
+			channels = models.DefaultNotificationChannels[notificationType]
+		}
+		responseDTOs = append(responseDTOs, mapper.NotificationPreferenceToDTO(notificationType, channels))
+	}
+
+	return responseDTOs, nil
+}
+
+// UpdateNotificationPreferences saves the channels of the given types; types
+// that are left out keep their current setting
+func (c *AuthController) UpdateNotificationPreferences(userId uint, request dto.NotificationPreferencesRequestDTO) ([]dto.NotificationPreferenceDTO, error) {
+	// A type sent twice keeps its last setting
+	requested := map[models.NotificationType]models.NotificationPreference{}
+	for _, item := range request.Preferences {
+		notificationType := models.NotificationType(item.Type)
+		if !notificationType.IsValid() {
+			return nil, fmt.Errorf("Unknown notification type: %s", item.Type)
+		}
+
+		requested[notificationType] = models.NotificationPreference{
+			UserID: userId,
+			Type:   notificationType,
+			InApp:  item.InApp,
+			Email:  item.Email,
+			SMS:    item.SMS,
+		}
+	}
+
+	var preferences []models.NotificationPreference
+	for _, notificationType := range models.NotificationTypes {
+		if preference, ok := requested[notificationType]; ok {
+			preferences = append(preferences, preference)
+		}
+	}
+
+	if len(preferences) > 0 {
+		err := c.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+			DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "sms", "updated_at"}),
+		}).Create(&preferences).Error
+		if err != nil {
+			return nil, errors.New("Failed to update notification preferences")
+		}
+	}
+
+	return c.NotificationPreferences(userId)
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/farhapartex/real_estate_be/lib/email"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+// maxSMSLength keeps a notification within two SMS segments
+const maxSMSLength = 300
+
+// notification is a domain event addressed to one recipient
+type notification struct {
+	Type  models.NotificationType
+	Title string
+	Body  string
+	// Data is stored with the notification and pushed to the stream
+	Data map[string]interface{}
+	// EmailTemplate and EmailData render the email; without a template the
+	// generic notification template shows Title and Body
+	EmailTemplate string
+	EmailData     map[string]interface{}
+}
+
+// notificationRecipient is a user or, for guests, just an email address. Phone
+// is looked up from the owner profile when it is not given.
+type notificationRecipient struct {
+	UserID *uint
+	Name   string
+	Email  string
+	Phone  string
+}
+
+func userRecipient(user models.User) notificationRecipient {
+	return notificationRecipient{
+		UserID: &user.ID,
+		Name:   user.FirstName,
+		Email:  user.Email,
+	}
+}
+
+// notify is the single place domain events reach users. It delivers on the
+// channels the recipient enabled for the type; guests can only be emailed.
+// Delivery failures are logged and never fail the caller.
+func (c *AuthController) notify(recipient notificationRecipient, n notification) {
+	channels := models.DefaultNotificationChannels[n.Type]
+	if recipient.UserID != nil {
+		channels = c.notificationChannels(*recipient.UserID, n.Type)
+	} else {
+		channels = models.NotificationChannels{Email: channels.Email}
+	}
+
+	if channels.InApp && recipient.UserID != nil {
+		c.storeNotification(*recipient.UserID, n)
+	}
+
+	if channels.Email && recipient.Email != "" {
+		c.sendNotificationEmail(recipient, n)
+	}
+
+	phone := recipient.Phone
+	if channels.SMS && phone == "" && recipient.UserID != nil {
+		phone = c.userPhoneNumber(*recipient.UserID)
+	}
+
+	if channels.SMS && phone != "" && c.SMS != nil {
+		text := n.Title
+		if n.Body != "" {
+			text += ": " + n.Body
+		}
+		if runes := []rune(text); len(runes) > maxSMSLength {
+			text = string(runes[:maxSMSLength-3]) + "..."
+		}
+
+		go func() {
+			if err := c.SMS.Send(phone, text); err != nil {
+				log.Printf("Failed to send %s SMS to %s: %v", n.Type, phone, err)
+			}
+		}()
+	}
+}
+
+// storeNotification adds the notification to the user's notification center and
+// pushes it to their open streams
+func (c *AuthController) storeNotification(userID uint, n notification) {
+	payload, err := json.Marshal(n.Data)
+	if err != nil {
+		log.Printf("Failed to encode %s notification: %v", n.Type, err)
+		return
+	}
+
+	row := models.Notification{
+		UserID:  userID,
+		Type:    n.Type,
+		Title:   n.Title,
+		Body:    n.Body,
+		Payload: payload,
+	}
+	if err := c.DB.Create(&row).Error; err != nil {
+		log.Printf("Failed to store %s notification for user %d: %v", n.Type, userID, err)
+		return
+	}
+
+	c.Events.Publish(userID, string(n.Type), mapper.NotificationToDTO(row))
+}
+
+func (c *AuthController) sendNotificationEmail(recipient notificationRecipient, n notification) {
+	template := n.EmailTemplate
+	data := map[string]interface{}{}
+	for key, value := range n.EmailData {
+		data[key] = value
+	}
+
+	if template == "" {
+		template = "notification"
+		data["Headline"] = n.Title
+		data["Message"] = n.Body
+	}
+
+	data["RecipientName"] = strings.TrimSpace(recipient.Name)
+	data["CompanyName"] = email.CompanyName()
+	data["SupportEmail"] = email.SupportEmail()
+
+	go func() {
+		if err := email.SendTemplateEmail(recipient.Email, n.Title, template, data); err != nil {
+			log.Printf("Failed to send %s email to %s: %v", n.Type, recipient.Email, err)
+		}
+	}()
+}
+
+// notificationChannels returns the user's channels for a type, falling back to
+// the defaults
+func (c *AuthController) notificationChannels(userID uint, notificationType models.NotificationType) models.NotificationChannels {
+	var preference models.NotificationPreference
+	err := c.DB.Where("user_id = ? AND type = ?", userID, notificationType).Limit(1).Find(&preference).Error
+	if err != nil || preference.ID == 0 {
+		return models.DefaultNotificationChannels[notificationType]
+	}
+
+	return models.NotificationChannels{
+		InApp: preference.InApp,
+		Email: preference.Email,
+		SMS:   preference.SMS,
+	}
+}
+
+// userPhoneNumber is the phone number from the user's owner profile, if any
+func (c *AuthController) userPhoneNumber(userID uint) string {
+	var profile models.OwnerProfile
+	if err := c.DB.Where("user_id = ?", userID).Limit(1).Find(&profile).Error; err != nil {
+		return ""
+	}
+	return profile.PhoneNumber
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+// newPartnerToken returns a random token and the hash that is stored for it
+func newPartnerToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashPartnerToken(token), nil
+}
+
+func hashPartnerToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *AuthController) CreatePartner(request dto.PartnerRequestDTO) (*dto.PartnerTokenDTO, error) {
+	token, hash, err := newPartnerToken()
+	if err != nil {
+		return nil, errors.New("Failed to create partner")
+	}
+
+	partner := models.Partner{
+		Name:      strings.TrimSpace(request.Name),
+		TokenHash: hash,
+		Active:    true,
+	}
+	if request.Purpose != nil {
+		purpose := models.PropertyString(*request.Purpose)
+		partner.Purpose = &purpose
+	}
+	if request.CountryID != nil && *request.CountryID > 0 {
+		if err := c.DB.First(&models.Country{}, *request.CountryID).Error; err != nil {
+			return nil, errors.New("country not found")
+		}
+		partner.CountryID = request.CountryID
+	}
+
+	if err := c.DB.Create(&partner).Error; err != nil {
+		return nil, errors.New("Failed to create partner")
+	}
+
+	response := dto.PartnerTokenDTO{PartnerDTO: mapper.PartnerToDTO(partner), Token: token}
+	return &response, nil
+}
+
+func (c *AuthController) Partners(page, pageSize int) (*dto.PaginatedResponse, error) {
+	var partners []models.Partner
+	var total int64
+
+	query := c.DB.Model(&models.Partner{})
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting partners")
+	}
+
+	offset := (page - 1) * pageSize
+
+	if err := query.Order("name ASC").Offset(offset).Limit(pageSize).Find(&partners).Error; err != nil {
+		return nil, errors.New("error retrieving partners")
+	}
+
+	responseDTOs := []dto.PartnerDTO{}
+	for _, partner := range partners {
+		responseDTOs = append(responseDTOs, mapper.PartnerToDTO(partner))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+// UpdatePartner changes a partner. Changing which listings it receives throws
+// away its cached feeds so they are rebuilt on the next run.
+func (c *AuthController) UpdatePartner(partnerId uint32, request dto.PartnerUpdateDTO) (*dto.PartnerDTO, error) {
+	var partner models.Partner
+	if err := c.DB.First(&partner, partnerId).Error; err != nil {
+		return nil, errors.New("Partner not found")
+	}
+
+	updates := map[string]interface{}{}
+	if request.Name != nil {
+		updates["name"] = strings.TrimSpace(*request.Name)
+	}
+	if request.Active != nil {
+		updates["active"] = *request.Active
+	}
+	if request.Purpose != nil {
+		if *request.Purpose == "" {
+			updates["purpose"] = nil
+		} else {
+			updates["purpose"] = *request.Purpose
+		}
+		updates["feed_generated_at"] = nil
+	}
+	if request.CountryID != nil {
+		if *request.CountryID == 0 {
+			updates["country_id"] = nil
+		} else {
+			if err := c.DB.First(&models.Country{}, *request.CountryID).Error; err != nil {
+				return nil, errors.New("country not found")
+			}
+			updates["country_id"] = *request.CountryID
+		}
+		updates["feed_generated_at"] = nil
+	}
+
+	if len(updates) > 0 {
+		if err := c.DB.Model(&partner).Updates(updates).Error; err != nil {
+			return nil, errors.New("Failed to update partner")
+		}
+	}
+
+	if err := c.DB.First(&partner, partnerId).Error; err != nil {
+		return nil, errors.New("Partner not found")
+	}
+
+	response := mapper.PartnerToDTO(partner)
+	return &response, nil
+}
+
+// RotatePartnerToken issues a new token; the old one stops working right away
+func (c *AuthController) RotatePartnerToken(partnerId uint32) (*dto.PartnerTokenDTO, error) {
+	var partner models.Partner
+	if err := c.DB.First(&partner, partnerId).Error; err != nil {
+		return nil, errors.New("Partner not found")
+	}
+
+	token, hash, err := newPartnerToken()
+	if err != nil {
+		return nil, errors.New("Failed to rotate token")
+	}
+
+	if err := c.DB.Model(&partner).Update("token_hash", hash).Error; err != nil {
+		return nil, errors.New("Failed to rotate token")
+	}
+
+	response := dto.PartnerTokenDTO{PartnerDTO: mapper.PartnerToDTO(partner), Token: token}
+	return &response, nil
+}
+
+func (c *AuthController) DeletePartner(partnerId uint32) error {
+	var partner models.Partner
+	if err := c.DB.First(&partner, partnerId).Error; err != nil {
+		return errors.New("Partner not found")
+	}
+
+	if err := c.DB.Delete(&partner).Error; err != nil {
+		return errors.New("Failed to delete partner")
+	}
+
+	for _, format := range partnerFeedFormats {
+		key := partnerFeedKey(partner.ID, format)
+		if err := c.Storage.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to delete %s of a deleted partner: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+// PartnerByToken returns the active partner the token belongs to and records the fetch
+func (c *AuthController) PartnerByToken(token string) (*models.Partner, error) {
+	if token == "" {
+		return nil, errors.New("Invalid partner token")
+	}
+
+	var partner models.Partner
+	if err := c.DB.Where("token_hash = ? AND active = ?", hashPartnerToken(token), true).First(&partner).Error; err != nil {
+		return nil, errors.New("Invalid partner token")
+	}
+
+	c.DB.Model(&partner).UpdateColumn("last_fetched_at", time.Now())
+
+	return &partner, nil
+}
@@ -0,0 +1,451 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PartnerFeedXML  = "xml"
+	PartnerFeedJSON = "json"
+
+	// partnerFeedCheckInterval is how often stale partner feeds are looked for
+	partnerFeedCheckInterval = time.Minute
+	// partnerFeedBuildTimeout is how long a feed build may take before another
+	// instance assumes the first one died and builds it again
+	partnerFeedBuildTimeout = 30 * time.Minute
+	// partnerFeedBatchSize is how many listings are loaded at a time
+	partnerFeedBatchSize = 200
+)
+
+var partnerFeedFormats = []string{PartnerFeedXML, PartnerFeedJSON}
+
+// ErrPartnerFeedNotReady is returned for a full feed that has not been built yet
+var ErrPartnerFeedNotReady = errors.New("The feed is being generated, try again later")
+
+// partnerFeedRefresh is how old a partner's cached feeds may get,
+// PARTNER_FEED_REFRESH_MINUTES (default 60)
+func partnerFeedRefresh() time.Duration {
+	return time.Duration(envInt64("PARTNER_FEED_REFRESH_MINUTES", 60)) * time.Minute
+}
+
+func partnerFeedKey(partnerId uint, format string) string {
+	return fmt.Sprintf("feeds/%d/listings.%s", partnerId, format)
+}
+
+// PartnerFeed is a feed ready to be sent to a partner
+type PartnerFeed struct {
+	ContentType string
+	// GeneratedAt is the time the feed is up to date with; partners pass it as
+	// since on their next fetch to get only what changed
+	GeneratedAt time.Time
+	write       func(ctx context.Context, w io.Writer) error
+}
+
+// Write sends the feed to w. As with exports the status is already sent, so a
+// failure part way through is only logged.
+func (f *PartnerFeed) Write(ctx context.Context, w io.Writer) error {
+	err := f.write(ctx, w)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Failed to write partner feed: %v", err)
+	}
+	return err
+}
+
+func partnerFeedContentType(format string) string {
+	if format == PartnerFeedJSON {
+		return "application/json; charset=utf-8"
+	}
+	return "application/xml; charset=utf-8"
+}
+
+// PartnerFeed returns the partner's active listings. Without since it is the
+// cached full feed. With since it is built on request and holds the listings
+// changed after since, including their photos and features, plus the IDs of
+// listings that were deleted or taken off the market; since can go back as far
+// as deleted listings are kept.
+func (c *AuthController) PartnerFeed(partner models.Partner, format string, since *time.Time) (*PartnerFeed, error) {
+	if format != PartnerFeedXML && format != PartnerFeedJSON {
+		return nil, errors.New("format must be xml or json")
+	}
+
+	if since == nil {
+		if partner.FeedGeneratedAt == nil {
+			return nil, ErrPartnerFeedNotReady
+		}
+
+		key := partnerFeedKey(partner.ID, format)
+		return &PartnerFeed{
+			ContentType: partnerFeedContentType(format),
+			GeneratedAt: *partner.FeedGeneratedAt,
+			write: func(ctx context.Context, w io.Writer) error {
+				body, err := c.Storage.Get(ctx, key)
+				if err != nil {
+					return err
+				}
+				defer body.Close()
+
+				_, err = io.Copy(w, body)
+				return err
+			},
+		}, nil
+	}
+
+	now := time.Now()
+	if since.After(now) {
+		return nil, errors.New("since is in the future")
+	}
+	if since.Before(now.Add(-propertyRetention())) {
+		return nil, errors.New("since is too old to include every deletion, fetch the full feed instead")
+	}
+
+	var deleted []uint
+	err := c.partnerFeedScope(c.DB.Unscoped().Model(&models.Property{}), partner).
+		Where("deleted_at > ? OR (deleted_at IS NULL AND updated_at > ? AND status NOT IN ?)",
+			*since, *since, models.SearchableStatuses).
+		Order("id ASC").
+		Pluck("id", &deleted).Error
+	if err != nil {
+		return nil, errors.New("Failed to build feed")
+	}
+
+	changedMedia := c.DB.Unscoped().Model(&models.PropertyMedia{}).Select("property_id").
+		Where("updated_at > ? OR deleted_at > ?", *since, *since)
+	changedFeatures := c.DB.Unscoped().Model(&models.PropertyFeature{}).Select("property_id").
+		Where("updated_at > ? OR deleted_at > ?", *since, *since)
+	query := c.partnerFeedQuery(partner).
+		Where("updated_at > ? OR id IN (?) OR id IN (?)", *since, changedMedia, changedFeatures)
+
+	return &PartnerFeed{
+		ContentType: partnerFeedContentType(format),
+		GeneratedAt: now,
+		write: func(ctx context.Context, w io.Writer) error {
+			buf := bufio.NewWriter(w)
+			encoder := newPartnerFeedEncoder(format, buf)
+
+			if err := encoder.Start(now, since); err != nil {
+				return err
+			}
+			err := c.eachFeedListing(query.WithContext(ctx), func(listing dto.FeedListingDTO) error {
+				return encoder.Listing(listing)
+			})
+			if err != nil {
+				return err
+			}
+			if err := encoder.Finish(deleted); err != nil {
+				return err
+			}
+			return buf.Flush()
+		},
+	}, nil
+}
+
+// partnerFeedScope narrows a listing query to the partner's purpose and country
+func (c *AuthController) partnerFeedScope(query *gorm.DB, partner models.Partner) *gorm.DB {
+	if partner.Purpose != nil {
+		query = query.Where("properties.purpose = ?", *partner.Purpose)
+	}
+	if partner.CountryID != nil {
+		query = query.Where("properties.country_id = ?", *partner.CountryID)
+	}
+	return query
+}
+
+// partnerFeedQuery selects the listings that are on the market for the partner
+func (c *AuthController) partnerFeedQuery(partner models.Partner) *gorm.DB {
+	query := c.DB.Model(&models.Property{}).Where("properties.status IN ?", models.SearchableStatuses)
+	return c.partnerFeedScope(query, partner)
+}
+
+// eachFeedListing maps the listings of query a batch at a time
+func (c *AuthController) eachFeedListing(query *gorm.DB, fn func(dto.FeedListingDTO) error) error {
+	baseURL := appBaseURL()
+
+	var batch []models.Property
+	result := query.Preload("Country").
+		Preload("Division").
+		Preload("District").
+		Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		FindInBatches(&batch, partnerFeedBatchSize, func(tx *gorm.DB, _ int) error {
+			ids := make([]uint, len(batch))
+			for i, property := range batch {
+				ids[i] = property.ID
+			}
+
+			var features []models.PropertyFeature
+			if err := c.DB.Where("property_id IN ?", ids).Order("id ASC").Find(&features).Error; err != nil {
+				return err
+			}
+
+			// The first feature row of a listing is the one its details show
+			byProperty := map[uint]*models.PropertyFeature{}
+			for i := range features {
+				if _, ok := byProperty[features[i].PropertyID]; !ok {
+					byProperty[features[i].PropertyID] = &features[i]
+				}
+			}
+
+			for _, property := range batch {
+				if err := fn(mapper.PropertyToFeedListing(property, byProperty[property.ID], baseURL)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	return result.Error
+}
+
+// StartPartnerFeeds rebuilds the cached partner feeds when they are older than
+// the refresh interval, in the background until ctx is done. Partners are
+// claimed with SKIP LOCKED, so every API instance can run it.
+func (c *AuthController) StartPartnerFeeds(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(partnerFeedCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			for ctx.Err() == nil && c.buildNextPartnerFeed(ctx) {
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// buildNextPartnerFeed claims one partner with stale feeds and rebuilds them.
+// It reports whether there was anything to do.
+func (c *AuthController) buildNextPartnerFeed(ctx context.Context) bool {
+	partner, err := c.claimPartnerFeed()
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Failed to claim a partner feed: %v", err)
+		}
+		return false
+	}
+
+	// Changes made while the feed is built are picked up by the next since fetch
+	generatedAt := time.Now()
+	if err := c.buildPartnerFeeds(ctx, partner, generatedAt); err != nil {
+		log.Printf("Failed to build the feeds of partner %d: %v", partner.ID, err)
+		// Try again on the next tick rather than straight away
+		c.DB.Model(&partner).UpdateColumn("feed_claimed_at", nil)
+		return false
+	}
+
+	c.DB.Model(&partner).UpdateColumns(map[string]interface{}{
+		"feed_generated_at": generatedAt,
+		"feed_claimed_at":   nil,
+	})
+	return true
+}
+
+func (c *AuthController) claimPartnerFeed() (models.Partner, error) {
+	var partner models.Partner
+	now := time.Now()
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("active = ?", true).
+			Where("feed_generated_at IS NULL OR feed_generated_at < ?", now.Add(-partnerFeedRefresh())).
+			Where("feed_claimed_at IS NULL OR feed_claimed_at < ?", now.Add(-partnerFeedBuildTimeout)).
+			Order("feed_generated_at ASC NULLS FIRST").
+			First(&partner).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&partner).UpdateColumn("feed_claimed_at", now).Error
+	})
+
+	return partner, err
+}
+
+// buildPartnerFeeds writes both formats in one pass over the listings to
+// temporary files, then stores them, so a feed of any size is never held in memory
+func (c *AuthController) buildPartnerFeeds(ctx context.Context, partner models.Partner, generatedAt time.Time) error {
+	files := make([]*os.File, len(partnerFeedFormats))
+	buffers := make([]*bufio.Writer, len(partnerFeedFormats))
+	encoders := make([]partnerFeedEncoder, len(partnerFeedFormats))
+
+	for i, format := range partnerFeedFormats {
+		file, err := os.CreateTemp("", "partner-feed-*."+format)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(file.Name())
+		defer file.Close()
+
+		files[i] = file
+		buffers[i] = bufio.NewWriter(file)
+		encoders[i] = newPartnerFeedEncoder(format, buffers[i])
+		if err := encoders[i].Start(generatedAt, nil); err != nil {
+			return err
+		}
+	}
+
+	err := c.eachFeedListing(c.partnerFeedQuery(partner).WithContext(ctx), func(listing dto.FeedListingDTO) error {
+		for _, encoder := range encoders {
+			if err := encoder.Listing(listing); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, format := range partnerFeedFormats {
+		if err := encoders[i].Finish(nil); err != nil {
+			return err
+		}
+		if err := buffers[i].Flush(); err != nil {
+			return err
+		}
+
+		size, err := files[i].Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if _, err := files[i].Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		if err := c.Storage.Put(ctx, partnerFeedKey(partner.ID, format), partnerFeedContentType(format), files[i], size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// partnerFeedEncoder writes a feed one listing at a time. Deletions are only
+// written for since feeds.
+type partnerFeedEncoder interface {
+	Start(generatedAt time.Time, since *time.Time) error
+	Listing(listing dto.FeedListingDTO) error
+	Finish(deleted []uint) error
+}
+
+func newPartnerFeedEncoder(format string, w io.Writer) partnerFeedEncoder {
+	if format == PartnerFeedJSON {
+		return &jsonFeedEncoder{w: w}
+	}
+	return &xmlFeedEncoder{encoder: xml.NewEncoder(w), w: w}
+}
+
+// xmlFeedEncoder writes the Trovit format: a <trovit> root with an <ad> per listing
+type xmlFeedEncoder struct {
+	encoder *xml.Encoder
+	w       io.Writer
+	since   *time.Time
+}
+
+func (e *xmlFeedEncoder) Start(generatedAt time.Time, since *time.Time) error {
+	e.since = since
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+
+	root := xml.StartElement{
+		Name: xml.Name{Local: "trovit"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "generated_at"}, Value: generatedAt.UTC().Format(time.RFC3339)}},
+	}
+	if since != nil {
+		root.Attr = append(root.Attr, xml.Attr{Name: xml.Name{Local: "since"}, Value: since.UTC().Format(time.RFC3339)})
+	}
+	return e.encoder.EncodeToken(root)
+}
+
+func (e *xmlFeedEncoder) Listing(listing dto.FeedListingDTO) error {
+	return e.encoder.Encode(listing)
+}
+
+func (e *xmlFeedEncoder) Finish(deleted []uint) error {
+	if e.since != nil {
+		removed := struct {
+			IDs []uint `xml:"id"`
+		}{deleted}
+		if err := e.encoder.EncodeElement(removed, xml.StartElement{Name: xml.Name{Local: "deleted"}}); err != nil {
+			return err
+		}
+	}
+
+	if err := e.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "trovit"}}); err != nil {
+		return err
+	}
+	return e.encoder.Flush()
+}
+
+// jsonFeedEncoder writes {"generated_at": ..., "listings": [...]} with "since"
+// and "deleted" added for since feeds
+type jsonFeedEncoder struct {
+	w        io.Writer
+	since    *time.Time
+	listings int
+}
+
+func (e *jsonFeedEncoder) Start(generatedAt time.Time, since *time.Time) error {
+	e.since = since
+
+	header := fmt.Sprintf(`{"generated_at":%q`, generatedAt.UTC().Format(time.RFC3339))
+	if since != nil {
+		header += fmt.Sprintf(`,"since":%q`, since.UTC().Format(time.RFC3339))
+	}
+	_, err := io.WriteString(e.w, header+`,"listings":[`)
+	return err
+}
+
+func (e *jsonFeedEncoder) Listing(listing dto.FeedListingDTO) error {
+	data, err := json.Marshal(listing)
+	if err != nil {
+		return err
+	}
+
+	if e.listings > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.listings++
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonFeedEncoder) Finish(deleted []uint) error {
+	footer := "]"
+	if e.since != nil {
+		if deleted == nil {
+			deleted = []uint{}
+		}
+		data, err := json.Marshal(deleted)
+		if err != nil {
+			return err
+		}
+		footer += `,"deleted":` + string(data)
+	}
+
+	_, err := io.WriteString(e.w, footer+"}")
+	return err
+}
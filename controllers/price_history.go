@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"log"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+// PriceDrop is published after the price of a live listing went down
+type PriceDrop struct {
+	Property models.Property
+	Change   models.PropertyPriceChange
+}
+
+// OnPriceDrop registers a handler that runs in the background for every price
+// drop. Register handlers before the server starts.
+func (c *AuthController) OnPriceDrop(handler func(PriceDrop)) {
+	c.priceDropHandlers = append(c.priceDropHandlers, handler)
+}
+
+func (c *AuthController) publishPriceDrop(drop PriceDrop) {
+	for _, handler := range c.priceDropHandlers {
+		go handler(drop)
+	}
+}
+
+// announceReviewedPriceChange announces a price change made while the listing
+// was waiting for review, comparing to the price it had when last approved:
+// favorites hear about any change, subscribers about a drop
+func (c *AuthController) announceReviewedPriceChange(property models.Property, lastApproval *time.Time) {
+	if lastApproval == nil {
+		return
+	}
+
+	var first models.PropertyPriceChange
+	err := c.DB.Where("property_id = ? AND changed_at > ?", property.ID, *lastApproval).
+		Order("changed_at ASC, id ASC").
+		First(&first).Error
+	if err != nil {
+		return
+	}
+
+	if property.Price == first.OldPrice {
+		return
+	}
+
+	change := first
+	change.NewPrice = property.Price
+	c.notifyFavoritePriceChange(property, change)
+	if change.IsDrop() {
+		c.publishPriceDrop(PriceDrop{Property: property, Change: change})
+	}
+}
+
+// priceHistoryPreload orders a PriceHistory preload oldest first
+func priceHistoryPreload(db *gorm.DB) *gorm.DB {
+	return db.Order("changed_at ASC, id ASC")
+}
+
+// recordPriceChange adds a price change to the timeline. Only listings that were
+// approved before are tracked; price edits while drafting are not history.
+func recordPriceChange(tx *gorm.DB, property models.Property, newPrice money.Amount, userId uint) (*models.PropertyPriceChange, error) {
+	if newPrice == property.Price || property.ApprovedAt == nil {
+		return nil, nil
+	}
+
+	change := models.PropertyPriceChange{
+		PropertyID:  property.ID,
+		OldPrice:    property.Price,
+		NewPrice:    newPrice,
+		Currency:    property.Currency,
+		ChangedAt:   time.Now(),
+		ChangedByID: &userId,
+	}
+	if err := tx.Create(&change).Error; err != nil {
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// loadPropertyOriginalPrices sets the price before the first recorded change on
+// each property that has one
+func (c *AuthController) loadPropertyOriginalPrices(properties []models.Property) {
+	if len(properties) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(properties))
+	for _, property := range properties {
+		ids = append(ids, property.ID)
+	}
+
+	var originals []struct {
+		PropertyID uint
+		OldPrice   money.Amount
+	}
+	err := c.DB.Raw(`SELECT DISTINCT ON (property_id) property_id, old_price
+		FROM property_price_changes
+		WHERE property_id IN ?
+		ORDER BY property_id, changed_at ASC, id ASC`, ids).
+		Scan(&originals).Error
+	if err != nil {
+		log.Printf("Failed to load original prices: %v", err)
+		return
+	}
+
+	prices := map[uint]money.Amount{}
+	for _, original := range originals {
+		prices[original.PropertyID] = original.OldPrice
+	}
+
+	for i := range properties {
+		if price, ok := prices[properties[i].ID]; ok {
+			properties[i].OriginalPrice = &price
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+// projectPreload loads the project of a unit with its location and photos
+func projectPreload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Project.Country").
+		Preload("Project.Division").
+		Preload("Project.District").
+		Preload("Project.Media", galleryPreload).
+		Preload("Project.Media.Renditions")
+}
+
+// projectDetailsPreload loads what a project page shows
+func projectDetailsPreload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Country").
+		Preload("Division").
+		Preload("District").
+		Preload("Media", galleryPreload).
+		Preload("Media.Renditions")
+}
+
+// liveUnitsExist matches projects with at least one unit in public search.
+// Projects are not moderated themselves, they are shown once a unit is live.
+const liveUnitsExist = "EXISTS (SELECT 1 FROM properties WHERE properties.project_id = projects.id AND properties.status IN ? AND properties.deleted_at IS NULL)"
+
+func (c *AuthController) ownerProject(projectId uint32, userId uint) (models.Project, error) {
+	var project models.Project
+
+	if err := c.DB.Where("owner_id = ? AND id = ?", userId, projectId).First(&project).Error; err != nil {
+		return project, errors.New("Project not found")
+	}
+
+	return project, nil
+}
+
+// validateProjectRequest checks the location of a project like that of a listing
+func (c *AuthController) validateProjectRequest(request dto.ProjectRequestDTO) error {
+	if err := c.DB.First(&models.Country{}, request.CountryID).Error; err != nil {
+		return errors.New("country not found")
+	}
+
+	if err := c.DB.First(&models.Division{}, request.DivisionID).Error; err != nil {
+		return errors.New("division not found")
+	}
+
+	var district models.District
+	if err := c.DB.First(&district, request.DistrictID).Error; err != nil {
+		return errors.New("district not found")
+	}
+
+	if request.CompletionDate != nil {
+		if _, err := time.Parse("2006-01-02", *request.CompletionDate); err != nil {
+			return errors.New("completion_date must be a date like 2006-01-02")
+		}
+	}
+
+	return validatePropertyCoordinates(request.Latitude, request.Longitude, district)
+}
+
+// applyProjectLocation gives a unit the location of its project
+func applyProjectLocation(request *dto.PropertyRequestDTO, project models.Project) {
+	request.CountryID = project.CountryID
+	request.DivisionID = project.DivisionID
+	request.DistrictID = project.DistrictID
+	request.Address = project.Address
+	request.Latitude = project.Latitude
+	request.Longitude = project.Longitude
+}
+
+func (c *AuthController) CreateProject(request dto.ProjectRequestDTO, userId uint) (*dto.ProjectDetailsDTO, error) {
+	if err := c.validateProjectRequest(request); err != nil {
+		return nil, err
+	}
+
+	project := mapper.ProjectDTOToModel(request, userId)
+	if err := c.DB.Create(&project).Error; err != nil {
+		return nil, errors.New("project creation failed")
+	}
+
+	return c.projectDetails(project.ID, nil)
+}
+
+// UpdateProject replaces the project details. Its units move along when the
+// location changes, each with its own audit record, and live units go back to
+// review like after an owner edit of their address.
+func (c *AuthController) UpdateProject(projectId uint32, userId uint, request dto.ProjectRequestDTO, requestID string) (*dto.ProjectDetailsDTO, error) {
+	existing, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validateProjectRequest(request); err != nil {
+		return nil, err
+	}
+
+	project := mapper.ProjectDTOToModel(request, userId)
+	project.Model = existing.Model
+
+	var sendBackToReview []models.Property
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&project).Error; err != nil {
+			return err
+		}
+
+		// Deleted units move too, so they are in place when restored
+		var units []models.Property
+		if err := tx.Unscoped().Where("project_id = ?", project.ID).Find(&units).Error; err != nil {
+			return err
+		}
+
+		for _, unit := range units {
+			before := models.NewPropertySnapshot(unit)
+
+			updated := unit
+			updated.CountryID = project.CountryID
+			updated.DivisionID = project.DivisionID
+			updated.DistrictID = project.DistrictID
+			updated.Address = project.Address
+			updated.Latitude = project.Latitude
+			updated.Longitude = project.Longitude
+
+			after := models.NewPropertySnapshot(updated)
+			if changes, err := auditChanges(before, after); err != nil || len(changes) == 0 {
+				continue
+			}
+
+			err := tx.Unscoped().Model(&unit).Updates(map[string]interface{}{
+				"country_id":  updated.CountryID,
+				"division_id": updated.DivisionID,
+				"district_id": updated.DistrictID,
+				"address":     updated.Address,
+				"latitude":    updated.Latitude,
+				"longitude":   updated.Longitude,
+			}).Error
+			if err != nil {
+				return err
+			}
+
+			audit := models.AuditLog{
+				PropertyID: unit.ID,
+				EntityType: models.AuditEntityProperty,
+				EntityID:   unit.ID,
+				Action:     models.AuditActionUpdate,
+				ActorID:    &userId,
+				RequestID:  requestID,
+			}
+			if err := recordAudit(tx, audit, before, after); err != nil {
+				return err
+			}
+
+			locationChanged := before.Address != after.Address ||
+				before.CountryID != after.CountryID ||
+				before.DivisionID != after.DivisionID ||
+				before.DistrictID != after.DistrictID
+			if unit.Status == models.StatusActive && !unit.DeletedAt.Valid && locationChanged {
+				sendBackToReview = append(sendBackToReview, updated)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("Failed to update project")
+	}
+
+	for i := range sendBackToReview {
+		reason := "project location changed"
+		if err := c.transitionProperty(&sendBackToReview[i], models.StatusPendingReview, models.ActorSystem, &userId, &reason, requestID); err != nil {
+			return nil, errors.New("Failed to send units back to review")
+		}
+	}
+
+	return c.projectDetails(project.ID, nil)
+}
+
+// DeleteProject removes a project and its photos. Units, including deleted ones
+// that can still be restored, have to go first.
+func (c *AuthController) DeleteProject(projectId uint32, userId uint) error {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return err
+	}
+
+	var units int64
+	c.DB.Unscoped().Model(&models.Property{}).Where("project_id = ?", project.ID).Count(&units)
+	if units > 0 {
+		return errors.New("Delete the units of the project first")
+	}
+
+	var mediaIDs []uint
+	if err := c.DB.Unscoped().Model(&models.PropertyMedia{}).Where("project_id = ?", project.ID).Pluck("id", &mediaIDs).Error; err != nil {
+		return errors.New("Failed to delete project")
+	}
+
+	gallery := projectGallery(project)
+	for _, mediaId := range mediaIDs {
+		if err := c.deleteGalleryMedia(gallery, mediaId); err != nil {
+			return err
+		}
+	}
+
+	if err := c.DB.Delete(&project).Error; err != nil {
+		return errors.New("Failed to delete project")
+	}
+
+	return nil
+}
+
+func (c *AuthController) OwnerProjects(userId uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	return c.projectList(c.DB.Model(&models.Project{}).Where("owner_id = ?", userId), page, pageSize)
+}
+
+func (c *AuthController) OwnerProjectDetails(projectId uint32, userId uint) (*dto.ProjectDetailsDTO, error) {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.projectDetails(project.ID, nil)
+}
+
+// PublicProjects lists the projects with live units, newest first
+func (c *AuthController) PublicProjects(filter dto.ProjectFilterDTO, page, pageSize int) (*dto.PaginatedResponse, error) {
+	query := c.DB.Model(&models.Project{}).Where(liveUnitsExist, models.SearchableStatuses)
+
+	if filter.CountryID > 0 {
+		query = query.Where("country_id = ?", filter.CountryID)
+	}
+
+	if filter.DivisionID > 0 {
+		query = query.Where("division_id = ?", filter.DivisionID)
+	}
+
+	if filter.DistrictID > 0 {
+		query = query.Where("district_id = ?", filter.DistrictID)
+	}
+
+	return c.projectList(query, page, pageSize)
+}
+
+func (c *AuthController) PublicProjectDetails(projectId uint32) (*dto.ProjectDetailsDTO, error) {
+	return c.projectDetails(uint(projectId), func(db *gorm.DB) *gorm.DB {
+		return db.Where(liveUnitsExist, models.SearchableStatuses)
+	})
+}
+
+// CreateProjectUnit lists a unit of a project. The unit takes the project
+// location and goes through review like any listing.
+func (c *AuthController) CreateProjectUnit(projectId uint32, userId uint, request dto.ProjectUnitRequestDTO, requestID string) (*dto.PropertyListDTO, error) {
+	project, err := c.ownerProject(projectId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	description := request.Description
+	if description == "" {
+		description = project.Description
+	}
+
+	unit := dto.PropertyRequestDTO{
+		Title:        request.Title,
+		Purpose:      request.Purpose,
+		Price:        request.Price,
+		Currency:     request.Currency,
+		PropertyType: request.PropertyType,
+		Bedrooms:     request.Bedrooms,
+		Bathrooms:    request.Bathrooms,
+		Size:         request.Size,
+		BuiltYear:    request.BuiltYear,
+		Description:  description,
+		ExternalRef:  request.ExternalRef,
+		RentalTerms:  request.RentalTerms,
+		Floor:        request.Floor,
+		ProjectID:    &project.ID,
+	}
+	applyProjectLocation(&unit, project)
+
+	return c.CreateProperty(unit, userId, requestID)
+}
+
+func (c *AuthController) projectList(query *gorm.DB, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var projects []models.Project
+	var total int64
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting projects")
+	}
+
+	offset := (page - 1) * pageSize
+
+	err := query.Preload("Country").
+		Preload("Division").
+		Preload("District").
+		Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&projects).Error
+	if err != nil {
+		return nil, errors.New("error retrieving projects")
+	}
+
+	ids := make([]uint, 0, len(projects))
+	for _, project := range projects {
+		ids = append(ids, project.ID)
+	}
+	units := c.loadProjectUnits(ids)
+
+	responseDTOs := []dto.ProjectListDTO{}
+	for _, project := range projects {
+		responseDTOs = append(responseDTOs, mapper.ProjectModelToListDTO(project, units[project.ID]))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+func (c *AuthController) projectDetails(projectId uint, scope func(*gorm.DB) *gorm.DB) (*dto.ProjectDetailsDTO, error) {
+	query := c.DB.Scopes(projectDetailsPreload)
+	if scope != nil {
+		query = query.Scopes(scope)
+	}
+
+	var project models.Project
+	if err := query.First(&project, projectId).Error; err != nil {
+		return nil, errors.New("Project not found")
+	}
+
+	response := dto.ProjectDetailsDTO{
+		ProjectDTO:      mapper.ProjectModelToDTO(project),
+		ProjectUnitsDTO: c.loadProjectUnits([]uint{project.ID})[project.ID],
+	}
+	return &response, nil
+}
+
+// loadProjectUnits counts the live units of each project and sums up the
+// available ones per purpose, bedrooms and currency
+func (c *AuthController) loadProjectUnits(ids []uint) map[uint]dto.ProjectUnitsDTO {
+	units := map[uint]dto.ProjectUnitsDTO{}
+	for _, id := range ids {
+		units[id] = dto.ProjectUnitsDTO{UnitTypes: []dto.ProjectUnitTypeDTO{}}
+	}
+	if len(ids) == 0 {
+		return units
+	}
+
+	var totals []struct {
+		ProjectID uint
+		Total     int64
+		Available int64
+	}
+	err := c.DB.Model(&models.Property{}).
+		Select("project_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE status = ?) AS available", models.StatusActive).
+		Where("project_id IN ? AND status IN ?", ids, models.SearchableStatuses).
+		Group("project_id").
+		Scan(&totals).Error
+	if err != nil {
+		return units
+	}
+
+	var types []struct {
+		ProjectID      uint
+		Purpose        string
+		Bedrooms       int
+		Currency       string
+		AvailableUnits int64
+		MinPrice       money.Amount
+		MaxPrice       money.Amount
+		MinSize        float64
+		MaxSize        float64
+	}
+	err = c.DB.Model(&models.Property{}).
+		Select("project_id, purpose, bedrooms, currency, COUNT(*) AS available_units, MIN(price) AS min_price, MAX(price) AS max_price, MIN(size) AS min_size, MAX(size) AS max_size").
+		Where("project_id IN ? AND status = ?", ids, models.StatusActive).
+		Group("project_id, purpose, bedrooms, currency").
+		Order("project_id, purpose, bedrooms, currency").
+		Scan(&types).Error
+	if err != nil {
+		return units
+	}
+
+	for _, total := range totals {
+		summary := units[total.ProjectID]
+		summary.TotalUnits = total.Total
+		summary.AvailableUnits = total.Available
+		units[total.ProjectID] = summary
+	}
+
+	for _, unitType := range types {
+		summary := units[unitType.ProjectID]
+		summary.UnitTypes = append(summary.UnitTypes, dto.ProjectUnitTypeDTO{
+			Purpose:        unitType.Purpose,
+			Bedrooms:       unitType.Bedrooms,
+			Currency:       unitType.Currency,
+			AvailableUnits: unitType.AvailableUnits,
+			MinPrice:       unitType.MinPrice,
+			MaxPrice:       unitType.MaxPrice,
+			MinSize:        unitType.MinSize,
+			MaxSize:        unitType.MaxSize,
+		})
+		units[unitType.ProjectID] = summary
+	}
+
+	return units
+}
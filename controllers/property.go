@@ -2,33 +2,111 @@ package controllers
 
 import (
 	"errors"
-	"fmt"
+	"strings"
 
 	"github.com/farhapartex/real_estate_be/dto"
 	"github.com/farhapartex/real_estate_be/mapper"
 	"github.com/farhapartex/real_estate_be/models"
+	"github.com/farhapartex/real_estate_be/utils"
+	"gorm.io/gorm"
 )
 
 func (c *AuthController) GetProperties(filter dto.PropertyFilterDTO) (*dto.PaginatedResponse, error) {
 	var properties []models.Property
 	var total int64
 
+	rates, err := c.currentExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+	if filter.Currency != "" && !rates.supports(filter.Currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	query, center, err := c.propertyFilterQuery(filter, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting properties")
+	}
+
+	offset := (filter.Page - 1) * filter.PerPage
+
+	// Radius searches are returned nearest first with the distance attached
+	if center != nil {
+		query = query.Select("properties.*, "+haversineSQL+" AS distance", center.Lat, center.Lat, center.Lng).
+			Order("distance ASC")
+	}
+
+	// Execute query with pagination
+	err = query.Preload("Country").
+		Preload("Division").
+		Preload("District").
+		Preload("Owner").
+		Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		Preload("Project.Media", galleryPreload).
+		Preload("Project.Media.Renditions").
+		Offset(offset).
+		Limit(filter.PerPage).
+		Order("created_at DESC").
+		Find(&properties).Error
+
+	if err != nil {
+		return nil, errors.New("error retrieving properties")
+	}
+
+	c.loadPropertyViews(properties)
+	c.loadPropertyInquiries(properties)
+	c.loadPropertyFavorites(properties, filter.ViewerID)
+	c.loadPropertyOriginalPrices(properties)
+
+	var responseDTOs []dto.PropertyListDTO
+	for _, property := range properties {
+		dto := mapper.PropertyModelToResponseDTOMapper(property)
+		if filter.Currency != "" {
+			dto.DisplayPrice = rates.displayPrice(property.Price, property.Currency, filter.Currency)
+		}
+		responseDTOs = append(responseDTOs, dto)
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, filter.Page, filter.PerPage)
+
+	return &response, nil
+}
+
+// propertyFilterQuery builds the listing query for the filters of GetProperties.
+// Price bounds are in filter.Currency and converted with rates. When the
+// filters search around a point the parsed center is returned as well.
+func (c *AuthController) propertyFilterQuery(filter dto.PropertyFilterDTO, rates *exchangeRates) (*gorm.DB, *utils.LatLng, error) {
 	// Build query with filters
 	query := c.DB.Model(&models.Property{})
 
-	query = query.Where("owner_id = ?", filter.OwerID)
+	if filter.OwerID > 0 {
+		query = query.Where("owner_id = ?", filter.OwerID)
+	}
 
 	// Apply filters
 	if filter.Purpose != "" {
 		query = query.Where("purpose = ?", filter.Purpose)
 	}
 
-	if filter.MinPrice > 0 {
-		query = query.Where("price >= ?", filter.MinPrice)
+	if filter.MinPrice > 0 || filter.MaxPrice > 0 {
+		if !rates.supports(filter.Currency) {
+			return nil, nil, ErrUnsupportedCurrency
+		}
+		condition, args := rates.amountRangeCondition("properties.price", filter.MinPrice, filter.MaxPrice, filter.Currency)
+		query = query.Where(condition, args...)
 	}
 
-	if filter.MaxPrice > 0 {
-		query = query.Where("price <= ?", filter.MaxPrice)
+	condition, args, err := rentalTermsCondition(filter, rates)
+	if err != nil {
+		return nil, nil, err
+	}
+	if condition != "" {
+		query = query.Where(condition, args...)
 	}
 
 	if filter.PropertyType != "" {
@@ -36,11 +114,11 @@ func (c *AuthController) GetProperties(filter dto.PropertyFilterDTO) (*dto.Pagin
 	}
 
 	if filter.BedRooms > 0 {
-		query = query.Where("bed_rooms >= ?", filter.BedRooms)
+		query = query.Where("bedrooms >= ?", filter.BedRooms)
 	}
 
 	if filter.BathRooms > 0 {
-		query = query.Where("bath_rooms >= ?", filter.BathRooms)
+		query = query.Where("bathrooms >= ?", filter.BathRooms)
 	}
 
 	if filter.MinSize > 0 {
@@ -63,44 +141,22 @@ func (c *AuthController) GetProperties(filter dto.PropertyFilterDTO) (*dto.Pagin
 		query = query.Where("district_id = ?", filter.DistrictID)
 	}
 
-	if filter.Status != "" {
-		fmt.Println("Status filter applied:", filter.Status)
-		query = query.Where("status = ?", filter.Status)
+	if filter.ProjectID > 0 {
+		query = query.Where("project_id = ?", filter.ProjectID)
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, errors.New("error counting properties")
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
 	}
 
-	offset := (filter.Page - 1) * filter.PerPage
-
-	// Execute query with pagination
-	err := query.Preload("Country").
-		Preload("Division").
-		Preload("District").
-		Preload("Owner").
-		Offset(offset).
-		Limit(filter.PerPage).
-		Order("created_at DESC").
-		Find(&properties).Error
-
-	if err != nil {
-		return nil, errors.New("error retrieving properties")
-	}
-
-	var responseDTOs []dto.PropertyListDTO
-	for _, property := range properties {
-		fmt.Print("Status:", property.Status)
-		dto := mapper.PropertyModelToResponseDTOMapper(property)
-		responseDTOs = append(responseDTOs, dto)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
 	}
 
-	response := mapper.CreatePaginatedResponse(responseDTOs, total, filter.Page, filter.PerPage)
-
-	return &response, nil
+	return applyGeoFilters(query, filter)
 }
 
-func (c *AuthController) CreateProperty(request dto.PropertyRequestDTO, userID uint) (*dto.PropertyListDTO, error) {
+func (c *AuthController) CreateProperty(request dto.PropertyRequestDTO, userID uint, requestID string) (*dto.PropertyListDTO, error) {
 	// Verify that country, division, and district exist
 	var country models.Country
 	var division models.Division
@@ -118,6 +174,25 @@ func (c *AuthController) CreateProperty(request dto.PropertyRequestDTO, userID u
 		return nil, errors.New("district not found")
 	}
 
+	if err := validatePropertyCoordinates(request.Latitude, request.Longitude, district); err != nil {
+		return nil, err
+	}
+
+	request.ExternalRef = normalizeExternalRef(request.ExternalRef)
+	if request.ExternalRef != nil && c.externalRefTaken(userID, *request.ExternalRef, 0) {
+		return nil, errors.New("a property with this external reference already exists")
+	}
+
+	currency, err := c.listingCurrency(request.Currency, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Currency = currency
+
+	if err := validateRentalTerms(request.Purpose, request.RentalTerms); err != nil {
+		return nil, err
+	}
+
 	// Create new property
 	newProperty := mapper.PropertyDtoToModelMapper(request, userID)
 
@@ -127,12 +202,30 @@ func (c *AuthController) CreateProperty(request dto.PropertyRequestDTO, userID u
 		return nil, errors.New("property creation failed: " + err.Error())
 	}
 
+	if _, err := syncRentalTerms(tx, newProperty, request.RentalTerms, userID, requestID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Fetch the created property with all relationships to build the response
 	if err := tx.Preload("Country").Preload("Division").Preload("District").Preload("Owner").First(&newProperty, newProperty.ID).Error; err != nil {
 		tx.Rollback()
 		return nil, errors.New("error retrieving created property")
 	}
 
+	audit := models.AuditLog{
+		PropertyID: newProperty.ID,
+		EntityType: models.AuditEntityProperty,
+		EntityID:   newProperty.ID,
+		Action:     models.AuditActionCreate,
+		ActorID:    &userID,
+		RequestID:  requestID,
+	}
+	if err := recordAudit(tx, audit, nil, models.NewPropertySnapshot(newProperty)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, errors.New("property creation failed during commit")
 	}
@@ -144,7 +237,14 @@ func (c *AuthController) CreateProperty(request dto.PropertyRequestDTO, userID u
 func (c *AuthController) PropertyDetails(propertyId uint32, userId uint) (*dto.PropertyResponseDTO, error) {
 	var property models.Property
 
-	if err := c.DB.Where("owner_id = ? AND id = ?", userId, propertyId).First(&property).Error; err != nil {
+	err := c.DB.Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		Preload("PriceHistory", priceHistoryPreload).
+		Preload("RentalTerms").
+		Scopes(projectPreload).
+		Where("owner_id = ? AND id = ?", userId, propertyId).
+		First(&property).Error
+	if err != nil {
 		return nil, errors.New("Property not found")
 	}
 
@@ -153,30 +253,127 @@ func (c *AuthController) PropertyDetails(propertyId uint32, userId uint) (*dto.P
 	return &response, nil
 }
 
-func (c *AuthController) PropertyPatch(propertyId uint32, userId uint, request dto.PropertyRequestDTO) (*dto.PropertyResponseDTO, error) {
+func (c *AuthController) PropertyPatch(propertyId uint32, userId uint, request dto.PropertyRequestDTO, requestID string) (*dto.PropertyResponseDTO, error) {
 	var property models.Property
 
 	if err := c.DB.Where("owner_id = ? AND id = ?", userId, propertyId).First(&property).Error; err != nil {
 		return nil, errors.New("Property not found")
 	}
 
-	result := c.DB.Model(&property).Updates(models.Property{
-		Title:        request.Title,
-		Purpose:      models.PropertyString(request.Purpose),
-		Price:        request.Price,
-		PropertyType: request.PropertyType,
-		Bedrooms:     request.Bedrooms,
-		Bathrooms:    request.Bathrooms,
-		Size:         request.Size,
-		BuiltYear:    request.BuiltYear,
-		CountryID:    request.CountryID,
-		DivisionID:   request.DivisionID,
-		Address:      request.Address,
-		Description:  request.Description,
+	// Units of a project stay where the project is
+	if property.ProjectID != nil {
+		var project models.Project
+		if err := c.DB.First(&project, *property.ProjectID).Error; err != nil {
+			return nil, errors.New("Project not found")
+		}
+		applyProjectLocation(&request, project)
+	}
+
+	var district models.District
+	if err := c.DB.First(&district, request.DistrictID).Error; err != nil {
+		return nil, errors.New("district not found")
+	}
+
+	if err := validatePropertyCoordinates(request.Latitude, request.Longitude, district); err != nil {
+		return nil, err
+	}
+
+	request.ExternalRef = normalizeExternalRef(request.ExternalRef)
+	if request.ExternalRef != nil && c.externalRefTaken(userId, *request.ExternalRef, property.ID) {
+		return nil, errors.New("a property with this external reference already exists")
+	}
+
+	currency, err := c.listingCurrency(request.Currency, &property)
+	if err != nil {
+		return nil, err
+	}
+	request.Currency = currency
+
+	if err := validateRentalTerms(request.Purpose, request.RentalTerms); err != nil {
+		return nil, err
+	}
+
+	// Editing what buyers see on a live listing needs another review
+	sendBackToReview := property.Status == models.StatusActive && propertyKeyFieldsChanged(property, request)
+
+	before := models.NewPropertySnapshot(property)
+
+	var priceChange *models.PropertyPriceChange
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if priceChange, err = recordPriceChange(tx, property, request.Price, userId); err != nil {
+			return err
+		}
+
+		// The columns are listed so that a cleared optional field, such as the
+		// coordinates, is written as NULL instead of being skipped
+		err = tx.Model(&property).Select(
+			"title", "purpose", "price", "currency", "property_type", "bedrooms", "bathrooms",
+			"size", "built_year", "country_id", "division_id", "district_id", "address",
+			"latitude", "longitude", "description", "external_ref", "floor",
+		).Updates(models.Property{
+			Title:        request.Title,
+			Purpose:      models.PropertyString(request.Purpose),
+			Price:        request.Price,
+			Currency:     request.Currency,
+			PropertyType: request.PropertyType,
+			Bedrooms:     request.Bedrooms,
+			Bathrooms:    request.Bathrooms,
+			Size:         request.Size,
+			BuiltYear:    request.BuiltYear,
+			CountryID:    request.CountryID,
+			DivisionID:   request.DivisionID,
+			DistrictID:   request.DistrictID,
+			Address:      request.Address,
+			Latitude:     request.Latitude,
+			Longitude:    request.Longitude,
+			Description:  request.Description,
+			ExternalRef:  request.ExternalRef,
+			Floor:        request.Floor,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		var updated models.Property
+		if err := tx.First(&updated, property.ID).Error; err != nil {
+			return err
+		}
+
+		audit := models.AuditLog{
+			PropertyID: property.ID,
+			EntityType: models.AuditEntityProperty,
+			EntityID:   property.ID,
+			Action:     models.AuditActionUpdate,
+			ActorID:    &userId,
+			RequestID:  requestID,
+		}
+		if err := recordAudit(tx, audit, before, models.NewPropertySnapshot(updated)); err != nil {
+			return err
+		}
+
+		property.RentalTerms, err = syncRentalTerms(tx, updated, request.RentalTerms, userId, requestID)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	if result.Error != nil {
-		return nil, result.Error
+	if sendBackToReview {
+		reason := "listing details changed"
+		if err := c.transitionProperty(&property, models.StatusPendingReview, models.ActorSystem, &userId, &reason, requestID); err != nil {
+			return nil, errors.New("Failed to send property back to review")
+		}
+	}
+
+	// Price changes of hidden listings are not news to buyers. A listing sent
+	// back to review announces its new price once it is approved again.
+	if priceChange != nil && property.Status.IsSearchable() {
+		property.Price = request.Price
+		c.notifyFavoritePriceChange(property, *priceChange)
+		if priceChange.IsDrop() {
+			c.publishPriceDrop(PriceDrop{Property: property, Change: *priceChange})
+		}
 	}
 
 	response := mapper.PropertyModelToDetailsResponseDTOMapper(property)
@@ -184,7 +381,34 @@ func (c *AuthController) PropertyPatch(propertyId uint32, userId uint, request d
 	return &response, nil
 }
 
-func (c *AuthController) CreatePropertyFeature(request dto.PropertyFeatureDTO, userID uint) (*dto.PropertyFeatureDetailsDTO, error) {
+// normalizeExternalRef trims the reference; a blank one counts as not given
+func normalizeExternalRef(ref *string) *string {
+	if ref == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*ref)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// externalRefTaken reports whether another listing of the owner, including
+// deleted ones that can still be restored, uses the reference
+func (c *AuthController) externalRefTaken(ownerId uint, ref string, exceptId uint) bool {
+	var count int64
+	c.DB.Unscoped().Model(&models.Property{}).
+		Where("owner_id = ? AND external_ref = ? AND id <> ?", ownerId, ref, exceptId).
+		Count(&count)
+	return count > 0
+}
+
+func (c *AuthController) CreatePropertyFeature(request dto.PropertyFeatureDTO, userID uint, requestID string) (*dto.PropertyFeatureDetailsDTO, error) {
+	if _, err := c.ownerProperty(uint32(request.PropertyID), userID); err != nil {
+		return nil, err
+	}
+
 	newPropFeature := mapper.PropertyFeatureDTOToModel(request)
 
 	tx := c.DB.Begin()
@@ -193,6 +417,19 @@ func (c *AuthController) CreatePropertyFeature(request dto.PropertyFeatureDTO, u
 		return nil, errors.New("property feature creation failed: " + err.Error())
 	}
 
+	audit := models.AuditLog{
+		PropertyID: newPropFeature.PropertyID,
+		EntityType: models.AuditEntityPropertyFeature,
+		EntityID:   newPropFeature.ID,
+		Action:     models.AuditActionCreate,
+		ActorID:    &userID,
+		RequestID:  requestID,
+	}
+	if err := recordAudit(tx, audit, nil, models.NewPropertyFeatureSnapshot(newPropFeature)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, errors.New("property feature creation failed during commit")
 	}
@@ -202,9 +439,14 @@ func (c *AuthController) CreatePropertyFeature(request dto.PropertyFeatureDTO, u
 }
 
 func (c *AuthController) PropertyFeatureDetails(propertyId uint32, userID uint) (*dto.PropertyFeatureDetailsDTO, error) {
+	property, err := c.ownerProperty(propertyId, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	var propFeature models.PropertyFeature
 
-	if err := c.DB.Where("property_id = ?", propertyId).First(&propFeature).Error; err != nil {
+	if err := c.DB.Where("property_id = ?", property.ID).First(&propFeature).Error; err != nil {
 		return nil, errors.New("Property feature not found")
 	}
 
@@ -213,10 +455,15 @@ func (c *AuthController) PropertyFeatureDetails(propertyId uint32, userID uint)
 	return &response, nil
 }
 
-func (c *AuthController) DeletePropertyFeature(propertyId uint32, userID uint) error {
+func (c *AuthController) DeletePropertyFeature(propertyId uint32, userID uint, requestID string) error {
+	property, err := c.ownerProperty(propertyId, userID)
+	if err != nil {
+		return err
+	}
+
 	var propFeature models.PropertyFeature
 
-	if err := c.DB.Where("property_id = ?", propertyId).First(&propFeature).Error; err != nil {
+	if err := c.DB.Where("property_id = ?", property.ID).First(&propFeature).Error; err != nil {
 		return errors.New("Property feature not found")
 	}
 
@@ -226,6 +473,19 @@ func (c *AuthController) DeletePropertyFeature(propertyId uint32, userID uint) e
 		return errors.New("Failed to delete property feature")
 	}
 
+	audit := models.AuditLog{
+		PropertyID: propFeature.PropertyID,
+		EntityType: models.AuditEntityPropertyFeature,
+		EntityID:   propFeature.ID,
+		Action:     models.AuditActionDelete,
+		ActorID:    &userID,
+		RequestID:  requestID,
+	}
+	if err := recordAudit(tx, audit, models.NewPropertyFeatureSnapshot(propFeature), nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return errors.New("Failed to delete property feature")
 	}
@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// propertyPurgeInterval is how often deleted listings past retention are purged
+	propertyPurgeInterval = time.Hour
+	// propertyPurgeBatchSize is how many listings are purged per transaction
+	propertyPurgeBatchSize = 20
+)
+
+// propertyCascadeModels are soft deleted and restored together with their listing
+var propertyCascadeModels = []interface{}{
+	&models.PropertyFeature{},
+	&models.PropertyMedia{},
+	&models.PropertyAttachment{},
+}
+
+// propertyRetention is how long a deleted listing can still be restored
+func propertyRetention() time.Duration {
+	return time.Duration(envInt64("PROPERTY_RETENTION_DAYS", 30)) * 24 * time.Hour
+}
+
+// DeleteProperty soft deletes an owner's listing with its features, photos and
+// documents. All rows share the deletion time, so a restore brings back exactly
+// what was deleted with the listing.
+func (c *AuthController) DeleteProperty(propertyId uint32, userId uint, requestID string) error {
+	property, err := c.ownerProperty(propertyId, userId)
+	if err != nil {
+		return err
+	}
+
+	var features []models.PropertyFeature
+	if err := c.DB.Where("property_id = ?", property.ID).Find(&features).Error; err != nil {
+		return errors.New("Failed to delete property")
+	}
+
+	before := models.NewPropertySnapshot(property)
+	deletedAt := time.Now()
+
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range propertyCascadeModels {
+			if err := tx.Model(model).Where("property_id = ?", property.ID).Update("deleted_at", deletedAt).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&property).Update("deleted_at", deletedAt).Error; err != nil {
+			return err
+		}
+
+		for _, feature := range features {
+			audit := models.AuditLog{
+				PropertyID: property.ID,
+				EntityType: models.AuditEntityPropertyFeature,
+				EntityID:   feature.ID,
+				Action:     models.AuditActionDelete,
+				ActorID:    &userId,
+				RequestID:  requestID,
+			}
+			if err := recordAudit(tx, audit, models.NewPropertyFeatureSnapshot(feature), nil); err != nil {
+				return err
+			}
+		}
+
+		audit := models.AuditLog{
+			PropertyID: property.ID,
+			EntityType: models.AuditEntityProperty,
+			EntityID:   property.ID,
+			Action:     models.AuditActionDelete,
+			ActorID:    &userId,
+			RequestID:  requestID,
+		}
+		return recordAudit(tx, audit, before, nil)
+	})
+	if err != nil {
+		return errors.New("Failed to delete property")
+	}
+
+	return nil
+}
+
+// DeletedProperties lists soft deleted listings that have not been purged yet, most recent first
+func (c *AuthController) DeletedProperties(page, pageSize int) (*dto.PaginatedResponse, error) {
+	var properties []models.Property
+	var total int64
+
+	query := c.DB.Unscoped().Model(&models.Property{}).Where("deleted_at IS NOT NULL")
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting properties")
+	}
+
+	offset := (page - 1) * pageSize
+
+	err := query.Preload("Country").
+		Preload("Division").
+		Preload("District").
+		Preload("Owner").
+		Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		Order("deleted_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&properties).Error
+	if err != nil {
+		return nil, errors.New("error retrieving properties")
+	}
+
+	retention := propertyRetention()
+
+	responseDTOs := []dto.DeletedPropertyDTO{}
+	for _, property := range properties {
+		responseDTOs = append(responseDTOs, mapper.PropertyModelToDeletedDTOMapper(property, retention))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+// RestoreDeletedProperty brings back a soft deleted listing together with the
+// rows that were deleted with it. The listing keeps its status.
+func (c *AuthController) RestoreDeletedProperty(propertyId uint32, admin models.User, requestID string) (*dto.PropertyResponseDTO, error) {
+	var property models.Property
+	if err := c.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&property, propertyId).Error; err != nil {
+		return nil, errors.New("Deleted property not found")
+	}
+
+	deletedAt := property.DeletedAt.Time
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range propertyCascadeModels {
+			err := tx.Unscoped().Model(model).
+				Where("property_id = ? AND deleted_at = ?", property.ID, deletedAt).
+				Update("deleted_at", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Model(&property).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		var features []models.PropertyFeature
+		if err := tx.Where("property_id = ?", property.ID).Find(&features).Error; err != nil {
+			return err
+		}
+
+		for _, feature := range features {
+			audit := models.AuditLog{
+				PropertyID: property.ID,
+				EntityType: models.AuditEntityPropertyFeature,
+				EntityID:   feature.ID,
+				Action:     models.AuditActionRestore,
+				ActorID:    &admin.ID,
+				RequestID:  requestID,
+			}
+			if err := recordAudit(tx, audit, nil, models.NewPropertyFeatureSnapshot(feature)); err != nil {
+				return err
+			}
+		}
+
+		audit := models.AuditLog{
+			PropertyID: property.ID,
+			EntityType: models.AuditEntityProperty,
+			EntityID:   property.ID,
+			Action:     models.AuditActionRestore,
+			ActorID:    &admin.ID,
+			RequestID:  requestID,
+		}
+		return recordAudit(tx, audit, nil, models.NewPropertySnapshot(property))
+	})
+	if err != nil {
+		return nil, errors.New("Failed to restore property")
+	}
+
+	err = c.DB.Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		Preload("PriceHistory", priceHistoryPreload).
+		Preload("RentalTerms").
+		Scopes(projectPreload).
+		First(&property, propertyId).Error
+	if err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	response := mapper.PropertyModelToDetailsResponseDTOMapper(property)
+	return &response, nil
+}
+
+// StartPropertyPurge hard deletes listings that were deleted longer ago than the
+// retention period, including their stored files, in the background until ctx
+// is done. Listings are claimed with SKIP LOCKED, so every API instance can run it.
+func (c *AuthController) StartPropertyPurge(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(propertyPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			c.purgeDeletedProperties(time.Now().Add(-propertyRetention()))
+		}
+	}()
+}
+
+func (c *AuthController) purgeDeletedProperties(deletedBefore time.Time) {
+	for {
+		purged, err := c.purgeDeletedPropertyBatch(deletedBefore)
+		if err != nil {
+			log.Printf("Failed to purge deleted properties: %v", err)
+			return
+		}
+		if purged < propertyPurgeBatchSize {
+			return
+		}
+	}
+}
+
+// purgeDeletedPropertyBatch removes the rows first and the files once that is
+// committed; a file that fails to delete is only logged, the listing is gone either way
+func (c *AuthController) purgeDeletedPropertyBatch(deletedBefore time.Time) (int, error) {
+	var ids []uint
+	var keys []string
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&models.Property{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("deleted_at < ?", deletedBefore).
+			Order("id ASC").
+			Limit(propertyPurgeBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if keys, err = propertyStorageKeys(tx, ids); err != nil {
+			return err
+		}
+
+		return purgeProperties(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		if err := c.Storage.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to delete %s of a purged property: %v", key, err)
+		}
+	}
+
+	return len(ids), nil
+}
+
+// propertyStorageKeys returns the stored photos, renditions and documents of the listings
+func propertyStorageKeys(tx *gorm.DB, ids []uint) ([]string, error) {
+	var mediaKeys, renditionKeys, attachmentKeys []string
+
+	if err := tx.Unscoped().Model(&models.PropertyMedia{}).Where("property_id IN ?", ids).Pluck("s3_key", &mediaKeys).Error; err != nil {
+		return nil, err
+	}
+
+	mediaIDs := tx.Unscoped().Model(&models.PropertyMedia{}).Select("id").Where("property_id IN ?", ids)
+	if err := tx.Model(&models.PropertyMediaRendition{}).Where("media_id IN (?)", mediaIDs).Pluck("s3_key", &renditionKeys).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Unscoped().Model(&models.PropertyAttachment{}).Where("property_id IN ?", ids).Pluck("s3_key", &attachmentKeys).Error; err != nil {
+		return nil, err
+	}
+
+	keys := append(renditionKeys, mediaKeys...)
+	return append(keys, attachmentKeys...), nil
+}
+
+// purgeProperties deletes the listings and everything that references them
+func purgeProperties(tx *gorm.DB, ids []uint) error {
+	mediaIDs := tx.Unscoped().Model(&models.PropertyMedia{}).Select("id").Where("property_id IN ?", ids)
+	if err := tx.Where("media_id IN (?)", mediaIDs).Delete(&models.PropertyMediaRendition{}).Error; err != nil {
+		return err
+	}
+
+	inquiryIDs := tx.Unscoped().Model(&models.Inquiry{}).Select("id").Where("property_id IN ?", ids)
+	if err := tx.Where("inquiry_id IN (?)", inquiryIDs).Delete(&models.InquiryReply{}).Error; err != nil {
+		return err
+	}
+
+	conversationIDs := tx.Model(&models.Conversation{}).Select("id").Where("property_id IN ?", ids)
+	if err := tx.Where("conversation_id IN (?)", conversationIDs).Delete(&models.Message{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("conversation_id IN (?)", conversationIDs).Delete(&models.ConversationParticipant{}).Error; err != nil {
+		return err
+	}
+
+	dependents := []interface{}{
+		&models.PropertyMedia{},
+		&models.PropertyAttachment{},
+		&models.PropertyFeature{},
+		&models.PropertyRentalTerms{},
+		&models.Inquiry{},
+		&models.Conversation{},
+		&models.Favorite{},
+		&models.SavedSearchMatch{},
+		&models.PropertyPriceChange{},
+		&models.PropertyStatusHistory{},
+		&models.PropertyDailyStat{},
+		&models.PropertyViewVisitor{},
+		&models.AuditLog{},
+	}
+	for _, model := range dependents {
+		if err := tx.Unscoped().Where("property_id IN ?", ids).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Delete(&models.Property{}, ids).Error
+}
@@ -0,0 +1,732 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime/multipart"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/farhapartex/real_estate_be/lib/spreadsheet"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxImportFileSize = 10 * 1024 * 1024
+	// importPollInterval is how often the idle worker looks for imports it was
+	// not woken for, e.g. uploaded on another instance
+	importPollInterval = time.Minute
+	// importProcessingTimeout is how long an import may stay in processing before
+	// it is assumed the worker died and it is started over
+	importProcessingTimeout = 30 * time.Minute
+)
+
+// propertyImportColumns documents the columns of an import file. Header names
+// are matched case-insensitively, spaces count as underscores and unknown
+// columns are ignored.
+var propertyImportColumns = []dto.PropertyImportColumnDTO{
+	{Name: "external_ref", Description: "Your own reference for the listing. A row whose reference matches one of your listings updates that listing instead of creating a new one."},
+	{Name: "title", Required: true},
+	{Name: "purpose", Required: true, Description: "sale or rent"},
+	{Name: "price", Required: true, Description: "Greater than 0, at most 2 decimal places"},
+	{Name: "currency", Description: "ISO 4217 code like USD, the default currency when empty"},
+	{Name: "property_type", Required: true},
+	{Name: "bedrooms", Required: true, Description: "Whole number"},
+	{Name: "bathrooms", Required: true, Description: "Whole number"},
+	{Name: "size", Required: true, Description: "Greater than 0"},
+	{Name: "built_year"},
+	{Name: "country", Required: true, Description: "Country name or ID"},
+	{Name: "division", Required: true, Description: "Division name or ID, within the country"},
+	{Name: "district", Required: true, Description: "District name or ID, within the division"},
+	{Name: "address", Required: true},
+	{Name: "latitude", Description: "Decimal degrees, give both coordinates or neither"},
+	{Name: "longitude", Description: "Decimal degrees, give both coordinates or neither"},
+	{Name: "description", Required: true},
+	{Name: "floor", Description: "Whole number, 0 for the ground floor"},
+}
+
+// importColumnAliases lets files use the API field names for locations
+var importColumnAliases = map[string]string{
+	"country_id":  "country",
+	"division_id": "division",
+	"district_id": "district",
+}
+
+// maxImportRows is how many listings one file may hold, MAX_IMPORT_ROWS (default 1000)
+func maxImportRows() int {
+	return int(envInt64("MAX_IMPORT_ROWS", 1000))
+}
+
+func (c *AuthController) PropertyImportColumns() []dto.PropertyImportColumnDTO {
+	return propertyImportColumns
+}
+
+// CreatePropertyImport stores an uploaded file and queues it for the import
+// worker. Files that cannot be read or lack required columns are rejected
+// right away instead of failing later.
+func (c *AuthController) CreatePropertyImport(userId uint, file *multipart.FileHeader, dryRun bool, requestID string) (*dto.PropertyImportDTO, error) {
+	format := spreadsheet.Format(file.Filename)
+	if format == "" {
+		return nil, spreadsheet.ErrUnsupportedFormat
+	}
+
+	if file.Size > maxImportFileSize {
+		return nil, errors.New("File is too large, the limit is 10 MB")
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, errors.New("Failed to read file")
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxImportFileSize+1))
+	if err != nil || len(data) > maxImportFileSize {
+		return nil, errors.New("Failed to read file")
+	}
+
+	rows, err := spreadsheet.Read(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := importHeader(rows); err != nil {
+		return nil, err
+	}
+
+	if limit := maxImportRows(); len(rows)-1 > limit {
+		return nil, fmt.Errorf("File has more than %d rows, split it into smaller files", limit)
+	}
+
+	contentType := "text/csv"
+	if format == spreadsheet.FormatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	key := fmt.Sprintf("imports/%d/%d.%s", userId, time.Now().UnixNano(), format)
+	if err := c.Storage.Put(context.Background(), key, contentType, bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, errors.New("Failed to store file")
+	}
+
+	record := models.PropertyImport{
+		OwnerID:   userId,
+		FileName:  path.Base(file.Filename),
+		Format:    format,
+		FileKey:   key,
+		DryRun:    dryRun,
+		RequestID: requestID,
+		Status:    models.ImportPending,
+	}
+	if err := c.DB.Create(&record).Error; err != nil {
+		return nil, errors.New("Failed to create import")
+	}
+
+	c.NotifyPropertyImports()
+
+	response := mapper.PropertyImportToDTO(record)
+	return &response, nil
+}
+
+func (c *AuthController) PropertyImports(userId uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var imports []models.PropertyImport
+	var total int64
+
+	query := c.DB.Model(&models.PropertyImport{}).Where("owner_id = ?", userId)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting imports")
+	}
+
+	offset := (page - 1) * pageSize
+
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&imports).Error; err != nil {
+		return nil, errors.New("error retrieving imports")
+	}
+
+	responseDTOs := []dto.PropertyImportDTO{}
+	for _, record := range imports {
+		responseDTOs = append(responseDTOs, mapper.PropertyImportToDTO(record))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+func (c *AuthController) PropertyImportDetails(importId uint32, userId uint) (*dto.PropertyImportDTO, error) {
+	var record models.PropertyImport
+	if err := c.DB.Where("owner_id = ?", userId).First(&record, importId).Error; err != nil {
+		return nil, errors.New("Import not found")
+	}
+
+	response := mapper.PropertyImportToDTO(record)
+	return &response, nil
+}
+
+// PropertyImportReport renders the row results of an import as CSV and
+// returns it with a file name for the download
+func (c *AuthController) PropertyImportReport(importId uint32, userId uint) ([]byte, string, error) {
+	var record models.PropertyImport
+	if err := c.DB.Where("owner_id = ?", userId).First(&record, importId).Error; err != nil {
+		return nil, "", errors.New("Import not found")
+	}
+
+	var rows []models.PropertyImportRow
+	if err := c.DB.Where("import_id = ?", record.ID).Order("row_number ASC").Find(&rows).Error; err != nil {
+		return nil, "", errors.New("Failed to fetch import report")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"row", "external_ref", "result", "property_id", "error"})
+
+	for _, row := range rows {
+		externalRef, propertyId, message := "", "", ""
+		if row.ExternalRef != nil {
+			externalRef = *row.ExternalRef
+		}
+		if row.PropertyID != nil {
+			propertyId = strconv.FormatUint(uint64(*row.PropertyID), 10)
+		}
+		if row.Error != nil {
+			message = *row.Error
+		}
+
+		writer.Write([]string{strconv.Itoa(row.RowNumber), externalRef, string(row.Result), propertyId, message})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, "", errors.New("Failed to build import report")
+	}
+
+	return buf.Bytes(), fmt.Sprintf("import-%d-report.csv", record.ID), nil
+}
+
+// StartPropertyImports starts the background worker that processes uploaded
+// imports. It stops when ctx is done.
+func (c *AuthController) StartPropertyImports(ctx context.Context) {
+	go c.propertyImportWorker(ctx)
+}
+
+// NotifyPropertyImports wakes the worker after a file was uploaded
+func (c *AuthController) NotifyPropertyImports() {
+	select {
+	case c.importQueue <- struct{}{}:
+	default:
+	}
+}
+
+func (c *AuthController) propertyImportWorker(ctx context.Context) {
+	ticker := time.NewTicker(importPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil && c.processNextPropertyImport() {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.importQueue:
+		case <-ticker.C:
+		}
+	}
+}
+
+// processNextPropertyImport claims one queued import and runs it. It reports
+// whether there was anything to do.
+func (c *AuthController) processNextPropertyImport() bool {
+	record, err := c.claimPropertyImport()
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Failed to claim property import: %v", err)
+		}
+		return false
+	}
+
+	if err := c.processPropertyImport(record); err != nil {
+		log.Printf("Failed to process property import %d: %v", record.ID, err)
+
+		message := err.Error()
+		c.DB.Model(&record).Updates(map[string]interface{}{
+			"status":      models.ImportFailed,
+			"error":       message,
+			"finished_at": time.Now(),
+		})
+	}
+
+	if err := c.Storage.Delete(context.Background(), record.FileKey); err != nil {
+		log.Printf("Failed to delete import file %s: %v", record.FileKey, err)
+	}
+
+	return true
+}
+
+// claimPropertyImport marks the oldest queued import as processing. SKIP LOCKED
+// keeps two instances from picking the same one.
+func (c *AuthController) claimPropertyImport() (models.PropertyImport, error) {
+	var record models.PropertyImport
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND started_at < ?)",
+				models.ImportPending, models.ImportProcessing, time.Now().Add(-importProcessingTimeout)).
+			Order("id ASC").
+			First(&record).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&record).Updates(map[string]interface{}{
+			"status":     models.ImportProcessing,
+			"started_at": time.Now(),
+		}).Error
+	})
+
+	return record, err
+}
+
+func (c *AuthController) processPropertyImport(record models.PropertyImport) error {
+	body, err := c.Storage.Get(context.Background(), record.FileKey)
+	if err != nil {
+		return errors.New("Failed to read the uploaded file")
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxImportFileSize+1))
+	if err != nil {
+		return errors.New("Failed to read the uploaded file")
+	}
+
+	rows, err := spreadsheet.Read(record.Format, data)
+	if err != nil {
+		return err
+	}
+
+	header, err := importHeader(rows)
+	if err != nil {
+		return err
+	}
+
+	// An import picked up again after a crash starts over
+	if err := c.DB.Where("import_id = ?", record.ID).Delete(&models.PropertyImportRow{}).Error; err != nil {
+		return errors.New("Failed to reset import results")
+	}
+
+	run := propertyImportRun{
+		record:    record,
+		locations: newImportLocations(c.DB),
+		refs:      map[string]int{},
+	}
+
+	counts := map[models.ImportRowResult]int{}
+	for i, cells := range rows[1:] {
+		values := header.values(cells)
+		if isBlankImportRow(values) {
+			continue
+		}
+
+		// Row numbers match the spreadsheet, the header is row 1
+		result := c.importPropertyRow(&run, i+2, values)
+		if err := c.DB.Create(&result).Error; err != nil {
+			return errors.New("Failed to record import results")
+		}
+		counts[result.Result]++
+	}
+
+	return c.DB.Model(&record).Updates(map[string]interface{}{
+		"status":       models.ImportCompleted,
+		"total_rows":   counts[models.ImportRowCreated] + counts[models.ImportRowUpdated] + counts[models.ImportRowFailed],
+		"created_rows": counts[models.ImportRowCreated],
+		"updated_rows": counts[models.ImportRowUpdated],
+		"failed_rows":  counts[models.ImportRowFailed],
+		"finished_at":  time.Now(),
+	}).Error
+}
+
+// propertyImportRun is the state shared by the rows of one import
+type propertyImportRun struct {
+	record    models.PropertyImport
+	locations *importLocations
+	// refs maps each external_ref seen so far to its row number
+	refs map[string]int
+}
+
+// importPropertyRow validates one row with the same rules as the API and,
+// unless it is a dry run, creates the listing or updates the one with the
+// same external_ref. An update keeps the listing's values for the optional
+// columns the file does not have.
+func (c *AuthController) importPropertyRow(run *propertyImportRun, number int, values map[string]string) models.PropertyImportRow {
+	ownerId := run.record.OwnerID
+	row := models.PropertyImportRow{
+		ImportID:    run.record.ID,
+		RowNumber:   number,
+		ExternalRef: normalizeExternalRef(optionalImportValue(values, "external_ref")),
+	}
+
+	fail := func(err error) models.PropertyImportRow {
+		message := err.Error()
+		row.Result = models.ImportRowFailed
+		row.PropertyID = nil
+		row.Error = &message
+		return row
+	}
+
+	request, err := propertyImportRequest(values, run.locations)
+	if err != nil {
+		return fail(err)
+	}
+	request.ExternalRef = row.ExternalRef
+
+	var existing *models.Property
+	if row.ExternalRef != nil {
+		if first, ok := run.refs[*row.ExternalRef]; ok {
+			return fail(fmt.Errorf("external_ref is already used on row %d", first))
+		}
+		run.refs[*row.ExternalRef] = number
+
+		var property models.Property
+		err := c.DB.Unscoped().Where("owner_id = ? AND external_ref = ?", ownerId, *row.ExternalRef).First(&property).Error
+		if err == nil {
+			if property.DeletedAt.Valid {
+				return fail(errors.New("the listing with this external_ref was deleted"))
+			}
+			existing = &property
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fail(errors.New("Failed to look up external_ref"))
+		}
+	}
+
+	if existing != nil {
+		keepMissingImportColumns(&request, values, *existing)
+	}
+
+	if err := binding.Validator.ValidateStruct(&request); err != nil {
+		return fail(err)
+	}
+
+	district, err := run.locations.district(request.DistrictID)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := validatePropertyCoordinates(request.Latitude, request.Longitude, district); err != nil {
+		return fail(err)
+	}
+
+	// Checked here as well so dry runs report it
+	if _, err := c.listingCurrency(request.Currency, existing); err != nil {
+		return fail(err)
+	}
+
+	if existing != nil {
+		row.Result = models.ImportRowUpdated
+		row.PropertyID = &existing.ID
+
+		if !run.record.DryRun {
+			if _, err := c.PropertyPatch(uint32(existing.ID), ownerId, request, run.record.RequestID); err != nil {
+				return fail(err)
+			}
+		}
+		return row
+	}
+
+	row.Result = models.ImportRowCreated
+	if !run.record.DryRun {
+		response, err := c.CreateProperty(request, ownerId, run.record.RequestID)
+		if err != nil {
+			return fail(err)
+		}
+
+		propertyId := uint(response.ID)
+		row.PropertyID = &propertyId
+	}
+
+	return row
+}
+
+// propertyImportRequest converts a row into the API request. Empty cells stay
+// zero so the validator reports missing required fields like the API does.
+func propertyImportRequest(values map[string]string, locations *importLocations) (dto.PropertyRequestDTO, error) {
+	request := dto.PropertyRequestDTO{
+		Title:        values["title"],
+		Purpose:      strings.ToLower(values["purpose"]),
+		Currency:     strings.ToUpper(values["currency"]),
+		PropertyType: values["property_type"],
+		Address:      values["address"],
+		Description:  values["description"],
+	}
+
+	var err error
+	if request.Price, err = importAmount(values, "price"); err != nil {
+		return request, err
+	}
+	if request.Size, err = importFloat(values, "size"); err != nil {
+		return request, err
+	}
+	if request.Bedrooms, err = importInt(values, "bedrooms"); err != nil {
+		return request, err
+	}
+	if request.Bathrooms, err = importInt(values, "bathrooms"); err != nil {
+		return request, err
+	}
+	if request.BuiltYear, err = importInt(values, "built_year"); err != nil {
+		return request, err
+	}
+	if request.Latitude, err = importOptionalFloat(values, "latitude"); err != nil {
+		return request, err
+	}
+	if request.Longitude, err = importOptionalFloat(values, "longitude"); err != nil {
+		return request, err
+	}
+	if request.Floor, err = importOptionalInt(values, "floor"); err != nil {
+		return request, err
+	}
+
+	if request.CountryID, err = locations.resolve(&models.Country{}, "country", values["country"], "", 0); err != nil {
+		return request, err
+	}
+	if request.DivisionID, err = locations.resolve(&models.Division{}, "division", values["division"], "country_id", request.CountryID); err != nil {
+		return request, err
+	}
+	if request.DistrictID, err = locations.resolve(&models.District{}, "district", values["district"], "division_id", request.DivisionID); err != nil {
+		return request, err
+	}
+
+	return request, nil
+}
+
+// keepMissingImportColumns fills the optional fields whose column is not in
+// the file from the listing being updated, so that a file with fewer columns
+// does not clear them. A column that is there but empty still clears the value.
+// Rental terms have no columns and are left out, which keeps the current ones.
+func keepMissingImportColumns(request *dto.PropertyRequestDTO, values map[string]string, existing models.Property) {
+	missing := func(column string) bool {
+		_, ok := values[column]
+		return !ok
+	}
+
+	if missing("built_year") {
+		request.BuiltYear = existing.BuiltYear
+	}
+	if missing("latitude") && missing("longitude") {
+		request.Latitude = existing.Latitude
+		request.Longitude = existing.Longitude
+	}
+	if missing("floor") {
+		request.Floor = existing.Floor
+	}
+}
+
+func optionalImportValue(values map[string]string, column string) *string {
+	value, ok := values[column]
+	if !ok || value == "" {
+		return nil
+	}
+	return &value
+}
+
+func importFloat(values map[string]string, column string) (float64, error) {
+	value := values[column]
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("%s: %q is not a number", column, value)
+	}
+	return number, nil
+}
+
+// importAmount reads a price exactly, without going through a float
+func importAmount(values map[string]string, column string) (money.Amount, error) {
+	value := values[column]
+	if value == "" {
+		return 0, nil
+	}
+
+	amount, err := money.Parse(strings.ReplaceAll(value, ",", ""))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not an amount with at most 2 decimal places", column, value)
+	}
+	return amount, nil
+}
+
+func importOptionalFloat(values map[string]string, column string) (*float64, error) {
+	if values[column] == "" {
+		return nil, nil
+	}
+
+	number, err := importFloat(values, column)
+	if err != nil {
+		return nil, err
+	}
+	return &number, nil
+}
+
+// importInt also accepts whole numbers written as decimals, as spreadsheets store them
+func importInt(values map[string]string, column string) (int, error) {
+	number, err := importFloat(values, column)
+	if err != nil || number != math.Trunc(number) || math.Abs(number) > math.MaxInt32 {
+		return 0, fmt.Errorf("%s: %q is not a whole number", column, values[column])
+	}
+	return int(number), nil
+}
+
+func importOptionalInt(values map[string]string, column string) (*int, error) {
+	if values[column] == "" {
+		return nil, nil
+	}
+
+	number, err := importInt(values, column)
+	if err != nil {
+		return nil, err
+	}
+	return &number, nil
+}
+
+func isBlankImportRow(values map[string]string) bool {
+	for _, value := range values {
+		if value != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// importColumns maps each known column to its position in the file
+type importColumns map[string]int
+
+// importHeader reads the header row and checks that every required column is there
+func importHeader(rows [][]string) (importColumns, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("File is empty")
+	}
+
+	known := map[string]bool{}
+	for _, column := range propertyImportColumns {
+		known[column.Name] = true
+	}
+
+	columns := importColumns{}
+	for i, cell := range rows[0] {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if alias, ok := importColumnAliases[name]; ok {
+			name = alias
+		}
+
+		if _, duplicate := columns[name]; known[name] && !duplicate {
+			columns[name] = i
+		}
+	}
+
+	var missing []string
+	for _, column := range propertyImportColumns {
+		if _, ok := columns[column.Name]; column.Required && !ok {
+			missing = append(missing, column.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, errors.New("File is missing required columns: " + strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// values returns the trimmed cells of a row by column name
+func (columns importColumns) values(cells []string) map[string]string {
+	values := make(map[string]string, len(columns))
+	for name, i := range columns {
+		if i < len(cells) {
+			values[name] = strings.TrimSpace(cells[i])
+		} else {
+			values[name] = ""
+		}
+	}
+	return values
+}
+
+// importLocations resolves location names to IDs, caching the lookups for the
+// whole file since imports tend to repeat the same few places
+type importLocations struct {
+	db        *gorm.DB
+	ids       map[string]uint32
+	districts map[uint32]models.District
+}
+
+func newImportLocations(db *gorm.DB) *importLocations {
+	return &importLocations{
+		db:        db,
+		ids:       map[string]uint32{},
+		districts: map[uint32]models.District{},
+	}
+}
+
+// resolve accepts an ID or a case-insensitive name, looked up within the parent
+// location when parentColumn is set
+func (l *importLocations) resolve(model interface{}, label string, value string, parentColumn string, parentID uint32) (uint32, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+
+	key := fmt.Sprintf("%s:%d:%s", label, parentID, strings.ToLower(value))
+	id, ok := l.ids[key]
+	if !ok {
+		var ids []uint32
+		query := l.db.Model(model).Where("LOWER(name) = LOWER(?)", value)
+		if parentColumn != "" {
+			query = query.Where(parentColumn+" = ?", parentID)
+		}
+		if err := query.Limit(2).Pluck("id", &ids).Error; err != nil {
+			return 0, fmt.Errorf("Failed to look up %s", label)
+		}
+
+		if len(ids) > 1 {
+			return 0, fmt.Errorf("%s %q is ambiguous, use its ID instead", label, value)
+		}
+		if len(ids) == 1 {
+			id = ids[0]
+		}
+		l.ids[key] = id
+	}
+
+	if id == 0 {
+		return 0, fmt.Errorf("%s %q not found", label, value)
+	}
+	return id, nil
+}
+
+func (l *importLocations) district(id uint32) (models.District, error) {
+	if district, ok := l.districts[id]; ok {
+		return district, nil
+	}
+
+	var district models.District
+	if err := l.db.First(&district, id).Error; err != nil {
+		return district, errors.New("district not found")
+	}
+
+	l.districts[id] = district
+	return district, nil
+}
@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/config"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// viewFlushInterval is how often buffered views are written
+	viewFlushInterval = 10 * time.Second
+	// viewBufferLimit flushes early when this many views are waiting
+	viewBufferLimit = 1000
+)
+
+// Crawlers, link previews and scripts are not visitors
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|facebookexternalhit|embedly|preview|headless|lighthouse|curl|wget|python|java/|go-http-client|okhttp|axios|postman`)
+
+type propertyView struct {
+	PropertyID  uint
+	Day         string
+	VisitorHash string
+}
+
+// propertyViewBuffer collects views in memory so the detail page never writes
+// to the database. Repeat views inside one flush window are dropped right away.
+type propertyViewBuffer struct {
+	mu      sync.Mutex
+	pending map[propertyView]struct{}
+	full    chan struct{}
+}
+
+func newPropertyViewBuffer() *propertyViewBuffer {
+	return &propertyViewBuffer{
+		pending: map[propertyView]struct{}{},
+		full:    make(chan struct{}, 1),
+	}
+}
+
+func (b *propertyViewBuffer) add(view propertyView) {
+	b.mu.Lock()
+	b.pending[view] = struct{}{}
+	size := len(b.pending)
+	b.mu.Unlock()
+
+	if size >= viewBufferLimit {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (b *propertyViewBuffer) drain() []propertyView {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	views := make([]propertyView, 0, len(b.pending))
+	for view := range b.pending {
+		views = append(views, view)
+	}
+	b.pending = map[propertyView]struct{}{}
+
+	return views
+}
+
+// PublicPropertyDetails returns a live listing and counts the visit. visitor
+// identifies the visitor for de-duplication (user or IP). A currency
+// adds the price converted to it.
+func (c *AuthController) PublicPropertyDetails(propertyId uint32, user *models.User, visitor string, userAgent string, currency string) (*dto.PropertyResponseDTO, error) {
+	var rates *exchangeRates
+	if currency != "" {
+		var err error
+		if rates, err = c.currentExchangeRates(); err != nil {
+			return nil, err
+		}
+		if !rates.supports(currency) {
+			return nil, ErrUnsupportedCurrency
+		}
+	}
+
+	var property models.Property
+
+	err := c.DB.Preload("Country").
+		Preload("Division").
+		Preload("District").
+		Preload("Media", galleryPreload).
+		Preload("Media.Renditions").
+		Preload("PriceHistory", priceHistoryPreload).
+		Preload("RentalTerms").
+		Scopes(projectPreload).
+		Where("status IN ?", models.SearchableStatuses).
+		First(&property, propertyId).Error
+	if err != nil {
+		return nil, errors.New("Property not found")
+	}
+
+	// Owners checking their own listing are not visitors
+	if user == nil || user.ID != property.OwnerID {
+		c.RecordPropertyView(property.ID, visitor, userAgent)
+	}
+
+	if user != nil {
+		property.IsFavorited = c.isFavorite(user.ID, property.ID)
+	}
+
+	response := mapper.PropertyModelToDetailsResponseDTOMapper(property)
+	if rates != nil {
+		response.DisplayPrice = rates.displayPrice(property.Price, property.Currency, currency)
+	}
+	return &response, nil
+}
+
+// RecordPropertyView buffers a view unless it comes from a bot. Only a salted hash
+// of the visitor is kept.
+func (c *AuthController) RecordPropertyView(propertyID uint, visitor string, userAgent string) {
+	if c.viewBuffer == nil || userAgent == "" || botUserAgent.MatchString(userAgent) {
+		return
+	}
+
+	day := time.Now().UTC().Format("2006-01-02")
+
+	c.viewBuffer.add(propertyView{
+		PropertyID:  propertyID,
+		Day:         day,
+		VisitorHash: hashIdentifier(day + "|" + visitor),
+	})
+}
+
+// hashIdentifier keys an IP address or similar with the server secret so it can be
+// compared without being stored
+func hashIdentifier(value string) string {
+	mac := hmac.New(sha256.New, config.DerivedSecret("visitor-hash"))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// StartViewTracking flushes buffered views in the background until ctx is done,
+// then writes whatever is left
+func (c *AuthController) StartViewTracking(ctx context.Context) {
+	if c.viewBuffer == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(viewFlushInterval)
+		defer ticker.Stop()
+
+		lastCleanup := time.Time{}
+		for {
+			select {
+			case <-ctx.Done():
+				c.flushPropertyViews()
+				return
+			case <-ticker.C:
+			case <-c.viewBuffer.full:
+			}
+
+			c.flushPropertyViews()
+
+			if time.Since(lastCleanup) > time.Hour {
+				c.cleanupViewVisitors()
+				lastCleanup = time.Now()
+			}
+		}
+	}()
+}
+
+// flushPropertyViews stores the buffered views. A view only counts if the visitor
+// row is new, which also de-duplicates across flushes and API instances.
+func (c *AuthController) flushPropertyViews() {
+	views := c.viewBuffer.drain()
+	if len(views) == 0 {
+		return
+	}
+
+	type statKey struct {
+		PropertyID uint
+		Day        string
+	}
+
+	groups := map[statKey][]models.PropertyViewVisitor{}
+	for _, view := range views {
+		day, err := time.Parse("2006-01-02", view.Day)
+		if err != nil {
+			continue
+		}
+
+		key := statKey{view.PropertyID, view.Day}
+		groups[key] = append(groups[key], models.PropertyViewVisitor{
+			PropertyID:  view.PropertyID,
+			Day:         day,
+			VisitorHash: view.VisitorHash,
+		})
+	}
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		for _, visitors := range groups {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&visitors)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			stat := models.PropertyDailyStat{
+				PropertyID: visitors[0].PropertyID,
+				Day:        visitors[0].Day,
+				Views:      result.RowsAffected,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "property_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("property_daily_stats.views + EXCLUDED.views")}),
+			}).Create(&stat).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to store %d property views: %v", len(views), err)
+	}
+}
+
+// cleanupViewVisitors drops visitor rows of past days; the counters are kept
+func (c *AuthController) cleanupViewVisitors() {
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	if err := c.DB.Where("day < ?", yesterday).Delete(&models.PropertyViewVisitor{}).Error; err != nil {
+		log.Printf("Failed to clean up property view visitors: %v", err)
+	}
+}
+
+// loadPropertyViews sets the all-time view count on each property
+func (c *AuthController) loadPropertyViews(properties []models.Property) {
+	if len(properties) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(properties))
+	for _, property := range properties {
+		ids = append(ids, property.ID)
+	}
+
+	var totals []struct {
+		PropertyID uint
+		Views      int64
+	}
+	err := c.DB.Model(&models.PropertyDailyStat{}).
+		Select("property_id, SUM(views) AS views").
+		Where("property_id IN ?", ids).
+		Group("property_id").
+		Scan(&totals).Error
+	if err != nil {
+		log.Printf("Failed to load property views: %v", err)
+		return
+	}
+
+	views := map[uint]int64{}
+	for _, total := range totals {
+		views[total.PropertyID] = total.Views
+	}
+
+	for i := range properties {
+		properties[i].Views = views[properties[i].ID]
+	}
+}
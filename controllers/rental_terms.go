@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+// validateRentalTerms checks rental terms are only given for rent listings
+func validateRentalTerms(purpose string, terms *dto.RentalTermsRequestDTO) error {
+	if terms == nil {
+		return nil
+	}
+
+	if purpose != string(models.PurposeRent) {
+		return errors.New("rental terms only apply to rent listings")
+	}
+
+	if terms.AvailableFrom != nil {
+		if _, err := time.Parse("2006-01-02", *terms.AvailableFrom); err != nil {
+			return errors.New("available_from must be a date like 2006-01-02")
+		}
+	}
+
+	return nil
+}
+
+// syncRentalTerms writes the rental terms of a saved listing and returns the
+// current ones. A listing that is not for rent loses its terms; nil terms on a
+// rent listing keep the current ones.
+func syncRentalTerms(tx *gorm.DB, property models.Property, request *dto.RentalTermsRequestDTO, userId uint, requestID string) (*models.PropertyRentalTerms, error) {
+	var terms models.PropertyRentalTerms
+	var before interface{}
+
+	err := tx.Where("property_id = ?", property.ID).First(&terms).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		terms = models.PropertyRentalTerms{PropertyID: property.ID}
+	} else if err != nil {
+		return nil, errors.New("Failed to save rental terms")
+	} else {
+		before = models.NewRentalTermsSnapshot(terms)
+	}
+
+	audit := models.AuditLog{
+		PropertyID: property.ID,
+		EntityType: models.AuditEntityRentalTerms,
+		EntityID:   terms.ID,
+		ActorID:    &userId,
+		RequestID:  requestID,
+	}
+
+	if property.Purpose != models.PurposeRent {
+		if before == nil {
+			return nil, nil
+		}
+		if err := tx.Delete(&terms).Error; err != nil {
+			return nil, errors.New("Failed to remove rental terms")
+		}
+		audit.Action = models.AuditActionDelete
+		return nil, recordAudit(tx, audit, before, nil)
+	}
+
+	if request == nil {
+		if before == nil {
+			return nil, nil
+		}
+		return &terms, nil
+	}
+
+	models.NewRentalTermsSnapshot(mapper.RentalTermsDTOToModel(*request, property.ID)).Apply(&terms)
+	if err := tx.Save(&terms).Error; err != nil {
+		return nil, errors.New("Failed to save rental terms")
+	}
+
+	audit.EntityID = terms.ID
+	audit.Action = models.AuditActionUpdate
+	if before == nil {
+		audit.Action = models.AuditActionCreate
+	}
+	if err := recordAudit(tx, audit, before, models.NewRentalTermsSnapshot(terms)); err != nil {
+		return nil, err
+	}
+
+	return &terms, nil
+}
+
+// rentalTermsCondition filters listings on their rental terms, "" when the
+// filter has none. The deposit is in filter.Currency and converted like prices.
+func rentalTermsCondition(filter dto.PropertyFilterDTO, rates *exchangeRates) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.RentPeriod != "" {
+		conditions = append(conditions, "property_rental_terms.rent_period = ?")
+		args = append(args, filter.RentPeriod)
+	}
+
+	// Listings open to any tenant match both preferences
+	if filter.TenantPreference != "" {
+		conditions = append(conditions, "property_rental_terms.tenant_preference IN ?")
+		args = append(args, []string{filter.TenantPreference, string(models.TenantAny)})
+	}
+
+	if filter.AvailableBefore != "" {
+		availableBefore, err := time.Parse("2006-01-02", filter.AvailableBefore)
+		if err != nil {
+			return "", nil, errors.New("available_before must be a date like 2006-01-02")
+		}
+		conditions = append(conditions, "(property_rental_terms.available_from IS NULL OR property_rental_terms.available_from <= ?)")
+		args = append(args, availableBefore)
+	}
+
+	if filter.MaxAdvanceMonths > 0 {
+		conditions = append(conditions, "property_rental_terms.advance_months <= ?")
+		args = append(args, filter.MaxAdvanceMonths)
+	}
+
+	if filter.LeaseMonths > 0 {
+		conditions = append(conditions, "property_rental_terms.min_lease_months <= ?")
+		args = append(args, filter.LeaseMonths)
+	}
+
+	if filter.MaxDeposit > 0 {
+		if !rates.supports(filter.Currency) {
+			return "", nil, ErrUnsupportedCurrency
+		}
+		condition, depositArgs := rates.amountRangeCondition("property_rental_terms.security_deposit", 0, filter.MaxDeposit, filter.Currency)
+		conditions = append(conditions, condition)
+		args = append(args, depositArgs...)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+
+	return "EXISTS (SELECT 1 FROM property_rental_terms WHERE property_rental_terms.property_id = properties.id AND " +
+		strings.Join(conditions, " AND ") + ")", args, nil
+}
@@ -0,0 +1,374 @@
+package controllers
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/farhapartex/real_estate_be/lib/odata"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+const (
+	resoNamespace = "org.reso.metadata"
+	// resoDefaultTop and resoMaxTop are the page sizes without and with $top
+	resoDefaultTop = 100
+	resoMaxTop     = 200
+)
+
+// ErrResoNotFound is returned for a key that does not match a record the partner may see
+var ErrResoNotFound = errors.New("No record found with this key")
+
+// resoRoot is the service root of the RESO Web API
+func resoRoot() string {
+	return apiBaseURL() + "/api/v1/reso"
+}
+
+// resoFeatureJSON reads a yes/no or number from the first feature row of a listing
+func resoFeatureJSON(column, key, cast, fallback string) string {
+	return "COALESCE((reso_features." + column + "->>'" + key + "')::" + cast + ", " + fallback + ")"
+}
+
+// resoPropertyJoins make the owner, office, location and feature columns
+// available to $filter and $orderby. Owners and listings have a single profile
+// and feature row in practice; DISTINCT ON keeps it one row per listing anyway.
+var resoPropertyJoins = []string{
+	"LEFT JOIN countries ON countries.id = properties.country_id",
+	"LEFT JOIN divisions ON divisions.id = properties.division_id",
+	"LEFT JOIN districts ON districts.id = properties.district_id",
+	"LEFT JOIN users AS list_agents ON list_agents.id = properties.owner_id",
+	"LEFT JOIN (SELECT DISTINCT ON (user_id) user_id, company_name, phone_number FROM owner_profiles ORDER BY user_id, id) AS list_offices ON list_offices.user_id = properties.owner_id",
+	"LEFT JOIN (SELECT DISTINCT ON (property_id) property_id, amenities, luxury_feature FROM property_features WHERE deleted_at IS NULL ORDER BY property_id, id) AS reso_features ON reso_features.property_id = properties.id",
+}
+
+// resoPropertyFields are the RESO Data Dictionary fields of a listing, see
+// mapper.PropertyToReso for their values
+var resoPropertyFields = []odata.Field{
+	{Name: "ListingKey", Type: odata.TypeString, Column: "CAST(properties.id AS TEXT)"},
+	{Name: "ListingKeyNumeric", Type: odata.TypeInt, Column: "properties.id"},
+	{Name: "ListingId", Type: odata.TypeString, Column: "properties.external_ref", Nullable: true},
+	{Name: "StandardStatus", Type: odata.TypeString, Column: "properties.status", Enum: mapper.ResoStandardStatuses},
+	{Name: "PropertyType", Type: odata.TypeString, Column: "properties.purpose", Enum: mapper.ResoPropertyTypes},
+	{Name: "PropertySubType", Type: odata.TypeString, Column: "properties.property_type"},
+	{Name: "ListPrice", Type: odata.TypeDecimal, Column: "properties.price"},
+	// CurrencyCode is the ISO 4217 currency of ListPrice; the Data Dictionary
+	// assumes one currency per system, listings here have their own
+	{Name: "CurrencyCode", Type: odata.TypeString, Column: "properties.currency"},
+	{Name: "BedroomsTotal", Type: odata.TypeInt, Column: "properties.bedrooms"},
+	{Name: "BathroomsTotalInteger", Type: odata.TypeInt, Column: "properties.bathrooms"},
+	{Name: "LivingArea", Type: odata.TypeDecimal, Column: "properties.size"},
+	{Name: "YearBuilt", Type: odata.TypeInt, Column: "NULLIF(properties.built_year, 0)", Nullable: true},
+	{Name: "UnparsedAddress", Type: odata.TypeString, Column: "properties.address"},
+	{Name: "City", Type: odata.TypeString, Column: "districts.name"},
+	{Name: "StateOrProvince", Type: odata.TypeString, Column: "divisions.name"},
+	{Name: "Country", Type: odata.TypeString, Column: "countries.code"},
+	{Name: "Latitude", Type: odata.TypeDecimal, Column: "properties.latitude", Nullable: true},
+	{Name: "Longitude", Type: odata.TypeDecimal, Column: "properties.longitude", Nullable: true},
+	{Name: "PublicRemarks", Type: odata.TypeString, Column: "properties.description"},
+	{Name: "PhotosCount", Type: odata.TypeInt},
+	{Name: "CoolingYN", Type: odata.TypeBool, Column: resoFeatureJSON("amenities", "airConditioning", "boolean", "FALSE")},
+	{Name: "HeatingYN", Type: odata.TypeBool, Column: resoFeatureJSON("amenities", "heating", "boolean", "FALSE")},
+	{Name: "FireplaceYN", Type: odata.TypeBool, Column: "(" + resoFeatureJSON("amenities", "fireplace", "boolean", "FALSE") + " OR " + resoFeatureJSON("luxury_feature", "fireplace", "boolean", "FALSE") + ")"},
+	{Name: "PoolPrivateYN", Type: odata.TypeBool, Column: "(" + resoFeatureJSON("amenities", "pool", "boolean", "FALSE") + " OR " + resoFeatureJSON("luxury_feature", "pool", "boolean", "FALSE") + ")"},
+	{Name: "Furnished", Type: odata.TypeString, Column: resoFeatureJSON("amenities", "furnished", "text", "'false'"), Enum: mapper.ResoFurnished},
+	{Name: "ParkingTotal", Type: odata.TypeInt, Column: resoFeatureJSON("amenities", "parking", "integer", "0")},
+	{Name: "GarageSpaces", Type: odata.TypeInt, Column: resoFeatureJSON("amenities", "garages", "integer", "0")},
+	{Name: "LotSizeArea", Type: odata.TypeDecimal, Column: resoFeatureJSON("amenities", "lotSize", "numeric", "0")},
+	{Name: "InteriorFeatures", Type: odata.TypeString, Collection: true},
+	{Name: "Appliances", Type: odata.TypeString, Collection: true},
+	{Name: "SecurityFeatures", Type: odata.TypeString, Collection: true},
+	{Name: "AssociationAmenities", Type: odata.TypeString, Collection: true},
+	{Name: "RentIncludes", Type: odata.TypeString, Collection: true},
+	{Name: "GreenEnergyGeneration", Type: odata.TypeString, Collection: true},
+	{Name: "GreenEnergyEfficient", Type: odata.TypeString, Collection: true},
+	{Name: "Flooring", Type: odata.TypeString, Collection: true},
+	{Name: "ListAgentKey", Type: odata.TypeString, Column: "CAST(properties.owner_id AS TEXT)"},
+	{Name: "ListAgentFirstName", Type: odata.TypeString, Column: "list_agents.first_name"},
+	{Name: "ListAgentLastName", Type: odata.TypeString, Column: "list_agents.last_name"},
+	{Name: "ListAgentPreferredPhone", Type: odata.TypeString, Column: "list_offices.phone_number", Nullable: true},
+	{Name: "ListOfficeName", Type: odata.TypeString, Column: "list_offices.company_name", Nullable: true},
+	{Name: "OriginalEntryTimestamp", Type: odata.TypeDateTime, Column: "properties.created_at"},
+	{Name: "ModificationTimestamp", Type: odata.TypeDateTime, Column: "properties.updated_at"},
+}
+
+// resoMediaFields are the fields of the Media resource, the gallery photos of
+// the listings; see mapper.PropertyMediaToReso
+var resoMediaFields = []odata.Field{
+	{Name: "MediaKey", Type: odata.TypeString, Column: "CAST(property_media.id AS TEXT)"},
+	{Name: "ResourceName", Type: odata.TypeString},
+	{Name: "ResourceRecordKey", Type: odata.TypeString, Column: "CAST(property_media.property_id AS TEXT)"},
+	{Name: "MediaCategory", Type: odata.TypeString},
+	{Name: "MediaURL", Type: odata.TypeString},
+	{Name: "MimeType", Type: odata.TypeString, Column: "property_media.content_type"},
+	{Name: "Order", Type: odata.TypeInt, Column: "property_media.sort_order"},
+	{Name: "ShortDescription", Type: odata.TypeString, Column: "property_media.caption"},
+	{Name: "ImageWidth", Type: odata.TypeInt, Column: "property_media.width"},
+	{Name: "ImageHeight", Type: odata.TypeInt, Column: "property_media.height"},
+	{Name: "MediaModificationTimestamp", Type: odata.TypeDateTime, Column: "property_media.updated_at"},
+}
+
+func (c *AuthController) ResoService() dto.ODataServiceDTO {
+	return dto.ODataServiceDTO{
+		Context: resoRoot() + "/$metadata",
+		Value: []dto.ODataEntitySetDTO{
+			{Name: "Property", Kind: "EntitySet", URL: "Property"},
+			{Name: "Media", Kind: "EntitySet", URL: "Media"},
+		},
+	}
+}
+
+func (c *AuthController) ResoMetadata() []byte {
+	return odata.Metadata(resoNamespace, []odata.EntitySet{
+		{Name: "Property", Key: "ListingKey", Fields: resoPropertyFields},
+		{Name: "Media", Key: "MediaKey", Fields: resoMediaFields},
+	})
+}
+
+// resoPropertyQuery selects the listings the partner may see, the same ones as its feeds
+func (c *AuthController) resoPropertyQuery(partner models.Partner) *gorm.DB {
+	query := c.partnerFeedQuery(partner)
+	for _, join := range resoPropertyJoins {
+		query = query.Joins(join)
+	}
+	return query
+}
+
+// resoMediaQuery selects the processed gallery photos of the listings the
+// partner may see
+func (c *AuthController) resoMediaQuery(partner models.Partner) *gorm.DB {
+	query := c.DB.Model(&models.PropertyMedia{}).
+		Joins("JOIN properties ON properties.id = property_media.property_id AND properties.deleted_at IS NULL").
+		Where("property_media.status = ?", models.MediaUploaded).
+		Where("EXISTS (SELECT 1 FROM property_media_renditions WHERE property_media_renditions.media_id = property_media.id AND property_media_renditions.name = ?)", "full").
+		Where("properties.status IN ?", models.SearchableStatuses)
+	return c.partnerFeedScope(query, partner)
+}
+
+// ResoProperties answers a Property collection request. ListPrice filters are
+// in the currency query parameter, the default currency when it is not given,
+// and match listings in every currency with a rate, like the search does.
+func (c *AuthController) ResoProperties(partner models.Partner, values url.Values) (*dto.ODataCollectionDTO, error) {
+	rates, err := c.currentExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+
+	currency := normalizeCurrency(values.Get("currency"))
+	if !rates.supports(currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	return c.resoCollection("Property", values, resoPriceFields(rates, currency), c.resoPropertyQuery(partner),
+		"properties.id ASC", c.resoPropertyRecords)
+}
+
+// resoPriceFields are the Property fields with ListPrice comparisons converted
+// from currency to each listing currency
+func resoPriceFields(rates *exchangeRates, currency string) []odata.Field {
+	fields := make([]odata.Field, len(resoPropertyFields))
+	copy(fields, resoPropertyFields)
+
+	for i := range fields {
+		if fields[i].Name != "ListPrice" {
+			continue
+		}
+		fields[i].Compare = func(op string, value interface{}) (string, []interface{}, error) {
+			amount, err := money.Parse(strconv.FormatFloat(value.(float64), 'f', -1, 64))
+			if err != nil {
+				return "", nil, errors.New("ListPrice values can have at most 2 decimal places")
+			}
+			condition, args := rates.amountCondition("properties.price", op, amount, currency)
+			return condition, args, nil
+		}
+	}
+	return fields
+}
+
+// ResoProperty returns the listing with the key, in its $select fields
+func (c *AuthController) ResoProperty(partner models.Partner, key string, values url.Values) (map[string]interface{}, error) {
+	id, err := strconv.ParseUint(key, 10, 32)
+	if err != nil {
+		return nil, ErrResoNotFound
+	}
+	return c.resoEntity("Property", values, resoPropertyFields,
+		c.resoPropertyQuery(partner).Where("properties.id = ?", id), c.resoPropertyRecords)
+}
+
+// ResoMediaList answers a Media collection request
+func (c *AuthController) ResoMediaList(partner models.Partner, values url.Values) (*dto.ODataCollectionDTO, error) {
+	return c.resoCollection("Media", values, resoMediaFields, c.resoMediaQuery(partner),
+		"property_media.property_id ASC, property_media.sort_order ASC, property_media.id ASC", c.resoMediaRecords)
+}
+
+// ResoMedia returns the photo with the key, in its $select fields
+func (c *AuthController) ResoMedia(partner models.Partner, key string, values url.Values) (map[string]interface{}, error) {
+	id, err := strconv.ParseUint(key, 10, 32)
+	if err != nil {
+		return nil, ErrResoNotFound
+	}
+	return c.resoEntity("Media", values, resoMediaFields,
+		c.resoMediaQuery(partner).Where("property_media.id = ?", id), c.resoMediaRecords)
+}
+
+// resoCollection runs an OData collection request against query. defaultOrder
+// ends every sort so pages stay stable; load fetches and maps one page.
+func (c *AuthController) resoCollection(set string, values url.Values, fields []odata.Field, query *gorm.DB,
+	defaultOrder string, load func(*gorm.DB) ([]map[string]interface{}, error)) (*dto.ODataCollectionDTO, error) {
+	q, err := odata.Parse(values, fields, resoDefaultTop, resoMaxTop)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Where != "" {
+		query = query.Where(q.Where, q.Args...)
+	}
+
+	response := &dto.ODataCollectionDTO{
+		Context: resoRoot() + "/$metadata#" + set,
+		Value:   []map[string]interface{}{},
+	}
+
+	if q.Count {
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return nil, errors.New("Failed to count records")
+		}
+		response.Count = &total
+	}
+
+	if q.Top == 0 {
+		return response, nil
+	}
+
+	for _, order := range q.OrderBy {
+		query = query.Order(order)
+	}
+	query = query.Order(defaultOrder)
+
+	// One extra record tells whether there is a next page
+	records, err := load(query.Offset(q.Skip).Limit(q.Top + 1))
+	if err != nil {
+		return nil, errors.New("Failed to fetch records")
+	}
+
+	if len(records) > q.Top {
+		records = records[:q.Top]
+
+		next := url.Values{}
+		for name, value := range values {
+			next[name] = value
+		}
+		next.Set("$skip", strconv.Itoa(q.Skip+q.Top))
+		next.Set("$top", strconv.Itoa(q.Top))
+		response.NextLink = resoRoot() + "/" + set + "?" + next.Encode()
+	}
+
+	for _, record := range records {
+		response.Value = append(response.Value, resoSelect(record, q.Select))
+	}
+
+	return response, nil
+}
+
+func (c *AuthController) resoEntity(set string, values url.Values, fields []odata.Field, query *gorm.DB,
+	load func(*gorm.DB) ([]map[string]interface{}, error)) (map[string]interface{}, error) {
+	// Only $select applies to a single record
+	selected := url.Values{}
+	if value, ok := values["$select"]; ok {
+		selected["$select"] = value
+	}
+	q, err := odata.Parse(selected, fields, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := load(query.Limit(1))
+	if err != nil {
+		return nil, errors.New("Failed to fetch record")
+	}
+	if len(records) == 0 {
+		return nil, ErrResoNotFound
+	}
+
+	record := resoSelect(records[0], q.Select)
+	record["@odata.context"] = resoRoot() + "/$metadata#" + set + "/$entity"
+	return record, nil
+}
+
+func resoSelect(record map[string]interface{}, fields []string) map[string]interface{} {
+	selected := make(map[string]interface{}, len(fields))
+	for _, name := range fields {
+		selected[name] = record[name]
+	}
+	return selected
+}
+
+func (c *AuthController) resoPropertyRecords(query *gorm.DB) ([]map[string]interface{}, error) {
+	var properties []models.Property
+	err := query.Select("properties.*").
+		Preload("Country").
+		Preload("Division").
+		Preload("District").
+		Preload("Owner").
+		Preload("Media", galleryPreload).
+		Find(&properties).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := []map[string]interface{}{}
+	if len(properties) == 0 {
+		return records, nil
+	}
+
+	propertyIds := make([]uint, len(properties))
+	ownerIds := make([]uint, len(properties))
+	for i, property := range properties {
+		propertyIds[i] = property.ID
+		ownerIds[i] = property.OwnerID
+	}
+
+	var features []models.PropertyFeature
+	if err := c.DB.Where("property_id IN ?", propertyIds).Order("id ASC").Find(&features).Error; err != nil {
+		return nil, err
+	}
+	featureByProperty := map[uint]*models.PropertyFeature{}
+	for i := range features {
+		if _, ok := featureByProperty[features[i].PropertyID]; !ok {
+			featureByProperty[features[i].PropertyID] = &features[i]
+		}
+	}
+
+	var profiles []models.OwnerProfile
+	if err := c.DB.Where("user_id IN ?", ownerIds).Order("id ASC").Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	profileByOwner := map[uint]*models.OwnerProfile{}
+	for i := range profiles {
+		if _, ok := profileByOwner[profiles[i].UserID]; !ok {
+			profileByOwner[profiles[i].UserID] = &profiles[i]
+		}
+	}
+
+	for _, property := range properties {
+		records = append(records, mapper.PropertyToReso(property, featureByProperty[property.ID], profileByOwner[property.OwnerID]))
+	}
+	return records, nil
+}
+
+func (c *AuthController) resoMediaRecords(query *gorm.DB) ([]map[string]interface{}, error) {
+	var media []models.PropertyMedia
+	if err := query.Select("property_media.*").Preload("Renditions").Find(&media).Error; err != nil {
+		return nil, err
+	}
+
+	records := []map[string]interface{}{}
+	for _, item := range media {
+		records = append(records, mapper.PropertyMediaToReso(item))
+	}
+	return records, nil
+}
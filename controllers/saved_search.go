@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/farhapartex/real_estate_be/utils"
+)
+
+// maxSavedSearches is the per-user limit, MAX_SAVED_SEARCHES (default 20)
+func maxSavedSearches() int64 {
+	return envInt64("MAX_SAVED_SEARCHES", 20)
+}
+
+// apiBaseURL is where links in emails that call the API point to, API_BASE_URL
+func apiBaseURL() string {
+	baseURL := os.Getenv("API_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:" + os.Getenv("port")
+	}
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+// appBaseURL is the web app that listing links in emails open, APP_BASE_URL.
+// Without it emails carry no listing links.
+func appBaseURL() string {
+	return strings.TrimSuffix(os.Getenv("APP_BASE_URL"), "/")
+}
+
+func (c *AuthController) SavedSearches(userId uint, page, pageSize int) (*dto.PaginatedResponse, error) {
+	var searches []models.SavedSearch
+	var total int64
+
+	query := c.DB.Model(&models.SavedSearch{}).Where("user_id = ?", userId)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("error counting saved searches")
+	}
+
+	offset := (page - 1) * pageSize
+
+	if err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&searches).Error; err != nil {
+		return nil, errors.New("error retrieving saved searches")
+	}
+
+	responseDTOs := []dto.SavedSearchDTO{}
+	for _, search := range searches {
+		responseDTOs = append(responseDTOs, mapper.SavedSearchToDTO(search))
+	}
+
+	response := mapper.CreatePaginatedResponse(responseDTOs, total, page, pageSize)
+	return &response, nil
+}
+
+// CreateSavedSearch saves a search. Only listings that appear or change from now
+// on are sent, not the ones already matching.
+func (c *AuthController) CreateSavedSearch(userId uint, request dto.SavedSearchRequestDTO) (*dto.SavedSearchDTO, error) {
+	criteria := mapper.SavedSearchCriteriaToModel(request.Criteria)
+	if err := validateSearchCriteria(criteria); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if criteria.Currency, err = c.supportedCurrency(criteria.Currency); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	c.DB.Model(&models.SavedSearch{}).Where("user_id = ?", userId).Count(&count)
+	if count >= maxSavedSearches() {
+		return nil, fmt.Errorf("You can save at most %d searches", maxSavedSearches())
+	}
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, errors.New("Failed to save search")
+	}
+
+	search := models.SavedSearch{
+		UserID:           userId,
+		Name:             strings.TrimSpace(request.Name),
+		Criteria:         criteria,
+		Frequency:        models.AlertFrequency(request.Frequency),
+		AlertsEnabled:    request.AlertsEnabled == nil || *request.AlertsEnabled,
+		UnsubscribeToken: hex.EncodeToString(token),
+		MatchedUntil:     time.Now(),
+	}
+
+	if err := c.DB.Create(&search).Error; err != nil {
+		return nil, errors.New("Failed to save search")
+	}
+
+	response := mapper.SavedSearchToDTO(search)
+	return &response, nil
+}
+
+func (c *AuthController) UpdateSavedSearch(userId uint, searchId uint, request dto.SavedSearchRequestDTO) (*dto.SavedSearchDTO, error) {
+	var search models.SavedSearch
+	if err := c.DB.Where("user_id = ? AND id = ?", userId, searchId).First(&search).Error; err != nil {
+		return nil, errors.New("Saved search not found")
+	}
+
+	criteria := mapper.SavedSearchCriteriaToModel(request.Criteria)
+	if err := validateSearchCriteria(criteria); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if criteria.Currency, err = c.supportedCurrency(criteria.Currency); err != nil {
+		return nil, err
+	}
+
+	search.Name = strings.TrimSpace(request.Name)
+	search.Criteria = criteria
+	search.Frequency = models.AlertFrequency(request.Frequency)
+	if request.AlertsEnabled != nil {
+		// Turning alerts back on starts from now instead of catching up on
+		// everything listed while they were off
+		if *request.AlertsEnabled && !search.AlertsEnabled {
+			search.MatchedUntil = time.Now()
+		}
+		search.AlertsEnabled = *request.AlertsEnabled
+	}
+
+	if err := c.DB.Save(&search).Error; err != nil {
+		return nil, errors.New("Failed to update saved search")
+	}
+
+	response := mapper.SavedSearchToDTO(search)
+	return &response, nil
+}
+
+func (c *AuthController) DeleteSavedSearch(userId uint, searchId uint) error {
+	var search models.SavedSearch
+	if err := c.DB.Where("user_id = ? AND id = ?", userId, searchId).First(&search).Error; err != nil {
+		return errors.New("Saved search not found")
+	}
+
+	if err := c.DB.Where("saved_search_id = ?", search.ID).Delete(&models.SavedSearchMatch{}).Error; err != nil {
+		return errors.New("Failed to delete saved search")
+	}
+
+	if err := c.DB.Delete(&search).Error; err != nil {
+		return errors.New("Failed to delete saved search")
+	}
+
+	return nil
+}
+
+// UnsubscribeSavedSearch turns off the alerts of the search the token belongs
+// to. It backs the one-click link in alert emails, so it needs no login.
+func (c *AuthController) UnsubscribeSavedSearch(token string) (*dto.SavedSearchUnsubscribeDTO, error) {
+	if token == "" {
+		return nil, errors.New("Invalid unsubscribe link")
+	}
+
+	var search models.SavedSearch
+	if err := c.DB.Where("unsubscribe_token = ?", token).First(&search).Error; err != nil {
+		return nil, errors.New("Invalid unsubscribe link")
+	}
+
+	if err := c.DB.Model(&search).Update("alerts_enabled", false).Error; err != nil {
+		return nil, errors.New("Failed to unsubscribe")
+	}
+
+	return &dto.SavedSearchUnsubscribeDTO{
+		Message: fmt.Sprintf("You will no longer receive alerts for \"%s\".", search.Name),
+	}, nil
+}
+
+func validateSearchCriteria(criteria models.SearchCriteria) error {
+	if criteria.MaxPrice > 0 && criteria.MinPrice > criteria.MaxPrice {
+		return errors.New("min_price must not exceed max_price")
+	}
+
+	if criteria.MaxSize > 0 && criteria.MinSize > criteria.MaxSize {
+		return errors.New("min_size must not exceed max_size")
+	}
+
+	if criteria.BBox != "" {
+		if _, err := utils.ParseBoundingBox(criteria.BBox); err != nil {
+			return err
+		}
+	}
+
+	if criteria.Near == "" {
+		if criteria.RadiusKm > 0 {
+			return errors.New("radius_km requires near")
+		}
+		return nil
+	}
+
+	if _, err := utils.ParseLatLng(criteria.Near); err != nil {
+		return err
+	}
+
+	if criteria.RadiusKm < 0 || criteria.RadiusKm > maxSearchRadiusKm {
+		return errors.New("radius_km must be between 0 and 500")
+	}
+
+	return nil
+}
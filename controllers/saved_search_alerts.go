@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/farhapartex/real_estate_be/lib/search"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// savedSearchAlertInterval is how often listing changes are matched, and
+	// so the delay of "instant" alerts
+	savedSearchAlertInterval = time.Minute
+	// savedSearchSettleDelay leaves room for transactions that were still open
+	// when a run started, so their listings are not skipped
+	savedSearchSettleDelay = time.Minute
+	// savedSearchBatchSize is how many searches are matched per transaction
+	savedSearchBatchSize = 100
+	// maxDigestListings is how many listings a digest email shows
+	maxDigestListings = 20
+)
+
+type digestListing struct {
+	Title    string
+	Price    money.Amount
+	Currency string
+	Address  string
+	URL      string
+	// PriceDropped marks a listing sent before that is back for its lower price
+	PriceDropped  bool
+	PreviousPrice money.Amount
+}
+
+// StartSavedSearchAlerts matches new and changed listings against saved searches
+// and sends the due digests in the background until ctx is done. Searches are
+// claimed with SKIP LOCKED, so every API instance can run it.
+func (c *AuthController) StartSavedSearchAlerts(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(savedSearchAlertInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			c.matchSavedSearches(time.Now().Add(-savedSearchSettleDelay))
+			c.sendSavedSearchDigests(time.Now())
+		}
+	}()
+}
+
+// matchSavedSearches records the listings that changed up to until and match a
+// saved search
+func (c *AuthController) matchSavedSearches(until time.Time) {
+	for {
+		matched, err := c.matchSavedSearchBatch(until)
+		if err != nil {
+			log.Printf("Failed to match saved searches: %v", err)
+			return
+		}
+		if matched < savedSearchBatchSize {
+			return
+		}
+	}
+}
+
+func (c *AuthController) matchSavedSearchBatch(until time.Time) (int, error) {
+	var searches []models.SavedSearch
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("alerts_enabled = ? AND matched_until < ?", true, until).
+			Order("id ASC").
+			Limit(savedSearchBatchSize).
+			Find(&searches).Error
+		if err != nil || len(searches) == 0 {
+			return err
+		}
+
+		since := searches[0].MatchedUntil
+		ids := make([]uint, 0, len(searches))
+		for _, saved := range searches {
+			if saved.MatchedUntil.Before(since) {
+				since = saved.MatchedUntil
+			}
+			ids = append(ids, saved.ID)
+		}
+
+		rates, err := c.currentExchangeRates()
+		if err != nil {
+			return err
+		}
+
+		var properties []models.Property
+		err = tx.Where("status IN ? AND updated_at > ? AND updated_at <= ?", models.SearchableStatuses, since, until).
+			Preload("RentalTerms").
+			Find(&properties).Error
+		if err != nil {
+			return err
+		}
+
+		var matches []models.SavedSearchMatch
+		for _, saved := range searches {
+			for _, property := range properties {
+				if !property.UpdatedAt.After(saved.MatchedUntil) || property.OwnerID == saved.UserID {
+					continue
+				}
+				if search.Matches(saved.Criteria, property, rates.convert) {
+					matches = append(matches, models.SavedSearchMatch{SavedSearchID: saved.ID, PropertyID: property.ID})
+				}
+			}
+		}
+
+		if len(matches) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&matches, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.SavedSearch{}).Where("id IN ?", ids).Update("matched_until", until).Error
+	})
+
+	return len(searches), err
+}
+
+// sendSavedSearchDigests sends every search whose frequency allows it a digest of
+// its unsent matches
+func (c *AuthController) sendSavedSearchDigests(now time.Time) {
+	for {
+		err := c.sendNextSavedSearchDigest(now)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to send saved search digest: %v", err)
+			return
+		}
+	}
+}
+
+func (c *AuthController) sendNextSavedSearchDigest(now time.Time) error {
+	var saved models.SavedSearch
+	var matches []models.SavedSearchMatch
+
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("alerts_enabled = ?", true).
+			Where("id IN (?)", tx.Model(&models.SavedSearchMatch{}).Select("saved_search_id").Where("sent_at IS NULL")).
+			Where("frequency = ? OR last_alert_at IS NULL OR (frequency = ? AND last_alert_at <= ?) OR (frequency = ? AND last_alert_at <= ?)",
+				models.AlertInstant,
+				models.AlertDaily, now.Add(-models.AlertDaily.Interval()),
+				models.AlertWeekly, now.Add(-models.AlertWeekly.Interval())).
+			Order("id ASC").
+			First(&saved).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Preload("Property").
+			Where("saved_search_id = ? AND sent_at IS NULL", saved.ID).
+			Order("created_at ASC").
+			Find(&matches).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.SavedSearchMatch{}).Where("saved_search_id = ? AND sent_at IS NULL", saved.ID).Update("sent_at", now).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&saved).Update("last_alert_at", now).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Listings that went off the market since they matched are left out
+	var listings []digestListing
+	var propertyIds []uint
+	for _, match := range matches {
+		if !match.Property.Status.IsSearchable() {
+			continue
+		}
+
+		listing := digestListing{
+			Title:    match.Property.Title,
+			Price:    match.Property.Price,
+			Currency: match.Property.Currency,
+			Address:  match.Property.Address,
+		}
+		if match.PreviousPrice != nil && match.Property.Price < *match.PreviousPrice {
+			listing.PriceDropped = true
+			listing.PreviousPrice = *match.PreviousPrice
+		}
+		if appBaseURL() != "" {
+			listing.URL = fmt.Sprintf("%s/properties/%d", appBaseURL(), match.Property.ID)
+		}
+
+		listings = append(listings, listing)
+		propertyIds = append(propertyIds, match.Property.ID)
+	}
+
+	if len(listings) == 0 {
+		return nil
+	}
+
+	var user models.User
+	if err := c.DB.Where("id = ? AND status = ?", saved.UserID, "active").First(&user).Error; err != nil {
+		return nil
+	}
+
+	c.notify(userRecipient(user), savedSearchDigest(saved, listings, propertyIds))
+	return nil
+}
+
+// rematchPriceDrop puts a listing back into the next digest of every search it
+// was already sent for and still matches at the lower price. Subscribed to
+// OnPriceDrop.
+func (c *AuthController) rematchPriceDrop(drop PriceDrop) {
+	var searches []models.SavedSearch
+	err := c.DB.Where("id IN (?)", c.DB.Model(&models.SavedSearchMatch{}).
+		Select("saved_search_id").
+		Where("property_id = ? AND sent_at IS NOT NULL", drop.Property.ID)).
+		Find(&searches).Error
+	if err != nil {
+		log.Printf("Failed to rematch property %d after a price drop: %v", drop.Property.ID, err)
+		return
+	}
+	if len(searches) == 0 {
+		return
+	}
+
+	rates, err := c.currentExchangeRates()
+	if err != nil {
+		log.Printf("Failed to rematch property %d after a price drop: %v", drop.Property.ID, err)
+		return
+	}
+
+	// The terms are loaded again, not every caller has them on the listing
+	property := drop.Property
+	property.RentalTerms = nil
+	var terms models.PropertyRentalTerms
+	err = c.DB.Where("property_id = ?", property.ID).First(&terms).Error
+	if err == nil {
+		property.RentalTerms = &terms
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Failed to rematch property %d after a price drop: %v", drop.Property.ID, err)
+		return
+	}
+
+	var ids []uint
+	for _, saved := range searches {
+		if search.Matches(saved.Criteria, property, rates.convert) {
+			ids = append(ids, saved.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	err = c.DB.Model(&models.SavedSearchMatch{}).
+		Where("property_id = ? AND saved_search_id IN ? AND sent_at IS NOT NULL", drop.Property.ID, ids).
+		Updates(map[string]interface{}{"sent_at": nil, "previous_price": drop.Change.OldPrice}).Error
+	if err != nil {
+		log.Printf("Failed to rematch property %d after a price drop: %v", drop.Property.ID, err)
+	}
+}
+
+func savedSearchDigest(saved models.SavedSearch, listings []digestListing, propertyIds []uint) notification {
+	drops := 0
+	for _, listing := range listings {
+		if listing.PriceDropped {
+			drops++
+		}
+	}
+
+	var title string
+	switch {
+	case drops == 0 && len(listings) == 1:
+		title = fmt.Sprintf("A new listing matches \"%s\"", saved.Name)
+	case drops == 0:
+		title = fmt.Sprintf("%d new listings match \"%s\"", len(listings), saved.Name)
+	case drops == len(listings) && drops == 1:
+		title = fmt.Sprintf("A listing matching \"%s\" dropped its price", saved.Name)
+	case drops == len(listings):
+		title = fmt.Sprintf("%d listings matching \"%s\" dropped their price", drops, saved.Name)
+	default:
+		title = fmt.Sprintf("%d listings match \"%s\", %d of them at a lower price", len(listings), saved.Name, drops)
+	}
+
+	shown := listings
+	if len(shown) > maxDigestListings {
+		shown = shown[:maxDigestListings]
+	}
+
+	titles := make([]string, 0, 3)
+	for i := 0; i < len(listings) && i < 3; i++ {
+		titles = append(titles, listings[i].Title)
+	}
+	body := strings.Join(titles, ", ")
+	if len(listings) > len(titles) {
+		body += fmt.Sprintf(" and %d more", len(listings)-len(titles))
+	}
+
+	return notification{
+		Type:  models.NotificationSavedSearchMatch,
+		Title: title,
+		Body:  body,
+		Data: map[string]interface{}{
+			"saved_search_id": saved.ID,
+			"property_ids":    propertyIds,
+		},
+		EmailTemplate: "saved_search_digest",
+		EmailData: map[string]interface{}{
+			"Headline":       title,
+			"SearchName":     saved.Name,
+			"Listings":       shown,
+			"More":           len(listings) - len(shown),
+			"UnsubscribeURL": fmt.Sprintf("%s/api/v1/web/saved-searches/unsubscribe?token=%s", apiBaseURL(), saved.UnsubscribeToken),
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm/clause"
+)
+
+// streamTicketExpiry is how long a ticket can be used to open the stream
+const streamTicketExpiry = 30 * time.Second
+
+func hashStreamTicket(ticket string) string {
+	hash := sha256.Sum256([]byte(ticket))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateStreamTicket issues a ticket to open the event stream with
+// ?ticket= instead of the access token, which must stay out of URLs and logs
+func (c *AuthController) CreateStreamTicket(userId uint) (*dto.StreamTicketDTO, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.New("Failed to create stream ticket")
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	ticket := models.StreamTicket{
+		UserID:    userId,
+		TokenHash: hashStreamTicket(token),
+		ExpiresAt: now.Add(streamTicketExpiry),
+	}
+	if err := c.DB.Create(&ticket).Error; err != nil {
+		return nil, errors.New("Failed to create stream ticket")
+	}
+
+	// Tickets are only looked up until they expire
+	c.DB.Where("expires_at < ?", now.Add(-time.Hour)).Delete(&models.StreamTicket{})
+
+	return &dto.StreamTicketDTO{
+		Ticket:    token,
+		ExpiresAt: ticket.ExpiresAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// RedeemStreamTicket uses up a ticket and returns its active user
+func (c *AuthController) RedeemStreamTicket(token string) (*models.User, error) {
+	now := time.Now()
+
+	var ticket models.StreamTicket
+	result := c.DB.Model(&ticket).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashStreamTicket(token), now).
+		Update("used_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New("Invalid or expired stream ticket")
+	}
+
+	var user models.User
+	if err := c.DB.Where("id = ? AND status = ?", ticket.UserID, "active").First(&user).Error; err != nil {
+		return nil, errors.New("Invalid or expired stream ticket")
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"mime/multipart"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/imaging"
+	"github.com/farhapartex/real_estate_be/mapper"
+	"github.com/farhapartex/real_estate_be/models"
+	"gorm.io/gorm"
+)
+
+const maxWatermarkLogoSize = 2 * 1024 * 1024
+
+// The logo is needed for every rendition, keep the decoded one around
+var watermarkLogoCache struct {
+	sync.Mutex
+	key  string
+	logo image.Image
+}
+
+// watermarkSetting loads the site-wide setting, falling back to the defaults
+// before an admin has saved one
+func (c *AuthController) watermarkSetting() (models.WatermarkSetting, error) {
+	setting := models.WatermarkSetting{
+		ID:       models.WatermarkSettingID,
+		Source:   models.WatermarkLogo,
+		Position: imaging.PositionBottomRight,
+		Opacity:  0.5,
+	}
+
+	err := c.DB.Where("id = ?", models.WatermarkSettingID).FirstOrInit(&setting).Error
+	return setting, err
+}
+
+func (c *AuthController) GetWatermarkSetting() (*dto.WatermarkSettingDTO, error) {
+	setting, err := c.watermarkSetting()
+	if err != nil {
+		return nil, errors.New("Failed to fetch watermark settings")
+	}
+
+	response := mapper.WatermarkSettingToDTO(setting)
+	return &response, nil
+}
+
+// UpdateWatermarkSetting changes the site-wide watermark. Existing photos keep
+// their renditions until RerenderWatermarks is run.
+func (c *AuthController) UpdateWatermarkSetting(admin models.User, request dto.WatermarkSettingRequestDTO) (*dto.WatermarkSettingDTO, error) {
+	setting, err := c.watermarkSetting()
+	if err != nil {
+		return nil, errors.New("Failed to fetch watermark settings")
+	}
+
+	if request.Enabled && models.WatermarkSource(request.Source) == models.WatermarkLogo && setting.LogoKey == nil {
+		return nil, errors.New("Upload a watermark logo before enabling the logo watermark")
+	}
+
+	setting.Enabled = request.Enabled
+	setting.Source = models.WatermarkSource(request.Source)
+	setting.Position = request.Position
+	setting.Opacity = request.Opacity
+	setting.UpdatedByID = &admin.ID
+	setting.UpdatedAt = time.Now()
+
+	if err := c.DB.Save(&setting).Error; err != nil {
+		return nil, errors.New("Failed to update watermark settings")
+	}
+
+	response := mapper.WatermarkSettingToDTO(setting)
+	return &response, nil
+}
+
+// UploadWatermarkLogo replaces the brand logo used for watermarks. Only PNG is
+// accepted since the logo needs transparency.
+func (c *AuthController) UploadWatermarkLogo(admin models.User, file *multipart.FileHeader) (*dto.WatermarkSettingDTO, error) {
+	if file.Size > maxWatermarkLogoSize {
+		return nil, fmt.Errorf("Logo must not be larger than %d MB", maxWatermarkLogoSize/1024/1024)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, errors.New("Failed to read logo")
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxWatermarkLogoSize+1))
+	if err != nil || len(data) > maxWatermarkLogoSize {
+		return nil, errors.New("Failed to read logo")
+	}
+
+	if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return nil, errors.New("Logo must be a PNG image")
+	}
+
+	setting, err := c.watermarkSetting()
+	if err != nil {
+		return nil, errors.New("Failed to fetch watermark settings")
+	}
+
+	key := fmt.Sprintf("branding/watermark-logo-%d.png", time.Now().Unix())
+	if err := c.Storage.Put(context.Background(), key, "image/png", bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	previous := setting.LogoKey
+	setting.LogoKey = &key
+	setting.UpdatedByID = &admin.ID
+	setting.UpdatedAt = time.Now()
+
+	if err := c.DB.Save(&setting).Error; err != nil {
+		return nil, errors.New("Failed to update watermark settings")
+	}
+
+	if previous != nil {
+		if err := c.Storage.Delete(context.Background(), *previous); err != nil {
+			log.Printf("Failed to delete old watermark logo %s: %v", *previous, err)
+		}
+	}
+
+	response := mapper.WatermarkSettingToDTO(setting)
+	return &response, nil
+}
+
+// RerenderWatermarks queues every gallery photo for processing so the renditions
+// pick up the current watermark settings
+func (c *AuthController) RerenderWatermarks() (*dto.WatermarkRerenderResponseDTO, error) {
+	queued, err := c.queueMediaProcessing(c.DB.Model(&models.PropertyMedia{}))
+	if err != nil {
+		return nil, errors.New("Failed to queue photos")
+	}
+
+	return &dto.WatermarkRerenderResponseDTO{Queued: queued}, nil
+}
+
+// UpdateOwnerWatermark stores an agency's watermark preference and re-renders
+// the agency's photos with it
+func (c *AuthController) UpdateOwnerWatermark(userId uint, request dto.OwnerWatermarkRequestDTO) (*dto.WatermarkRerenderResponseDTO, error) {
+	var profile models.OwnerProfile
+	if err := c.DB.Where("user_id = ?", userId).First(&profile).Error; err != nil {
+		return nil, errors.New("Owner profile not found")
+	}
+
+	if err := c.DB.Model(&profile).Update("watermark_enabled", request.Enabled).Error; err != nil {
+		return nil, errors.New("Failed to update watermark settings")
+	}
+
+	queued, err := c.queueMediaProcessing(c.DB.Model(&models.PropertyMedia{}).
+		Where("property_id IN (?) OR project_id IN (?)",
+			c.DB.Model(&models.Property{}).Select("id").Where("owner_id = ?", userId),
+			c.DB.Model(&models.Project{}).Select("id").Where("owner_id = ?", userId)))
+	if err != nil {
+		return nil, errors.New("Failed to queue photos")
+	}
+
+	return &dto.WatermarkRerenderResponseDTO{Queued: queued}, nil
+}
+
+// queueMediaProcessing sends the gallery photos matched by query back to the
+// processing workers
+func (c *AuthController) queueMediaProcessing(query *gorm.DB) (int64, error) {
+	result := query.Where("status = ?", models.MediaUploaded).
+		Update("processing_status", models.ProcessingPending)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	c.NotifyMediaProcessing()
+	return result.RowsAffected, nil
+}
+
+// mediaWatermark works out the watermark for a photo. The agency preference wins
+// over the site setting; nil means no watermark.
+func (c *AuthController) mediaWatermark(ctx context.Context, media models.PropertyMedia) (*imaging.Watermark, error) {
+	setting, err := c.watermarkSetting()
+	if err != nil {
+		return nil, err
+	}
+
+	owner := c.DB.Unscoped().Model(&models.Property{}).Select("owner_id").Where("id = ?", media.PropertyID)
+	if media.ProjectID != nil {
+		owner = c.DB.Unscoped().Model(&models.Project{}).Select("owner_id").Where("id = ?", *media.ProjectID)
+	}
+
+	var profile models.OwnerProfile
+	err = c.DB.Where("user_id = (?)", owner).First(&profile).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	enabled := setting.Enabled
+	if profile.WatermarkEnabled != nil {
+		enabled = *profile.WatermarkEnabled
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	watermark := imaging.Watermark{Position: setting.Position, Opacity: setting.Opacity}
+
+	if setting.Source == models.WatermarkCompanyName && profile.CompanyName != nil && strings.TrimSpace(*profile.CompanyName) != "" {
+		watermark.Text = strings.TrimSpace(*profile.CompanyName)
+		return &watermark, nil
+	}
+
+	// Owners without a company name get the brand logo
+	if setting.LogoKey == nil {
+		return nil, nil
+	}
+
+	logo, err := c.watermarkLogo(ctx, *setting.LogoKey)
+	if err != nil {
+		return nil, err
+	}
+	watermark.Logo = logo
+
+	return &watermark, nil
+}
+
+func (c *AuthController) watermarkLogo(ctx context.Context, key string) (image.Image, error) {
+	watermarkLogoCache.Lock()
+	defer watermarkLogoCache.Unlock()
+
+	if watermarkLogoCache.key == key {
+		return watermarkLogoCache.logo, nil
+	}
+
+	body, err := c.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	logo, err := png.Decode(io.LimitReader(body, maxWatermarkLogoSize))
+	if err != nil {
+		return nil, errors.New("watermark logo is not a valid PNG image")
+	}
+
+	watermarkLogoCache.key = key
+	watermarkLogoCache.logo = logo
+
+	return logo, nil
+}
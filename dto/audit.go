@@ -0,0 +1,18 @@
+package dto
+
+import "encoding/json"
+
+type AuditLogDTO struct {
+	ID         uint   `json:"id"`
+	EntityType string `json:"entity_type"`
+	EntityID   uint   `json:"entity_id"`
+	Action     string `json:"action"`
+	ActorID    *uint  `json:"actor_id"`
+	ActorName  string `json:"actor_name"`
+	RequestID  string `json:"request_id"`
+	// Changes maps each changed field to its old and new value
+	Changes        json.RawMessage `json:"changes"`
+	Snapshot       json.RawMessage `json:"snapshot"`
+	RestoredFromID *uint           `json:"restored_from_id"`
+	CreatedAt      string          `json:"created_at"`
+}
@@ -43,6 +43,8 @@ type UserMeDTO struct {
 	LastLoginAt   *time.Time `json:"last_login_at,omitempty"`
 	EmailVerified bool       `json:"email_verified"`
 	Role          string     `json:"role"`
+
+	UnreadNotifications int64 `json:"unread_notifications"`
 }
 
 type OwnerSignupRequestDTO struct {
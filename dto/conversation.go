@@ -0,0 +1,52 @@
+package dto
+
+type ConversationStartRequestDTO struct {
+	PropertyID uint   `json:"property_id" binding:"required"`
+	Message    string `json:"message" binding:"required,max=4000"`
+}
+
+type MessageRequestDTO struct {
+	Body string `json:"body" binding:"required,max=4000"`
+}
+
+type ContactSharingRequestDTO struct {
+	Share bool `json:"share"`
+}
+
+type ConversationPropertyDTO struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+type ConversationParticipantDTO struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+}
+
+type MessageDTO struct {
+	ID       uint   `json:"id"`
+	SenderID uint   `json:"sender_id"`
+	Body     string `json:"body"`
+	// Masked is true when contact details were hidden from the body
+	Masked    bool   `json:"masked"`
+	Read      bool   `json:"read"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ConversationDTO struct {
+	ID           uint                         `json:"id"`
+	Property     ConversationPropertyDTO      `json:"property"`
+	Participants []ConversationParticipantDTO `json:"participants"`
+	LastMessage  *MessageDTO                  `json:"last_message"`
+	UnreadCount  int                          `json:"unread_count"`
+	// ShareContact is the current user's consent, ContactShared whether both agreed
+	ShareContact  bool    `json:"share_contact"`
+	ContactShared bool    `json:"contact_shared"`
+	LastMessageAt *string `json:"last_message_at"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type UnreadCountDTO struct {
+	Unread int64 `json:"unread"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+)
+
+// ExchangeRateRequestDTO sets what one unit of Currency is worth in the base
+// currency, from EffectiveAt on or right away
+type ExchangeRateRequestDTO struct {
+	Currency    string     `json:"currency" binding:"required,len=3"`
+	Rate        money.Rate `json:"rate"`
+	EffectiveAt *time.Time `json:"effective_at"`
+}
+
+type ExchangeRateDTO struct {
+	ID            uint       `json:"id"`
+	BaseCurrency  string     `json:"base_currency"`
+	Currency      string     `json:"currency"`
+	Rate          money.Rate `json:"rate"`
+	EffectiveAt   string     `json:"effective_at"`
+	CreatedByID   *uint      `json:"created_by_id"`
+	CreatedByName string     `json:"created_by_name"`
+	CreatedAt     string     `json:"created_at"`
+}
+
+// ExchangeRateListDTO are the rates in effect, in units of BaseCurrency
+type ExchangeRateListDTO struct {
+	BaseCurrency string            `json:"base_currency"`
+	Rates        []ExchangeRateDTO `json:"rates"`
+}
+
+// DisplayPriceDTO is a price converted to the currency asked for with currency=
+type DisplayPriceDTO struct {
+	Amount   money.Amount `json:"amount"`
+	Currency string       `json:"currency"`
+	// Rate is what one unit of the listing currency was converted at
+	Rate money.Rate `json:"rate"`
+	// ExchangeRateIDs are the exchange rates the conversion used, none when
+	// both currencies are the base currency
+	ExchangeRateIDs []uint `json:"exchange_rate_ids"`
+	// RatesAsOf is when the older of those rates took effect
+	RatesAsOf *string `json:"rates_as_of"`
+}
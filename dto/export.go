@@ -0,0 +1,8 @@
+package dto
+
+// ExportRequestDTO selects the file format and columns of an export. Columns
+// is a comma separated list; all columns are exported when it is empty.
+type ExportRequestDTO struct {
+	Format  string `form:"format" binding:"omitempty,oneof=csv xlsx"`
+	Columns string `form:"columns"`
+}
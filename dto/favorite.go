@@ -0,0 +1,6 @@
+package dto
+
+type FavoriteStatusDTO struct {
+	PropertyID  uint `json:"property_id"`
+	IsFavorited bool `json:"is_favorited"`
+}
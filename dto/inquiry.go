@@ -0,0 +1,47 @@
+package dto
+
+type InquiryRequestDTO struct {
+	Name    string `json:"name" binding:"max=100"`
+	Email   string `json:"email" binding:"omitempty,email,max=255"`
+	Phone   string `json:"phone" binding:"max=20"`
+	Message string `json:"message" binding:"required,min=10,max=2000"`
+
+	// Website is a honeypot field. It is hidden in the form, so only bots fill it.
+	Website string `json:"website"`
+}
+
+type InquiryFilterDTO struct {
+	Status     string `form:"status" binding:"omitempty,oneof=new replied closed"`
+	PropertyID uint   `form:"property_id"`
+	Search     string `form:"q"`
+}
+
+type InquiryPropertyDTO struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+type InquiryReplyDTO struct {
+	ID        uint   `json:"id"`
+	Message   string `json:"message"`
+	CreatedAt string `json:"created_at"`
+}
+
+type InquiryDTO struct {
+	ID        uint               `json:"id"`
+	Property  InquiryPropertyDTO `json:"property"`
+	SenderID  *uint              `json:"sender_id"`
+	Name      string             `json:"name"`
+	Email     string             `json:"email"`
+	Phone     string             `json:"phone"`
+	Message   string             `json:"message"`
+	Status    string             `json:"status"`
+	RepliedAt *string            `json:"replied_at"`
+	ClosedAt  *string            `json:"closed_at"`
+	CreatedAt string             `json:"created_at"`
+	Replies   []InquiryReplyDTO  `json:"replies"`
+}
+
+type InquiryReplyRequestDTO struct {
+	Message string `json:"message" binding:"required,max=5000"`
+}
@@ -54,16 +54,25 @@ type DivisionMinimal2DTO struct {
 	Name string `json:"name"`
 }
 
+// DistrictRequestDTO creates a district. Latitude, Longitude and RadiusKm are
+// the area listing coordinates are checked against; the check is optional and
+// stays off for a district until all three are set.
 type DistrictRequestDTO struct {
-	Name       string `json:"name"`
-	DivisionId uint32 `json:"division_id"`
+	Name       string   `json:"name"`
+	DivisionId uint32   `json:"division_id"`
+	Latitude   *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude  *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	RadiusKm   *float64 `json:"radius_km" binding:"omitempty,gt=0"`
 }
 
 type DistrictResponseDTO struct {
-	ID       uint32             `json:"id"`
-	Name     string             `json:"name"`
-	Division DivisionMinimalDTO `json:"division"`
-	Status   bool               `json:"status"`
+	ID        uint32             `json:"id"`
+	Name      string             `json:"name"`
+	Division  DivisionMinimalDTO `json:"division"`
+	Status    bool               `json:"status"`
+	Latitude  *float64           `json:"latitude"`
+	Longitude *float64           `json:"longitude"`
+	RadiusKm  *float64           `json:"radius_km"`
 }
 
 type DistrictMinimalResponseDTO struct {
@@ -71,10 +80,15 @@ type DistrictMinimalResponseDTO struct {
 	Name string `json:"name"`
 }
 
+// DistrictUpdateRequestDTO updates a district; an area field that is left out
+// keeps its current value
 type DistrictUpdateRequestDTO struct {
-	Name       string `json:"name"`
-	DivisionId uint32 `json:"division_id"`
-	Status     bool   `json:"status"`
+	Name       string   `json:"name"`
+	DivisionId uint32   `json:"division_id"`
+	Status     bool     `json:"status"`
+	Latitude   *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude  *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	RadiusKm   *float64 `json:"radius_km" binding:"omitempty,gt=0"`
 }
 
 // public response DTO
@@ -0,0 +1,36 @@
+package dto
+
+import "encoding/json"
+
+type NotificationDTO struct {
+	ID    uint   `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Data holds the ids a client needs to link to the subject, e.g. property_id
+	Data      json.RawMessage `json:"data"`
+	Read      bool            `json:"read"`
+	ReadAt    *string         `json:"read_at"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type NotificationFilterDTO struct {
+	Unread bool `form:"unread"`
+}
+
+type NotificationPreferenceDTO struct {
+	Type  string `json:"type" binding:"required"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+	SMS   bool   `json:"sms"`
+}
+
+type NotificationPreferencesRequestDTO struct {
+	Preferences []NotificationPreferenceDTO `json:"preferences" binding:"required,dive"`
+}
+
+// StreamTicketDTO opens the event stream once, as /api/v1/stream?ticket=
+type StreamTicketDTO struct {
+	Ticket    string `json:"ticket"`
+	ExpiresAt string `json:"expires_at"`
+}
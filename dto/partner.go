@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"encoding/xml"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+)
+
+type PartnerRequestDTO struct {
+	Name      string  `json:"name" binding:"required,max=150"`
+	Purpose   *string `json:"purpose" binding:"omitempty,oneof=sale rent"`
+	CountryID *uint32 `json:"country_id"`
+}
+
+// PartnerUpdateDTO changes only the fields that are sent. An empty purpose or a
+// zero country_id removes that restriction.
+type PartnerUpdateDTO struct {
+	Name      *string `json:"name" binding:"omitempty,min=1,max=150"`
+	Active    *bool   `json:"active"`
+	Purpose   *string `json:"purpose" binding:"omitempty,oneof=sale rent"`
+	CountryID *uint32 `json:"country_id"`
+}
+
+type PartnerDTO struct {
+	ID              uint    `json:"id"`
+	Name            string  `json:"name"`
+	Active          bool    `json:"active"`
+	Purpose         *string `json:"purpose"`
+	CountryID       *uint32 `json:"country_id"`
+	FeedGeneratedAt *string `json:"feed_generated_at"`
+	LastFetchedAt   *string `json:"last_fetched_at"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+// PartnerTokenDTO is returned when a token is issued; it cannot be looked up later
+type PartnerTokenDTO struct {
+	PartnerDTO
+	Token string `json:"token"`
+}
+
+// FeedListingDTO is one listing of a partner feed. The XML feed follows the
+// Trovit ad format; the JSON feed uses the same fields with the API's names.
+type FeedListingDTO struct {
+	XMLName      xml.Name         `xml:"ad" json:"-"`
+	ID           uint             `xml:"id" json:"id"`
+	URL          string           `xml:"url,omitempty" json:"url,omitempty"`
+	Title        string           `xml:"title" json:"title"`
+	Type         string           `xml:"type" json:"type"`
+	Content      string           `xml:"content" json:"description"`
+	Price        FeedPriceDTO     `xml:"price" json:"price"`
+	PropertyType string           `xml:"property_type" json:"property_type"`
+	FloorArea    float64          `xml:"floor_area" json:"size"`
+	Rooms        int              `xml:"rooms" json:"bedrooms"`
+	Bathrooms    int              `xml:"bathrooms" json:"bathrooms"`
+	Parking      int              `xml:"parking,omitempty" json:"parking,omitempty"`
+	Year         int              `xml:"year,omitempty" json:"built_year,omitempty"`
+	Address      string           `xml:"address" json:"address"`
+	City         string           `xml:"city" json:"district"`
+	Region       string           `xml:"region" json:"division"`
+	Country      string           `xml:"country" json:"country"`
+	Latitude     *float64         `xml:"latitude,omitempty" json:"latitude"`
+	Longitude    *float64         `xml:"longitude,omitempty" json:"longitude"`
+	Features     []string         `xml:"features>feature" json:"features"`
+	Pictures     []FeedPictureDTO `xml:"pictures>picture" json:"pictures"`
+	// Date and Time are the publication date in the dd/mm/yyyy and hh:mm forms of the XML format
+	Date      string `xml:"date" json:"-"`
+	Time      string `xml:"time" json:"-"`
+	CreatedAt string `xml:"-" json:"created_at"`
+	UpdatedAt string `xml:"-" json:"updated_at"`
+}
+
+// FeedPriceDTO is the price with its currency; Period is "monthly" for rentals
+type FeedPriceDTO struct {
+	Amount   money.Amount `xml:",chardata" json:"amount"`
+	Currency string       `xml:"currency,attr" json:"currency"`
+	Period   string       `xml:"period,attr,omitempty" json:"period,omitempty"`
+}
+
+type FeedPictureDTO struct {
+	URL   string `xml:"picture_url" json:"url"`
+	Title string `xml:"picture_title,omitempty" json:"title,omitempty"`
+}
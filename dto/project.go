@@ -0,0 +1,113 @@
+package dto
+
+import "github.com/farhapartex/real_estate_be/lib/money"
+
+type ProjectRequestDTO struct {
+	Name           string   `json:"name" binding:"required,max=255"`
+	Description    string   `json:"description" binding:"required"`
+	CountryID      uint32   `json:"country_id" binding:"required"`
+	DivisionID     uint32   `json:"division_id" binding:"required"`
+	DistrictID     uint32   `json:"district_id" binding:"required"`
+	Address        string   `json:"address" binding:"required,max=255"`
+	Latitude       *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude      *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	CompletionDate *string  `json:"completion_date" binding:"omitempty,datetime=2006-01-02"`
+	TotalFloors    int      `json:"total_floors" binding:"gte=0"`
+	Features       []string `json:"features"`
+
+	Amenities        AmenitiesDTO        `json:"amenities"`
+	SecurityFeature  SecurityFeatureDTO  `json:"securityFeature"`
+	CommunityFeature CommunityFeatureDTO `json:"communityFeature"`
+	UtilsFeature     UtilsFeatureDTO     `json:"utilsFeature"`
+	EnergyFeature    EnergyFeatureDTO    `json:"energyFeature"`
+}
+
+// ProjectUnitRequestDTO is a unit of a project. Location comes from the project.
+type ProjectUnitRequestDTO struct {
+	Title        string       `json:"title" binding:"required"`
+	Purpose      string       `json:"purpose" binding:"required,oneof=sale rent"`
+	Price        money.Amount `json:"price" binding:"required,gt=0"`
+	Currency     string       `json:"currency" binding:"omitempty,len=3"`
+	PropertyType string       `json:"property_type" binding:"required"`
+	Bedrooms     int          `json:"bedrooms" binding:"required,gte=0"`
+	Bathrooms    int          `json:"bathrooms" binding:"required,gte=0"`
+	Size         float64      `json:"size" binding:"required,gt=0"`
+	Floor        *int         `json:"floor" binding:"omitempty,gte=0"`
+	BuiltYear    int          `json:"built_year" binding:"omitempty,gt=0"`
+	// Description is the project description when empty
+	Description string                 `json:"description"`
+	ExternalRef *string                `json:"external_ref" binding:"omitempty,max=100"`
+	RentalTerms *RentalTermsRequestDTO `json:"rental_terms"`
+}
+
+type ProjectDTO struct {
+	ID             uint                       `json:"id"`
+	OwnerID        uint                       `json:"owner_id"`
+	Name           string                     `json:"name"`
+	Description    string                     `json:"description"`
+	Country        CountryMinimalDTO          `json:"country"`
+	Division       DivisionMinimal2DTO        `json:"division"`
+	District       DistrictMinimalResponseDTO `json:"district"`
+	Address        string                     `json:"address"`
+	Latitude       *float64                   `json:"latitude"`
+	Longitude      *float64                   `json:"longitude"`
+	CompletionDate *string                    `json:"completion_date"`
+	TotalFloors    int                        `json:"total_floors"`
+	Features       []string                   `json:"features"`
+
+	Amenities        AmenitiesDTO        `json:"amenities"`
+	SecurityFeature  SecurityFeatureDTO  `json:"securityFeature"`
+	CommunityFeature CommunityFeatureDTO `json:"communityFeature"`
+	UtilsFeature     UtilsFeatureDTO     `json:"utilsFeature"`
+	EnergyFeature    EnergyFeatureDTO    `json:"energyFeature"`
+
+	CoverURL *string            `json:"cover_url"`
+	Gallery  []PropertyMediaDTO `json:"gallery"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ProjectDetailsDTO is a project page with what its units offer
+type ProjectDetailsDTO struct {
+	ProjectDTO
+	ProjectUnitsDTO
+}
+
+type ProjectListDTO struct {
+	ID             uint                       `json:"id"`
+	Name           string                     `json:"name"`
+	Country        CountryMinimalDTO          `json:"country"`
+	Division       DivisionMinimal2DTO        `json:"division"`
+	District       DistrictMinimalResponseDTO `json:"district"`
+	Address        string                     `json:"address"`
+	CompletionDate *string                    `json:"completion_date"`
+	CoverURL       *string                    `json:"cover_url"`
+	CreatedAt      string                     `json:"created_at"`
+	ProjectUnitsDTO
+}
+
+// ProjectUnitsDTO counts the live units of a project. Available units are the
+// active ones, summed up per unit type with their price range.
+type ProjectUnitsDTO struct {
+	TotalUnits     int64                `json:"total_units"`
+	AvailableUnits int64                `json:"available_units"`
+	UnitTypes      []ProjectUnitTypeDTO `json:"unit_types"`
+}
+
+type ProjectUnitTypeDTO struct {
+	Purpose        string       `json:"purpose"`
+	Bedrooms       int          `json:"bedrooms"`
+	Currency       string       `json:"currency"`
+	AvailableUnits int64        `json:"available_units"`
+	MinPrice       money.Amount `json:"min_price"`
+	MaxPrice       money.Amount `json:"max_price"`
+	MinSize        float64      `json:"min_size"`
+	MaxSize        float64      `json:"max_size"`
+}
+
+type ProjectFilterDTO struct {
+	CountryID  uint32 `form:"country_id"`
+	DivisionID uint32 `form:"division_id"`
+	DistrictID uint32 `form:"district_id"`
+}
@@ -1,57 +1,159 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+)
 
 type PropertyRequestDTO struct {
-	Title        string  `json:"title" binding:"required"`
-	Purpose      string  `json:"purpose" binding:"required,oneof=sale rent"`
-	Price        float64 `json:"price" binding:"required,gt=0"`
-	PropertyType string  `json:"property_type" binding:"required"`
-	Bedrooms     int     `json:"bedrooms" binding:"required,gte=0"`
-	Bathrooms    int     `json:"bathrooms" binding:"required,gte=0"`
-	Size         float64 `json:"size" binding:"required,gt=0"`
-	BuiltYear    int     `json:"built_year" binding:"omitempty,gt=0"`
-	CountryID    uint32  `json:"country_id" binding:"required"`
-	DivisionID   uint32  `json:"division_id" binding:"required"`
-	DistrictID   uint32  `json:"district_id" binding:"required"`
-	Address      string  `json:"address" binding:"required"`
-	Description  string  `json:"description" binding:"required"`
+	Title        string       `json:"title" binding:"required"`
+	Purpose      string       `json:"purpose" binding:"required,oneof=sale rent"`
+	Price        money.Amount `json:"price" binding:"required,gt=0"`
+	Currency     string       `json:"currency" binding:"omitempty,len=3"` // ISO 4217, the default currency when empty
+	PropertyType string       `json:"property_type" binding:"required"`
+	Bedrooms     int          `json:"bedrooms" binding:"required,gte=0"`
+	Bathrooms    int          `json:"bathrooms" binding:"required,gte=0"`
+	Size         float64      `json:"size" binding:"required,gt=0"`
+	BuiltYear    int          `json:"built_year" binding:"omitempty,gt=0"`
+	CountryID    uint32       `json:"country_id" binding:"required"`
+	DivisionID   uint32       `json:"division_id" binding:"required"`
+	DistrictID   uint32       `json:"district_id" binding:"required"`
+	Address      string       `json:"address" binding:"required"`
+	Latitude     *float64     `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude    *float64     `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Description  string       `json:"description" binding:"required"`
+	// ExternalRef is the owner's own reference for the listing, e.g. from their
+	// CRM; imports use it to update a listing instead of creating it again
+	ExternalRef *string `json:"external_ref" binding:"omitempty,max=100"`
+	// RentalTerms only apply to rent listings; leaving them out of an update
+	// keeps the current ones
+	RentalTerms *RentalTermsRequestDTO `json:"rental_terms"`
+	Floor       *int                   `json:"floor" binding:"omitempty,gte=0"`
+
+	// ProjectID makes the listing a unit of the project; set by the server
+	ProjectID *uint `json:"-"`
+}
+
+// RentalTermsRequestDTO are the terms of a rent listing, amounts in the listing currency
+type RentalTermsRequestDTO struct {
+	RentPeriod       string       `json:"rent_period" binding:"required,oneof=monthly yearly"`
+	SecurityDeposit  money.Amount `json:"security_deposit" binding:"gte=0"`
+	AdvanceMonths    int          `json:"advance_months" binding:"gte=0,lte=24"`
+	ServiceCharge    money.Amount `json:"service_charge" binding:"gte=0"`
+	MinLeaseMonths   int          `json:"min_lease_months" binding:"gte=0,lte=120"`
+	AvailableFrom    *string      `json:"available_from" binding:"omitempty,datetime=2006-01-02"`
+	TenantPreference string       `json:"tenant_preference" binding:"omitempty,oneof=family bachelor any"`
+}
+
+type RentalTermsDTO struct {
+	RentPeriod       string       `json:"rent_period"`
+	SecurityDeposit  money.Amount `json:"security_deposit"`
+	AdvanceMonths    int          `json:"advance_months"`
+	ServiceCharge    money.Amount `json:"service_charge"`
+	MinLeaseMonths   int          `json:"min_lease_months"`
+	AvailableFrom    *string      `json:"available_from"`
+	TenantPreference string       `json:"tenant_preference"`
 }
 
 type PropertyResponseDTO struct {
-	ID           uint32  `json:"id"`
-	Title        string  `json:"title"`
-	Purpose      string  `json:"purpose"`
-	Price        float64 `json:"price"`
-	Status       string  `json:"status"`
-	PropertyType string  `json:"property_type"`
-	BedRooms     int     `json:"bedrooms"`
-	BathRooms    int     `json:"bathrooms"`
-	Size         float64 `json:"size"`
-	BuiltYear    int     `json:"built_year"`
-	CountryID    uint32  `json:"country_id"`
-	DivisionID   uint32  `json:"division_id"`
-	DistrictID   uint32  `json:"district_id"`
-	Address      string  `json:"address"`
-	Description  string  `json:"description"`
-	CreatedAt    string  `json:"created_at"`
-	UpdatedAt    string  `json:"updated_at"`
+	ID           uint32           `json:"id"`
+	Title        string           `json:"title"`
+	Purpose      string           `json:"purpose"`
+	Price        money.Amount     `json:"price"`
+	Currency     string           `json:"currency"`
+	DisplayPrice *DisplayPriceDTO `json:"display_price,omitempty"`
+	Status       string           `json:"status"`
+	PropertyType string           `json:"property_type"`
+	BedRooms     int              `json:"bedrooms"`
+	BathRooms    int              `json:"bathrooms"`
+	Size         float64          `json:"size"`
+	BuiltYear    int              `json:"built_year"`
+	CountryID    uint32           `json:"country_id"`
+	DivisionID   uint32           `json:"division_id"`
+	DistrictID   uint32           `json:"district_id"`
+	Address      string           `json:"address"`
+	Latitude     *float64         `json:"latitude"`
+	Longitude    *float64         `json:"longitude"`
+	Description  string           `json:"description"`
+	CreatedAt    string           `json:"created_at"`
+	UpdatedAt    string           `json:"updated_at"`
+	ExternalRef  *string          `json:"external_ref"`
+	Floor        *int             `json:"floor"`
+
+	// Project is set on the units of a project, with the shared amenities and photos
+	Project *ProjectDTO `json:"project"`
+
+	SubmittedAt     *string `json:"submitted_at"`
+	ApprovedAt      *string `json:"approved_at"`
+	RejectedAt      *string `json:"rejected_at"`
+	RejectionReason *string `json:"rejection_reason"`
+
+	CoverURL *string            `json:"cover_url"`
+	Gallery  []PropertyMediaDTO `json:"gallery"`
+
+	IsFavorited bool `json:"is_favorited"`
+
+	PriceHistory     []PriceChangeDTO `json:"price_history"`
+	ReducedByPercent *float64         `json:"reduced_by_percent"`
+
+	RentalTerms *RentalTermsDTO `json:"rental_terms"`
+}
+
+type PriceChangeDTO struct {
+	OldPrice      money.Amount `json:"old_price"`
+	NewPrice      money.Amount `json:"new_price"`
+	Currency      string       `json:"currency"`
+	ChangePercent float64      `json:"change_percent"`
+	ChangedAt     string       `json:"changed_at"`
 }
 
 type PropertyListDTO struct {
 	ID           uint32                     `json:"id"`
 	Title        string                     `json:"title"`
 	Purpose      string                     `json:"purpose"`
-	Price        float64                    `json:"price"`
+	Price        money.Amount               `json:"price"`
+	Currency     string                     `json:"currency"`
+	DisplayPrice *DisplayPriceDTO           `json:"display_price,omitempty"`
 	PropertyType string                     `json:"property_type"`
 	Country      CountryMinimalDTO          `json:"country"`
 	Division     DivisionMinimal2DTO        `json:"division"`
 	District     DistrictMinimalResponseDTO `json:"district"`
 	Status       string                     `json:"status"`
 	Address      string                     `json:"address"`
+	Latitude     *float64                   `json:"latitude"`
+	Longitude    *float64                   `json:"longitude"`
+	DistanceKm   *float64                   `json:"distance_km,omitempty"`
+	ProjectID    *uint                      `json:"project_id"`
+	Floor        *int                       `json:"floor"`
+	CoverURL     *string                    `json:"cover_url"`
 	Views        int                        `json:"views"`
 	Inquiries    int                        `json:"inquiries"`
+	Favorites    int                        `json:"favorites"`
+	IsFavorited  bool                       `json:"is_favorited"`
 	CreatedAt    string                     `json:"created_at"`
+	// ReducedByPercent is set when the price is below the original price
+	ReducedByPercent *float64 `json:"reduced_by_percent"`
+}
+
+// PropertyModerationDTO is a row of the admin review queue
+type PropertyModerationDTO struct {
+	PropertyListDTO
+	OwnerID     uint    `json:"owner_id"`
+	OwnerName   string  `json:"owner_name"`
+	OwnerEmail  string  `json:"owner_email"`
+	SubmittedAt *string `json:"submitted_at"`
+}
+
+type DeletedPropertyDTO struct {
+	PropertyModerationDTO
+	DeletedAt string `json:"deleted_at"`
+	// PurgeAt is when the listing is removed for good and can no longer be restored
+	PurgeAt string `json:"purge_at"`
+}
+
+type PropertyRejectRequestDTO struct {
+	Reason string `json:"reason" binding:"required,min=3,max=1000"`
 }
 
 type PropertyListResponseDTO struct {
@@ -62,21 +164,39 @@ type PropertyListResponseDTO struct {
 }
 
 type PropertyFilterDTO struct {
-	OwerID       uint    `form:"owner_id"`
-	Purpose      string  `form:"purpose"`
-	MinPrice     float64 `form:"min_price"`
-	MaxPrice     float64 `form:"max_price"`
-	PropertyType string  `form:"property_type"`
-	BedRooms     int     `form:"bedrooms"`
-	BathRooms    int     `form:"bathrooms"`
-	MinSize      float64 `form:"min_size"`
-	MaxSize      float64 `form:"max_size"`
-	CountryID    uint32  `form:"country_id"`
-	DivisionID   uint32  `form:"division_id"`
-	DistrictID   uint32  `form:"district_id"`
-	Page         int     `form:"page,default=1"`
-	PerPage      int     `form:"per_page,default=10"`
-	Status       string  `form:"status"`
+	OwerID       uint         `form:"owner_id"`
+	Purpose      string       `form:"purpose"`
+	MinPrice     money.Amount `form:"min_price"`
+	MaxPrice     money.Amount `form:"max_price"`
+	Currency     string       `form:"currency"` // of MinPrice and MaxPrice, and prices are also shown in it
+	PropertyType string       `form:"property_type"`
+	BedRooms     int          `form:"bedrooms"`
+	BathRooms    int          `form:"bathrooms"`
+	MinSize      float64      `form:"min_size"`
+	MaxSize      float64      `form:"max_size"`
+	CountryID    uint32       `form:"country_id"`
+	DivisionID   uint32       `form:"division_id"`
+	DistrictID   uint32       `form:"district_id"`
+	ProjectID    uint         `form:"project_id"`
+	Near         string       `form:"near"`      // "lat,lng"
+	RadiusKm     float64      `form:"radius_km"` // used together with Near
+	BBox         string       `form:"bbox"`      // "min_lng,min_lat,max_lng,max_lat"
+	Page         int          `form:"page,default=1"`
+	PerPage      int          `form:"per_page,default=10"`
+	Status       string       `form:"status"`
+
+	// Rental terms; any of them limits results to rent listings that have terms
+	RentPeriod       string       `form:"rent_period" binding:"omitempty,oneof=monthly yearly"`
+	TenantPreference string       `form:"tenant_preference" binding:"omitempty,oneof=family bachelor"`
+	AvailableBefore  string       `form:"available_before" binding:"omitempty,datetime=2006-01-02"`
+	MaxDeposit       money.Amount `form:"max_deposit"` // in Currency
+	MaxAdvanceMonths int          `form:"max_advance_months"`
+	LeaseMonths      int          `form:"lease_months"` // the minimum lease is at most this long
+
+	// Statuses restricts results to any of these statuses; set by the server, not the query string
+	Statuses []string `form:"-"`
+	// ViewerID is the signed in user, used to flag their favorites
+	ViewerID uint `form:"-"`
 }
 
 type AmenitiesDTO struct {
@@ -186,3 +306,124 @@ type PropertyFeatureDetailsDTO struct {
 	UtilsFeature      UtilsFeatureDTO      `json:"utilsFeature"`
 	EnergyFeature     EnergyFeatureDTO     `json:"energyFeature"`
 }
+
+type PropertyTransitionRequestDTO struct {
+	Status string  `json:"status" binding:"required"`
+	Reason *string `json:"reason" binding:"omitempty,max=1000"`
+}
+
+type PropertyStatusHistoryDTO struct {
+	ID         uint    `json:"id"`
+	FromStatus string  `json:"from_status"`
+	ToStatus   string  `json:"to_status"`
+	ActorType  string  `json:"actor_type"`
+	ActorID    *uint   `json:"actor_id"`
+	ActorName  string  `json:"actor_name"`
+	Reason     *string `json:"reason"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+type PropertyMediaDTO struct {
+	ID          uint   `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	SortOrder   int    `json:"sort_order"`
+	Caption     string `json:"caption"`
+	IsCover     bool   `json:"is_cover"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+
+	ProcessingStatus string            `json:"processing_status"`
+	BlurHash         string            `json:"blur_hash"`
+	DominantColor    string            `json:"dominant_color"`
+	Renditions       map[string]string `json:"renditions"`
+}
+
+type PropertyMediaUploadRequestDTO struct {
+	ContentType string `json:"content_type" binding:"required,oneof=image/jpeg image/png image/webp"`
+	SizeBytes   int64  `json:"size_bytes" binding:"required,gt=0"`
+	Caption     string `json:"caption" binding:"max=255"`
+}
+
+type PropertyMediaUploadResponseDTO struct {
+	Media     PropertyMediaDTO `json:"media"`
+	UploadURL string           `json:"upload_url"`
+	ExpiresAt string           `json:"expires_at"`
+}
+
+type PropertyMediaConfirmRequestDTO struct {
+	Width  int `json:"width" binding:"omitempty,gte=0"`
+	Height int `json:"height" binding:"omitempty,gte=0"`
+}
+
+type PropertyMediaOrderRequestDTO struct {
+	MediaIDs []uint `json:"media_ids" binding:"required,min=1"`
+}
+
+type WatermarkSettingDTO struct {
+	Enabled   bool    `json:"enabled"`
+	Source    string  `json:"source"`
+	LogoURL   *string `json:"logo_url"`
+	Position  string  `json:"position"`
+	Opacity   float64 `json:"opacity"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+type WatermarkSettingRequestDTO struct {
+	Enabled  bool    `json:"enabled"`
+	Source   string  `json:"source" binding:"required,oneof=logo company_name"`
+	Position string  `json:"position" binding:"required,oneof=top_left top_right bottom_left bottom_right center"`
+	Opacity  float64 `json:"opacity" binding:"required,gt=0,lte=1"`
+}
+
+// OwnerWatermarkRequestDTO sets an agency's watermark preference. A null enabled
+// goes back to the site setting.
+type OwnerWatermarkRequestDTO struct {
+	Enabled *bool `json:"enabled"`
+}
+
+type WatermarkRerenderResponseDTO struct {
+	Queued int64 `json:"queued"`
+}
+
+type PropertyAttachmentDTO struct {
+	ID          uint   `json:"id"`
+	Kind        string `json:"kind"`
+	Title       string `json:"title"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Visibility  string `json:"visibility"`
+	Status      string `json:"status"`
+	// Available tells the current viewer whether they may download the file
+	Available bool   `json:"available"`
+	CreatedAt string `json:"created_at"`
+}
+
+type PropertyAttachmentUploadRequestDTO struct {
+	Kind        string `json:"kind" binding:"required,oneof=floor_plan brochure title_deed other"`
+	Title       string `json:"title" binding:"required,max=255"`
+	FileName    string `json:"file_name" binding:"required,max=255"`
+	ContentType string `json:"content_type" binding:"required,oneof=application/pdf image/jpeg image/png image/webp"`
+	SizeBytes   int64  `json:"size_bytes" binding:"required,gt=0"`
+	Visibility  string `json:"visibility" binding:"required,oneof=public registered inquiry"`
+}
+
+type PropertyAttachmentUploadResponseDTO struct {
+	Attachment PropertyAttachmentDTO `json:"attachment"`
+	UploadURL  string                `json:"upload_url"`
+	ExpiresAt  string                `json:"expires_at"`
+}
+
+type PropertyAttachmentUpdateRequestDTO struct {
+	Title      string `json:"title" binding:"required,max=255"`
+	Visibility string `json:"visibility" binding:"required,oneof=public registered inquiry"`
+}
+
+type PropertyAttachmentDownloadDTO struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+type PropertyImportDTO struct {
+	ID          uint    `json:"id"`
+	FileName    string  `json:"file_name"`
+	Format      string  `json:"format"`
+	DryRun      bool    `json:"dry_run"`
+	Status      string  `json:"status"`
+	TotalRows   int     `json:"total_rows"`
+	CreatedRows int     `json:"created_rows"`
+	UpdatedRows int     `json:"updated_rows"`
+	FailedRows  int     `json:"failed_rows"`
+	Error       *string `json:"error"`
+	CreatedAt   string  `json:"created_at"`
+	StartedAt   *string `json:"started_at"`
+	FinishedAt  *string `json:"finished_at"`
+}
+
+type PropertyImportColumnDTO struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+// ODataCollectionDTO is an OData v4 collection response. Records only hold the
+// selected fields.
+type ODataCollectionDTO struct {
+	Context  string                   `json:"@odata.context"`
+	Count    *int64                   `json:"@odata.count,omitempty"`
+	Value    []map[string]interface{} `json:"value"`
+	NextLink string                   `json:"@odata.nextLink,omitempty"`
+}
+
+// ODataServiceDTO is the service document listing the entity sets
+type ODataServiceDTO struct {
+	Context string              `json:"@odata.context"`
+	Value   []ODataEntitySetDTO `json:"value"`
+}
+
+type ODataEntitySetDTO struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+}
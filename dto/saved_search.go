@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/farhapartex/real_estate_be/lib/money"
+
+// SavedSearchCriteriaDTO uses the field names of the property search filters
+type SavedSearchCriteriaDTO struct {
+	Purpose      string       `json:"purpose" binding:"omitempty,oneof=sale rent"`
+	PropertyType string       `json:"property_type" binding:"max=50"`
+	MinPrice     money.Amount `json:"min_price" binding:"gte=0"`
+	MaxPrice     money.Amount `json:"max_price" binding:"gte=0"`
+	Currency     string       `json:"currency" binding:"omitempty,len=3"` // of MinPrice and MaxPrice, the default currency when empty
+	BedRooms     int          `json:"bedrooms" binding:"gte=0"`
+	BathRooms    int          `json:"bathrooms" binding:"gte=0"`
+	MinSize      float64      `json:"min_size" binding:"gte=0"`
+	MaxSize      float64      `json:"max_size" binding:"gte=0"`
+	CountryID    uint32       `json:"country_id"`
+	DivisionID   uint32       `json:"division_id"`
+	DistrictID   uint32       `json:"district_id"`
+	Near         string       `json:"near" binding:"max=64"`  // "lat,lng"
+	RadiusKm     float64      `json:"radius_km"`              // used together with Near
+	BBox         string       `json:"bbox" binding:"max=128"` // "min_lng,min_lat,max_lng,max_lat"
+
+	// Rental terms; any of them limits matches to rent listings that have terms
+	RentPeriod       string       `json:"rent_period" binding:"omitempty,oneof=monthly yearly"`
+	TenantPreference string       `json:"tenant_preference" binding:"omitempty,oneof=family bachelor"`
+	AvailableBefore  string       `json:"available_before" binding:"omitempty,datetime=2006-01-02"`
+	MaxDeposit       money.Amount `json:"max_deposit" binding:"gte=0"` // in Currency
+	MaxAdvanceMonths int          `json:"max_advance_months" binding:"gte=0"`
+	LeaseMonths      int          `json:"lease_months" binding:"gte=0"` // the minimum lease is at most this long
+}
+
+type SavedSearchRequestDTO struct {
+	Name          string                 `json:"name" binding:"required,max=150"`
+	Criteria      SavedSearchCriteriaDTO `json:"criteria"`
+	Frequency     string                 `json:"frequency" binding:"required,oneof=instant daily weekly"`
+	AlertsEnabled *bool                  `json:"alerts_enabled"`
+}
+
+type SavedSearchDTO struct {
+	ID            uint                   `json:"id"`
+	Name          string                 `json:"name"`
+	Criteria      SavedSearchCriteriaDTO `json:"criteria"`
+	Frequency     string                 `json:"frequency"`
+	AlertsEnabled bool                   `json:"alerts_enabled"`
+	LastAlertAt   *string                `json:"last_alert_at"`
+	CreatedAt     string                 `json:"created_at"`
+}
+
+type SavedSearchUnsubscribeDTO struct {
+	Message string `json:"message"`
+}
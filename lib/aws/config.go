@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// NewS3ClientFromEnv builds a client from AWS_REGION, AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_S3_BUCKET. It returns nil when no bucket is set.
+func NewS3ClientFromEnv() *S3Client {
+	bucket := os.Getenv("AWS_S3_BUCKET")
+	if bucket == "" {
+		return nil
+	}
+
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+
+	cfg := aws.Config{
+		Region: os.Getenv("AWS_REGION"),
+		Credentials: aws.NewCredentialsCache(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{
+				AccessKeyID:     accessKey,
+				SecretAccessKey: secretKey,
+				Source:          "environment",
+			}, nil
+		})),
+	}
+
+	return NewS3Client(cfg, bucket)
+}
+
+// PublicURL returns the URL a stored object is served from. MEDIA_BASE_URL can
+// point to a CDN in front of the bucket.
+func PublicURL(key string) string {
+	baseURL := os.Getenv("MEDIA_BASE_URL")
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", os.Getenv("AWS_S3_BUCKET"), os.Getenv("AWS_REGION"))
+	}
+
+	return strings.TrimRight(baseURL, "/") + "/" + key
+}
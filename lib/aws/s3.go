@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"errors"
+	"io"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -50,7 +51,81 @@ func (s *S3Client) DeleteFile(ctx context.Context, key string) error {
 
 	_, err := s.client.DeleteObject(ctx, input)
 	if err != nil {
-		return errors.New("failed to generate upload url")
+		return errors.New("failed to delete file")
+	}
+
+	return nil
+}
+
+func (s *S3Client) GeneratePresignDownloadURL(ctx context.Context, key string, expiredAt time.Duration) (string, error) {
+	client := s3.NewPresignClient(s.client)
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	presignResult, err := client.PresignGetObject(ctx, input, func(opts *s3.PresignOptions) {
+		opts.Expires = expiredAt
+	})
+
+	if err != nil {
+		return "", errors.New("failed to generate download url")
+	}
+
+	return presignResult.URL, nil
+}
+
+func (s *S3Client) HeadFile(ctx context.Context, key string) (*s3.HeadObjectOutput, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	return s.client.HeadObject(ctx, input)
+}
+
+func (s *S3Client) CopyFile(ctx context.Context, sourceKey string, destinationKey string) error {
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(s.bucket),
+		CopySource: aws.String(s.bucket + "/" + sourceKey),
+		Key:        aws.String(destinationKey),
+	}
+
+	_, err := s.client.CopyObject(ctx, input)
+	if err != nil {
+		return errors.New("failed to copy file")
+	}
+
+	return nil
+}
+
+func (s *S3Client) GetFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	output, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Body, nil
+}
+
+func (s *S3Client) PutFile(ctx context.Context, key string, contentType string, body io.Reader, size int64) error {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+		Body:          body,
+	}
+
+	_, err := s.client.PutObject(ctx, input)
+	if err != nil {
+		return errors.New("failed to upload file")
 	}
 
 	return nil
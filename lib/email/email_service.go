@@ -10,6 +10,10 @@ import (
 )
 
 func SendEmail(to, subject, body string) error {
+	return sendEmail(to, subject, body, body)
+}
+
+func sendEmail(to, subject, plainBody, htmlBody string) error {
 
 	apiKey := os.Getenv("SENDGRID_API_KEY")
 	if apiKey == "" {
@@ -29,8 +33,8 @@ func SendEmail(to, subject, body string) error {
 		mail.NewEmail(senderName, from),
 		subject,
 		mail.NewEmail("", to),
-		body, // Plain text version
-		body, // HTML version (same as plain text in this case)
+		plainBody,
+		htmlBody,
 	)
 
 	client := sendgrid.NewSendClient(apiKey)
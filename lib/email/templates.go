@@ -0,0 +1,62 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	texttemplate "text/template"
+)
+
+func templateDir() string {
+	dir := os.Getenv("EMAIL_TEMPLATE_DIR")
+	if dir == "" {
+		dir = "templates/email"
+	}
+	return dir
+}
+
+// SendTemplateEmail renders templates/email/<name>/text.tmpl and html.tmpl with data
+// and sends both versions
+func SendTemplateEmail(to, subject, name string, data interface{}) error {
+	dir := filepath.Join(templateDir(), name)
+
+	textTmpl, err := texttemplate.ParseFiles(filepath.Join(dir, "text.tmpl"))
+	if err != nil {
+		return fmt.Errorf("failed to load %s text template: %w", name, err)
+	}
+
+	htmlTmpl, err := htmltemplate.ParseFiles(filepath.Join(dir, "html.tmpl"))
+	if err != nil {
+		return fmt.Errorf("failed to load %s html template: %w", name, err)
+	}
+
+	var plainBody, htmlBody bytes.Buffer
+	if err := textTmpl.Execute(&plainBody, data); err != nil {
+		return fmt.Errorf("failed to render %s text template: %w", name, err)
+	}
+	if err := htmlTmpl.Execute(&htmlBody, data); err != nil {
+		return fmt.Errorf("failed to render %s html template: %w", name, err)
+	}
+
+	return sendEmail(to, subject, plainBody.String(), htmlBody.String())
+}
+
+// CompanyName is the brand name used in email templates
+func CompanyName() string {
+	name := os.Getenv("SENDER_NAME")
+	if name == "" {
+		name = "Your Application"
+	}
+	return name
+}
+
+// SupportEmail is the contact address shown in email footers
+func SupportEmail() string {
+	address := os.Getenv("SUPPORT_EMAIL")
+	if address == "" {
+		address = os.Getenv("SENDER_EMAIL")
+	}
+	return address
+}
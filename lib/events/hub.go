@@ -0,0 +1,186 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Reconnecting clients can catch up on the last 50 events of the last 5 minutes
+	replayBufferSize = 50
+	replayBufferTTL  = 5 * time.Minute
+
+	subscriberChannelSize = 16
+)
+
+// Event is a message for one user. Type names it for the client, e.g.
+// "inquiry.received". Data is already JSON encoded so events can be passed
+// between instances unchanged.
+type Event struct {
+	ID        string          `json:"id"`
+	UserID    uint            `json:"user_id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Backend carries events between API instances. Publish must eventually hand
+// every event to the deliver function given to Start, on every instance
+// including the one that published it.
+type Backend interface {
+	Start(deliver func(Event)) error
+	Publish(event Event) error
+	Close() error
+}
+
+// Subscription is one open stream. Events arrive on C; Replay holds the events
+// missed since the Last-Event-ID the client resumed from.
+type Subscription struct {
+	C      chan Event
+	Replay []Event
+	userID uint
+}
+
+// Hub fans events out to the streams of each user and keeps a short replay
+// buffer per user so reconnecting clients can catch up
+type Hub struct {
+	backend Backend
+
+	mu          sync.Mutex
+	subscribers map[uint]map[*Subscription]struct{}
+	recent      map[uint][]Event
+}
+
+func NewHub(backend Backend) (*Hub, error) {
+	hub := &Hub{
+		backend:     backend,
+		subscribers: map[uint]map[*Subscription]struct{}{},
+		recent:      map[uint][]Event{},
+	}
+
+	if err := backend.Start(hub.deliver); err != nil {
+		return nil, err
+	}
+
+	return hub, nil
+}
+
+// Publish sends an event to all open streams of userID. Errors are logged only;
+// a missed push must never fail the request that caused it.
+func (h *Hub) Publish(userID uint, eventType string, data interface{}) {
+	if h == nil {
+		return
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+
+	event := Event{
+		ID:        newEventID(),
+		UserID:    userID,
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: time.Now(),
+	}
+
+	if err := h.backend.Publish(event); err != nil {
+		log.Printf("Failed to publish %s event: %v", eventType, err)
+	}
+}
+
+// Subscribe opens a stream for userID. When lastEventID is still in the replay
+// buffer, the events after it are returned in Replay.
+func (h *Hub) Subscribe(userID uint, lastEventID string) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscription := &Subscription{
+		C:      make(chan Event, subscriberChannelSize),
+		userID: userID,
+	}
+
+	if lastEventID != "" {
+		recent := h.recent[userID]
+		for i, event := range recent {
+			if event.ID == lastEventID {
+				subscription.Replay = append([]Event{}, recent[i+1:]...)
+				break
+			}
+		}
+	}
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = map[*Subscription]struct{}{}
+	}
+	h.subscribers[userID][subscription] = struct{}{}
+
+	return subscription
+}
+
+func (h *Hub) Unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers[subscription.userID], subscription)
+	if len(h.subscribers[subscription.userID]) == 0 {
+		delete(h.subscribers, subscription.userID)
+	}
+}
+
+func (h *Hub) Close() error {
+	return h.backend.Close()
+}
+
+// deliver is called by the backend for every event published on any instance
+func (h *Hub) deliver(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remember(event)
+
+	for subscription := range h.subscribers[event.UserID] {
+		select {
+		case subscription.C <- event:
+		default:
+			// A stuck client loses the event; it can resume with Last-Event-ID
+		}
+	}
+}
+
+// remember adds event to the replay buffer and drops expired events. Must be
+// called with h.mu held.
+func (h *Hub) remember(event Event) {
+	cutoff := time.Now().Add(-replayBufferTTL)
+
+	for userID, recent := range h.recent {
+		start := 0
+		for start < len(recent) && recent[start].CreatedAt.Before(cutoff) {
+			start++
+		}
+		if start == len(recent) {
+			delete(h.recent, userID)
+		} else if start > 0 {
+			h.recent[userID] = recent[start:]
+		}
+	}
+
+	recent := append(h.recent[event.UserID], event)
+	if len(recent) > replayBufferSize {
+		recent = recent[len(recent)-replayBufferSize:]
+	}
+	h.recent[event.UserID] = recent
+}
+
+func newEventID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,25 @@
+package events
+
+// MemoryBackend delivers events inside the process. Use it when a single API
+// instance is running.
+type MemoryBackend struct {
+	deliver func(Event)
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+func (b *MemoryBackend) Start(deliver func(Event)) error {
+	b.deliver = deliver
+	return nil
+}
+
+func (b *MemoryBackend) Publish(event Event) error {
+	b.deliver(event)
+	return nil
+}
+
+func (b *MemoryBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Postgres refuses NOTIFY payloads of 8000 bytes or more
+const maxNotifyPayload = 7900
+
+// PostgresBackend passes events between API instances with LISTEN/NOTIFY, so
+// multi-instance deployments need no extra infrastructure
+type PostgresBackend struct {
+	db       *sql.DB
+	dsn      string
+	channel  string
+	listener *pq.Listener
+}
+
+func NewPostgresBackend(db *sql.DB, dsn string, channel string) *PostgresBackend {
+	return &PostgresBackend{db: db, dsn: dsn, channel: channel}
+}
+
+func (b *PostgresBackend) Start(deliver func(Event)) error {
+	b.listener = pq.NewListener(b.dsn, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Event listener: %v", err)
+		}
+	})
+
+	if err := b.listener.Listen(b.channel); err != nil {
+		return err
+	}
+
+	go func() {
+		for notification := range b.listener.Notify {
+			// nil is sent after a reconnect; events sent meanwhile are lost and
+			// clients catch up from the replay buffer of the other instances
+			if notification == nil {
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				log.Printf("Invalid event on %s: %v", b.channel, err)
+				continue
+			}
+			deliver(event)
+		}
+	}()
+
+	return nil
+}
+
+func (b *PostgresBackend) Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if len(payload) > maxNotifyPayload {
+		return errors.New("event is too large to publish")
+	}
+
+	_, err = b.db.Exec("SELECT pg_notify($1, $2)", b.channel, string(payload))
+	return err
+}
+
+func (b *PostgresBackend) Close() error {
+	if b.listener == nil {
+		return nil
+	}
+	return b.listener.Close()
+}
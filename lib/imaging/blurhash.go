@@ -0,0 +1,108 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+const base83Characters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// BlurHash encodes img as a https://blurha.sh placeholder with 4x3 components
+func BlurHash(img image.Image) string {
+	const xComponents, yComponents = 4, 3
+
+	small := Fit(img, 32, 32)
+	bounds := small.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+
+			var r, g, b float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+					c := color.RGBAModel.Convert(small.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+					r += basis * srgbToLinear(c.R)
+					g += basis * srgbToLinear(c.G)
+					b += basis * srgbToLinear(c.B)
+				}
+			}
+
+			scale := normalisation / float64(width*height)
+			factors = append(factors, [3]float64{r * scale, g * scale, b * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(encode83((xComponents-1)+(yComponents-1)*9, 1))
+
+	maximumValue := 1.0
+	ac := factors[1:]
+	if len(ac) > 0 {
+		actualMaximum := 0.0
+		for _, factor := range ac {
+			for _, value := range factor {
+				actualMaximum = math.Max(actualMaximum, math.Abs(value))
+			}
+		}
+		quantisedMaximum := int(math.Max(0, math.Min(82, math.Floor(actualMaximum*166-0.5))))
+		maximumValue = float64(quantisedMaximum+1) / 166
+		hash.WriteString(encode83(quantisedMaximum, 1))
+	} else {
+		hash.WriteString(encode83(0, 1))
+	}
+
+	dc := factors[0]
+	hash.WriteString(encode83(linearToSrgb(dc[0])<<16+linearToSrgb(dc[1])<<8+linearToSrgb(dc[2]), 4))
+
+	for _, factor := range ac {
+		quantR := quantiseAC(factor[0], maximumValue)
+		quantG := quantiseAC(factor[1], maximumValue)
+		quantB := quantiseAC(factor[2], maximumValue)
+		hash.WriteString(encode83(quantR*19*19+quantG*19+quantB, 2))
+	}
+
+	return hash.String()
+}
+
+func quantiseAC(value, maximumValue float64) int {
+	return int(math.Max(0, math.Min(18, math.Floor(signPow(value/maximumValue, 0.5)*9+9.5))))
+}
+
+func encode83(value, length int) string {
+	var result strings.Builder
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		result.WriteByte(base83Characters[digit])
+	}
+	return result.String()
+}
+
+func srgbToLinear(value uint8) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSrgb(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(value, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
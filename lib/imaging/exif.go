@@ -0,0 +1,217 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+const (
+	tagOrientation = 0x0112
+	tagGPSInfo     = 0x8825
+)
+
+var errInvalidExif = errors.New("invalid exif data")
+
+// exifSegment locates the TIFF structure holding EXIF data inside a JPEG, PNG or
+// WebP file. It returns the slice (sharing memory with data) and, for PNG, the
+// offset of the chunk so its CRC can be fixed after editing.
+func exifSegment(data []byte, format string) (tiff []byte, pngChunk int, err error) {
+	pngChunk = -1
+
+	switch format {
+	case "jpeg":
+		if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+			return nil, pngChunk, errors.New("invalid jpeg")
+		}
+		offset := 2
+		for offset+4 <= len(data) {
+			if data[offset] != 0xFF {
+				return nil, pngChunk, errors.New("invalid jpeg")
+			}
+			marker := data[offset+1]
+			// start of scan: no more metadata segments
+			if marker == 0xDA || marker == 0xD9 {
+				break
+			}
+			length := int(binary.BigEndian.Uint16(data[offset+2:]))
+			end := offset + 2 + length
+			if length < 2 || end > len(data) {
+				return nil, pngChunk, errors.New("invalid jpeg")
+			}
+			payload := data[offset+4 : end]
+			if marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+				return payload[6:], pngChunk, nil
+			}
+			offset = end
+		}
+	case "png":
+		offset := 8
+		for offset+12 <= len(data) {
+			length := int(binary.BigEndian.Uint32(data[offset:]))
+			end := offset + 12 + length
+			if length < 0 || end > len(data) {
+				return nil, pngChunk, errors.New("invalid png")
+			}
+			if string(data[offset+4:offset+8]) == "eXIf" {
+				return data[offset+8 : offset+8+length], offset, nil
+			}
+			offset = end
+		}
+	case "webp":
+		offset := 12
+		for offset+8 <= len(data) {
+			length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+			end := offset + 8 + length
+			if length < 0 || end > len(data) {
+				return nil, pngChunk, errors.New("invalid webp")
+			}
+			if string(data[offset:offset+4]) == "EXIF" {
+				payload := data[offset+8 : end]
+				return bytes.TrimPrefix(payload, []byte("Exif\x00\x00")), pngChunk, nil
+			}
+			offset = end + length%2
+		}
+	}
+
+	return nil, pngChunk, nil
+}
+
+// Orientation returns the EXIF orientation (1-8), defaulting to 1
+func Orientation(data []byte, format string) int {
+	tiff, _, err := exifSegment(data, format)
+	if err != nil || tiff == nil {
+		return 1
+	}
+
+	order, ifd0, err := tiffHeader(tiff)
+	if err != nil {
+		return 1
+	}
+
+	entry, err := findEntry(tiff, order, ifd0, tagOrientation)
+	if err != nil || entry < 0 {
+		return 1
+	}
+
+	value := int(order.Uint16(tiff[entry+8:]))
+	if value < 1 || value > 8 {
+		return 1
+	}
+
+	return value
+}
+
+// StripGPS erases the GPS block from the file's EXIF data in place, keeping the
+// rest (orientation, camera info) intact. It reports whether anything changed.
+func StripGPS(data []byte, format string) (bool, error) {
+	tiff, pngChunk, err := exifSegment(data, format)
+	if err != nil {
+		return false, err
+	}
+	if tiff == nil {
+		return false, nil
+	}
+
+	order, ifd0, err := tiffHeader(tiff)
+	if err != nil {
+		return false, err
+	}
+
+	entry, err := findEntry(tiff, order, ifd0, tagGPSInfo)
+	if err != nil {
+		return false, err
+	}
+	if entry < 0 {
+		return false, nil
+	}
+
+	gpsIFD := int(order.Uint32(tiff[entry+8:]))
+	if gpsIFD+2 > len(tiff) {
+		return false, errInvalidExif
+	}
+
+	count := int(order.Uint16(tiff[gpsIFD:]))
+	if gpsIFD+2+count*12 > len(tiff) {
+		return false, errInvalidExif
+	}
+
+	for i := 0; i < count; i++ {
+		field := gpsIFD + 2 + i*12
+		size := typeSize(order.Uint16(tiff[field+2:])) * int(order.Uint32(tiff[field+4:]))
+		if size > 4 {
+			valueOffset := int(order.Uint32(tiff[field+8:]))
+			if valueOffset >= 0 && valueOffset+size <= len(tiff) {
+				clear(tiff[valueOffset : valueOffset+size])
+			}
+		}
+		clear(tiff[field : field+12])
+	}
+	order.PutUint16(tiff[gpsIFD:], 0)
+
+	if pngChunk >= 0 {
+		length := int(binary.BigEndian.Uint32(data[pngChunk:]))
+		crc := crc32.ChecksumIEEE(data[pngChunk+4 : pngChunk+8+length])
+		binary.BigEndian.PutUint32(data[pngChunk+8+length:], crc)
+	}
+
+	return true, nil
+}
+
+func tiffHeader(tiff []byte) (binary.ByteOrder, int, error) {
+	if len(tiff) < 8 {
+		return nil, 0, errInvalidExif
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0, errInvalidExif
+	}
+
+	if order.Uint16(tiff[2:]) != 42 {
+		return nil, 0, errInvalidExif
+	}
+
+	return order, int(order.Uint32(tiff[4:])), nil
+}
+
+// findEntry returns the offset of the 12-byte IFD entry for tag, or -1
+func findEntry(tiff []byte, order binary.ByteOrder, ifd int, tag uint16) (int, error) {
+	if ifd+2 > len(tiff) {
+		return -1, errInvalidExif
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	if ifd+2+count*12 > len(tiff) {
+		return -1, errInvalidExif
+	}
+
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if order.Uint16(tiff[entry:]) == tag {
+			return entry, nil
+		}
+	}
+
+	return -1, nil
+}
+
+func typeSize(fieldType uint16) int {
+	switch fieldType {
+	case 1, 2, 6, 7:
+		return 1
+	case 3, 8:
+		return 2
+	case 4, 9, 11:
+		return 4
+	case 5, 10, 12:
+		return 8
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxPixels guards the decoder against decompression bombs (about 50 MP)
+const maxPixels = 50_000_000
+
+const renditionQuality = 82
+
+type RenditionSpec struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+	// Watermark marks renditions that get the watermark when it is enabled.
+	// Thumbnails are too small to be worth scraping.
+	Watermark bool
+}
+
+// Renditions are the resized copies generated for every listing photo
+var Renditions = []RenditionSpec{
+	{Name: "thumb", MaxWidth: 320, MaxHeight: 320},
+	{Name: "card", MaxWidth: 800, MaxHeight: 600, Watermark: true},
+	{Name: "full", MaxWidth: 1600, MaxHeight: 1600, Watermark: true},
+}
+
+var contentTypeFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// Decode checks that data really is the declared image type and decodes it with
+// EXIF orientation applied. The detected format name is returned as well.
+func Decode(data []byte, contentType string) (image.Image, string, error) {
+	expected, ok := contentTypeFormats[contentType]
+	if !ok {
+		return nil, "", errors.New("unsupported image type")
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errors.New("file is not a valid image")
+	}
+
+	if format != expected {
+		return nil, "", fmt.Errorf("file is %s, not %s", format, contentType)
+	}
+
+	if config.Width*config.Height > maxPixels {
+		return nil, "", errors.New("image dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errors.New("file is not a valid image")
+	}
+
+	return applyOrientation(img, Orientation(data, format)), format, nil
+}
+
+// Fit scales img down to fit within maxWidth x maxHeight, never up
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	scale := min(1, float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	targetWidth := max(1, int(float64(width)*scale+0.5))
+	targetHeight := max(1, int(float64(height)*scale+0.5))
+
+	dst := image.NewRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+// EncodeJPEG encodes a rendition. The encoder writes no metadata, so renditions
+// never carry EXIF.
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: renditionQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DominantColor returns the average colour of img as #rrggbb
+func DominantColor(img image.Image) string {
+	small := Fit(img, 32, 32)
+	bounds := small.Bounds()
+
+	var r, g, b, count uint64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(small.At(x, y)).(color.RGBA)
+			r += uint64(c.R)
+			g += uint64(c.G)
+			b += uint64(c.B)
+			count++
+		}
+	}
+
+	if count == 0 {
+		return "#000000"
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", r/count, g/count, b/count)
+}
+
+// applyOrientation rotates/flips img according to an EXIF orientation value
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// orientations 5-8 swap width and height
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,133 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	PositionTopLeft     = "top_left"
+	PositionTopRight    = "top_right"
+	PositionBottomLeft  = "bottom_left"
+	PositionBottomRight = "bottom_right"
+	PositionCenter      = "center"
+)
+
+// Watermark is drawn over public renditions. Logo wins over Text when both are set.
+type Watermark struct {
+	Logo     image.Image
+	Text     string
+	Position string
+	Opacity  float64
+}
+
+var (
+	watermarkFont     *opentype.Font
+	watermarkFontOnce sync.Once
+	watermarkFontErr  error
+)
+
+// ApplyWatermark returns a copy of img with the watermark drawn on it. The mark is
+// sized relative to the image so it looks the same on every rendition.
+func ApplyWatermark(img image.Image, watermark Watermark) (image.Image, error) {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	var mark image.Image
+	var err error
+	if watermark.Logo != nil {
+		mark = Fit(watermark.Logo, dst.Bounds().Dx()/4, dst.Bounds().Dy()/4)
+	} else if watermark.Text != "" {
+		mark, err = renderText(watermark.Text, dst.Bounds().Dx())
+		if err != nil {
+			return nil, err
+		}
+		// long company names are shrunk instead of running off the image
+		mark = Fit(mark, dst.Bounds().Dx()*6/10, dst.Bounds().Dy()/4)
+	} else {
+		return dst, nil
+	}
+
+	opacity := min(1, max(0, watermark.Opacity))
+	alpha := image.NewUniform(color.Alpha{A: uint8(opacity * 255)})
+	target := placeWatermark(dst.Bounds(), mark.Bounds(), watermark.Position)
+
+	draw.DrawMask(dst, target, mark, mark.Bounds().Min, alpha, image.Point{}, draw.Over)
+
+	return dst, nil
+}
+
+// placeWatermark returns where a mark of the given size goes, keeping a margin
+// of 3% of the shorter image side
+func placeWatermark(canvas, mark image.Rectangle, position string) image.Rectangle {
+	margin := min(canvas.Dx(), canvas.Dy()) * 3 / 100
+	width, height := mark.Dx(), mark.Dy()
+
+	var x, y int
+	switch position {
+	case PositionTopLeft:
+		x, y = margin, margin
+	case PositionTopRight:
+		x, y = canvas.Dx()-width-margin, margin
+	case PositionBottomLeft:
+		x, y = margin, canvas.Dy()-height-margin
+	case PositionCenter:
+		x, y = (canvas.Dx()-width)/2, (canvas.Dy()-height)/2
+	default:
+		x, y = canvas.Dx()-width-margin, canvas.Dy()-height-margin
+	}
+
+	return image.Rect(x, y, x+width, y+height)
+}
+
+// renderText draws text in white with a dark outline so it stays readable on
+// light and dark photos
+func renderText(text string, canvasWidth int) (image.Image, error) {
+	watermarkFontOnce.Do(func() {
+		watermarkFont, watermarkFontErr = opentype.Parse(gobold.TTF)
+	})
+	if watermarkFontErr != nil {
+		return nil, watermarkFontErr
+	}
+
+	face, err := opentype.NewFace(watermarkFont, &opentype.FaceOptions{
+		Size:    max(10, float64(canvasWidth)/28),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	outline := 2
+	width := font.MeasureString(face, text).Ceil() + outline*2
+	height := (metrics.Ascent + metrics.Descent).Ceil() + outline*2
+
+	mark := image.NewRGBA(image.Rect(0, 0, width, height))
+	drawer := &font.Drawer{Dst: mark, Face: face}
+	baseline := metrics.Ascent.Ceil() + outline
+
+	drawer.Src = image.NewUniform(color.RGBA{A: 160})
+	for dx := -outline; dx <= outline; dx += outline {
+		for dy := -outline; dy <= outline; dy += outline {
+			drawer.Dot = fixed.P(outline+dx, baseline+dy)
+			drawer.DrawString(text)
+		}
+	}
+
+	drawer.Src = image.White
+	drawer.Dot = fixed.P(outline, baseline)
+	drawer.DrawString(text)
+
+	return mark, nil
+}
@@ -0,0 +1,296 @@
+// Package money holds exact prices and exchange rates. Amounts are whole
+// hundredths of the currency unit and rates are rationals, so money is never
+// rounded through floating point on its way to or from the database.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency prices are in unless they say otherwise and
+// the base of the exchange rates, DEFAULT_CURRENCY (default BDT)
+func DefaultCurrency() string {
+	currency := strings.ToUpper(strings.TrimSpace(os.Getenv("DEFAULT_CURRENCY")))
+	if currency == "" {
+		currency = "BDT"
+	}
+	return currency
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code
+func ValidCurrency(code string) bool {
+	return currencyPattern.MatchString(code)
+}
+
+// Amount is a price in hundredths of its currency, stored as numeric(16,2)
+type Amount int64
+
+// maxAmountDigits is the number of whole digits numeric(16,2) holds
+const maxAmountDigits = 14
+
+var amountPattern = regexp.MustCompile(`^(-)?(\d+)(?:\.(\d{1,2}))?$`)
+
+// Parse reads a decimal amount with at most two decimal places, like 1500 or 1499.99
+func Parse(text string) (Amount, error) {
+	match := amountPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return 0, fmt.Errorf("%q is not an amount with at most 2 decimal places", text)
+	}
+
+	whole := strings.TrimLeft(match[2], "0")
+	if len(whole) > maxAmountDigits {
+		return 0, fmt.Errorf("%q is too large", text)
+	}
+
+	units, _ := strconv.ParseInt("0"+whole, 10, 64)
+	cents, _ := strconv.ParseInt((match[3] + "00")[:2], 10, 64)
+
+	amount := Amount(units*100 + cents)
+	if match[1] != "" {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// String formats the amount with two decimal places
+func (a Amount) String() string {
+	sign := ""
+	value := int64(a)
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)
+}
+
+// Float64 is the amount as a float, for percentages and spreadsheets only
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimal places
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding one, without going through float64
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	amount, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// MarshalText is used for XML character data
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalParam reads query string parameters bound by gin
+func (a *Amount) UnmarshalParam(param string) error {
+	amount, err := Parse(param)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// Value stores the amount as a decimal string so the numeric column gets it exactly
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		return a.scanText(string(v))
+	case string:
+		return a.scanText(v)
+	case int64:
+		*a = Amount(v * 100)
+	case float64:
+		// columns that were double precision before they became numeric
+		*a = Amount(math.Round(v * 100))
+	default:
+		return fmt.Errorf("cannot scan %T into an amount", src)
+	}
+	return nil
+}
+
+func (a *Amount) scanText(text string) error {
+	rat, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return fmt.Errorf("cannot scan %q into an amount", text)
+	}
+	*a = roundCents(rat)
+	return nil
+}
+
+// Convert multiplies the amount by the rate, rounding half away from zero to the hundredth
+func (a Amount) Convert(rate Rate) Amount {
+	value := new(big.Rat).SetInt64(int64(a))
+	return roundCents(value.Mul(value, rate.rat()).Quo(value, big.NewRat(100, 1)))
+}
+
+// roundCents rounds a value in whole units to hundredths, half away from zero
+func roundCents(value *big.Rat) Amount {
+	scaled := new(big.Rat).Mul(value, big.NewRat(100, 1))
+	num := new(big.Int).Set(scaled.Num())
+	den := scaled.Denom()
+
+	negative := num.Sign() < 0
+	num.Abs(num)
+
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(den) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if negative {
+		quotient.Neg(quotient)
+	}
+	return Amount(quotient.Int64())
+}
+
+// Rate is an exact exchange rate, stored as numeric(20,10). The zero value is 0.
+type Rate struct {
+	value *big.Rat
+}
+
+// rateScale is the number of decimal places a rate keeps
+const rateScale = 10
+
+var ratePattern = regexp.MustCompile(`^\d{1,10}(?:\.\d{1,10})?$`)
+
+// ErrInvalidRate is returned for a rate that is not a positive decimal
+var ErrInvalidRate = errors.New("rate must be a positive decimal number with at most 10 decimal places")
+
+// ParseRate reads a positive decimal rate like 119.75
+func ParseRate(text string) (Rate, error) {
+	text = strings.TrimSpace(text)
+	if !ratePattern.MatchString(text) {
+		return Rate{}, ErrInvalidRate
+	}
+
+	value, _ := new(big.Rat).SetString(text)
+	if value.Sign() <= 0 {
+		return Rate{}, ErrInvalidRate
+	}
+	return Rate{value: value}, nil
+}
+
+// One is the rate of a currency to itself
+func One() Rate {
+	return Rate{value: big.NewRat(1, 1)}
+}
+
+func (r Rate) rat() *big.Rat {
+	if r.value == nil {
+		return new(big.Rat)
+	}
+	return r.value
+}
+
+// IsZero reports whether the rate is unset
+func (r Rate) IsZero() bool {
+	return r.rat().Sign() == 0
+}
+
+// Mul returns r * other
+func (r Rate) Mul(other Rate) Rate {
+	return Rate{value: new(big.Rat).Mul(r.rat(), other.rat())}
+}
+
+// Quo returns r / other, or zero when other is zero
+func (r Rate) Quo(other Rate) Rate {
+	if other.IsZero() {
+		return Rate{}
+	}
+	return Rate{value: new(big.Rat).Quo(r.rat(), other.rat())}
+}
+
+// Inverse returns 1 / r, or zero when r is zero
+func (r Rate) Inverse() Rate {
+	return One().Quo(r)
+}
+
+// String formats the rate with up to 10 decimal places, without trailing zeros
+func (r Rate) String() string {
+	text := r.rat().FloatString(rateScale)
+	text = strings.TrimRight(text, "0")
+	return strings.TrimSuffix(text, ".")
+}
+
+// MarshalJSON writes the rate as a JSON number
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding one, without going through float64
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	rate, err := ParseRate(text)
+	if err != nil {
+		return err
+	}
+	*r = rate
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func (r *Rate) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		*r = Rate{}
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	default:
+		return fmt.Errorf("cannot scan %T into a rate", src)
+	}
+
+	value, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return fmt.Errorf("cannot scan %q into a rate", text)
+	}
+	*r = Rate{value: value}
+	return nil
+}
@@ -0,0 +1,381 @@
+package odata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// maxFilterLength and maxFilterDepth keep a single request from building a huge query
+	maxFilterLength = 4000
+	maxFilterDepth  = 32
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenString
+	tokenNumber
+	tokenTime
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a $filter expression into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenOpen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenClose, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case r == '\'':
+			// Strings are single quoted; a quote inside is doubled
+			var text strings.Builder
+			start := i
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						text.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{tokenString, text.String(), start})
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && strings.ContainsRune("0123456789-+.:TZeE", runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			kind := tokenNumber
+			if strings.ContainsAny(text, "T:") || (len(text) == 10 && text[4] == '-' && text[7] == '-') {
+				kind = tokenTime
+			}
+			tokens = append(tokens, token{kind, text, start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokenName, string(runes[start:i]), start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, i)
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+// node is a parsed $filter expression
+type node interface{}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+type notNode struct {
+	operand node
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+type inNode struct {
+	field  fieldNode
+	values []literalNode
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+type fieldNode struct {
+	name string
+	pos  int
+}
+
+type literalNode struct {
+	kind  tokenKind
+	value interface{} // string, float64, bool, time.Time or nil for null
+	text  string
+}
+
+var comparisonOperators = map[string]string{
+	"eq": "=",
+	"ne": "<>",
+	"gt": ">",
+	"ge": ">=",
+	"lt": "<",
+	"le": "<=",
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func parseFilter(input string) (node, error) {
+	if len(input) > maxFilterLength {
+		return nil, fmt.Errorf("$filter is longer than %d characters", maxFilterLength)
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", next.text, next.pos)
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isName(name string) bool {
+	t := p.peek()
+	return t.kind == tokenName && t.text == name
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	t := p.next()
+	if t.kind != kind {
+		return fmt.Errorf("expected %q at position %d", text, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isName("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{"OR", left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isName("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{"AND", left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxFilterDepth {
+		return nil, fmt.Errorf("$filter is nested more than %d levels deep", maxFilterDepth)
+	}
+
+	if p.isName("not") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind != tokenName {
+		return left, nil
+	}
+
+	if _, ok := comparisonOperators[t.text]; ok {
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{t.text, left, right}, nil
+	}
+
+	if t.text == "in" {
+		p.next()
+		field, ok := left.(fieldNode)
+		if !ok {
+			return nil, fmt.Errorf("in needs a field on its left at position %d", t.pos)
+		}
+		if err := p.expect(tokenOpen, "("); err != nil {
+			return nil, err
+		}
+
+		var values []literalNode
+		for {
+			value, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			literal, ok := value.(literalNode)
+			if !ok {
+				return nil, fmt.Errorf("in takes a list of values at position %d", t.pos)
+			}
+			values = append(values, literal)
+
+			if p.peek().kind == tokenComma {
+				p.next()
+				continue
+			}
+			if err := p.expect(tokenClose, ")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+		return inNode{field, values}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenOpen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenClose, ")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+
+	case tokenString:
+		return literalNode{tokenString, t.text, t.text}, nil
+
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return literalNode{tokenNumber, value, t.text}, nil
+
+	case tokenTime:
+		value, err := parseTime(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q at position %d", t.text, t.pos)
+		}
+		return literalNode{tokenTime, value, t.text}, nil
+
+	case tokenName:
+		switch t.text {
+		case "true", "false":
+			return literalNode{tokenName, t.text == "true", t.text}, nil
+		case "null":
+			return literalNode{tokenName, nil, t.text}, nil
+		}
+
+		if p.peek().kind == tokenOpen {
+			p.next()
+			var args []node
+			for p.peek().kind != tokenClose {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.peek().kind != tokenComma {
+					break
+				}
+				p.next()
+			}
+			if err := p.expect(tokenClose, ")"); err != nil {
+				return nil, err
+			}
+			return callNode{t.text, args}, nil
+		}
+
+		return fieldNode{t.text, t.pos}, nil
+	}
+
+	if t.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of $filter")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+func parseTime(text string) (time.Time, error) {
+	if value, err := time.Parse(time.RFC3339, text); err == nil {
+		return value, nil
+	}
+	return time.Parse("2006-01-02", text)
+}
@@ -0,0 +1,56 @@
+package odata
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// EntitySet describes one collection of the service for $metadata
+type EntitySet struct {
+	Name   string
+	Key    string
+	Fields []Field
+}
+
+// Metadata returns the CSDL document describing the entity sets, all in one
+// schema with an entity type named after each set
+func Metadata(namespace string, sets []EntitySet) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<edmx:Edmx xmlns:edmx="http://docs.oasis-open.org/odata/ns/edmx" Version="4.0">`)
+	buf.WriteString(`<edmx:DataServices>`)
+	fmt.Fprintf(&buf, `<Schema xmlns="http://docs.oasis-open.org/odata/ns/edm" Namespace="%s">`, escape(namespace))
+
+	for _, set := range sets {
+		fmt.Fprintf(&buf, `<EntityType Name="%s">`, escape(set.Name))
+		fmt.Fprintf(&buf, `<Key><PropertyRef Name="%s"/></Key>`, escape(set.Key))
+		for _, field := range set.Fields {
+			edmType := field.Type.EdmType()
+			if field.Collection {
+				edmType = "Collection(" + edmType + ")"
+			}
+			nullable := ""
+			if !field.Nullable {
+				nullable = ` Nullable="false"`
+			}
+			fmt.Fprintf(&buf, `<Property Name="%s" Type="%s"%s/>`, escape(field.Name), edmType, nullable)
+		}
+		buf.WriteString(`</EntityType>`)
+	}
+
+	buf.WriteString(`<EntityContainer Name="Default">`)
+	for _, set := range sets {
+		fmt.Fprintf(&buf, `<EntitySet Name="%s" EntityType="%s.%s"/>`, escape(set.Name), escape(namespace), escape(set.Name))
+	}
+	buf.WriteString(`</EntityContainer>`)
+
+	buf.WriteString(`</Schema></edmx:DataServices></edmx:Edmx>`)
+	return buf.Bytes()
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
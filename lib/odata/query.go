@@ -0,0 +1,181 @@
+// Package odata implements the read-only subset of OData v4 that listing
+// consumers use: $filter, $select, $orderby, $top, $skip, $count and $metadata.
+// Queries are translated into SQL conditions for a fixed set of fields.
+package odata
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeInt
+	TypeDecimal
+	TypeBool
+	TypeDateTime
+)
+
+// EdmType is the name of the type in $metadata
+func (t Type) EdmType() string {
+	switch t {
+	case TypeInt:
+		return "Edm.Int64"
+	case TypeDecimal:
+		return "Edm.Decimal"
+	case TypeBool:
+		return "Edm.Boolean"
+	case TypeDateTime:
+		return "Edm.DateTimeOffset"
+	}
+	return "Edm.String"
+}
+
+// Field is one property of an entity
+type Field struct {
+	Name string
+	Type Type
+	// Collection marks a list of values of Type
+	Collection bool
+	// Column is the trusted SQL expression the field is filtered and sorted on.
+	// Fields without one can only be selected.
+	Column string
+	// Enum maps the values a string field may be compared with to what is stored
+	Enum map[string]string
+	// Nullable marks fields that can be null; the key never is
+	Nullable bool
+	// Compare, when set, builds the condition for comparing the field with a
+	// value instead of Column, for values that need converting first. op is
+	// the SQL operator and value is never null.
+	Compare func(op string, value interface{}) (string, []interface{}, error)
+}
+
+// Query is a parsed request. Where and Args are a condition for gorm's Where,
+// OrderBy a list of sort expressions; both only contain column expressions of
+// the fields.
+type Query struct {
+	Where   string
+	Args    []interface{}
+	OrderBy []string
+	// Select holds the requested fields in order, or every field
+	Select []string
+	Top    int
+	Skip   int
+	Count  bool
+}
+
+// Parse reads the query options of a collection request. Unknown $ options
+// are rejected so a client never silently gets more than it asked for.
+func Parse(values url.Values, fields []Field, defaultTop, maxTop int) (*Query, error) {
+	byName := make(map[string]Field, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	for option := range values {
+		switch option {
+		case "$filter", "$select", "$orderby", "$top", "$skip", "$count", "$format":
+		default:
+			if strings.HasPrefix(option, "$") {
+				return nil, fmt.Errorf("unsupported query option %s", option)
+			}
+		}
+	}
+
+	query := &Query{Top: defaultTop}
+
+	if filter := strings.TrimSpace(values.Get("$filter")); filter != "" {
+		expr, err := parseFilter(filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $filter: %v", err)
+		}
+
+		t := &translator{fields: byName}
+		where, err := t.translate(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $filter: %v", err)
+		}
+		query.Where, query.Args = where, t.args
+	}
+
+	if selected := strings.TrimSpace(values.Get("$select")); selected != "" && selected != "*" {
+		for _, name := range strings.Split(selected, ",") {
+			name = strings.TrimSpace(name)
+			if _, ok := byName[name]; !ok {
+				return nil, fmt.Errorf("invalid $select: unknown field %q", name)
+			}
+			query.Select = append(query.Select, name)
+		}
+	} else {
+		for _, field := range fields {
+			query.Select = append(query.Select, field.Name)
+		}
+	}
+
+	if orderBy := strings.TrimSpace(values.Get("$orderby")); orderBy != "" {
+		t := &translator{fields: byName}
+		for _, item := range strings.Split(orderBy, ",") {
+			parts := strings.Fields(item)
+			if len(parts) == 0 || len(parts) > 2 {
+				return nil, fmt.Errorf("invalid $orderby %q", item)
+			}
+
+			field, err := t.field(fieldNode{name: parts[0]})
+			if err != nil {
+				return nil, fmt.Errorf("invalid $orderby: %v", err)
+			}
+			if field.Collection {
+				return nil, fmt.Errorf("invalid $orderby: %s is a list", field.Name)
+			}
+
+			direction := "ASC"
+			if len(parts) == 2 {
+				switch parts[1] {
+				case "asc":
+				case "desc":
+					direction = "DESC"
+				default:
+					return nil, fmt.Errorf("invalid $orderby direction %q", parts[1])
+				}
+			}
+			query.OrderBy = append(query.OrderBy, field.Column+" "+direction)
+		}
+	}
+
+	if top := values.Get("$top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("$top must be a whole number of 0 or more")
+		}
+		if n > maxTop {
+			return nil, fmt.Errorf("$top can be at most %d", maxTop)
+		}
+		query.Top = n
+	}
+
+	if skip := values.Get("$skip"); skip != "" {
+		n, err := strconv.Atoi(skip)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("$skip must be a whole number of 0 or more")
+		}
+		query.Skip = n
+	}
+
+	switch values.Get("$count") {
+	case "", "false":
+	case "true":
+		query.Count = true
+	default:
+		return nil, fmt.Errorf("$count must be true or false")
+	}
+
+	if format := values.Get("$format"); format != "" && format != "json" && !strings.HasPrefix(format, "application/json") {
+		return nil, fmt.Errorf("only the json $format is supported")
+	}
+
+	return query, nil
+}
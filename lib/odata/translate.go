@@ -0,0 +1,245 @@
+package odata
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// translator turns a parsed $filter into a SQL condition. Field names only
+// ever become the column expressions of their Field definitions and values
+// only ever become bound arguments, so nothing from the request reaches the
+// SQL text.
+type translator struct {
+	fields map[string]Field
+	args   []interface{}
+}
+
+func (t *translator) field(f fieldNode) (Field, error) {
+	field, ok := t.fields[f.name]
+	if !ok {
+		return Field{}, fmt.Errorf("unknown field %q", f.name)
+	}
+	if field.Column == "" {
+		return Field{}, fmt.Errorf("field %s cannot be filtered or sorted on", f.name)
+	}
+	return field, nil
+}
+
+func (t *translator) translate(n node) (string, error) {
+	switch n := n.(type) {
+	case logicalNode:
+		left, err := t.translate(n.left)
+		if err != nil {
+			return "", err
+		}
+		right, err := t.translate(n.right)
+		if err != nil {
+			return "", err
+		}
+		return "(" + left + " " + n.op + " " + right + ")", nil
+
+	case notNode:
+		operand, err := t.translate(n.operand)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + operand + ")", nil
+
+	case compareNode:
+		return t.compare(n)
+
+	case inNode:
+		field, err := t.field(n.field)
+		if err != nil {
+			return "", err
+		}
+		var values []interface{}
+		var conditions []string
+		for _, literal := range n.values {
+			value, err := fieldValue(field, literal)
+			if err != nil {
+				return "", err
+			}
+			if value == nil {
+				return "", fmt.Errorf("null cannot be used with in")
+			}
+			if field.Compare != nil {
+				condition, args, err := field.Compare("=", value)
+				if err != nil {
+					return "", err
+				}
+				conditions = append(conditions, condition)
+				t.args = append(t.args, args...)
+				continue
+			}
+			values = append(values, value)
+		}
+		if field.Compare != nil {
+			return "(" + strings.Join(conditions, " OR ") + ")", nil
+		}
+		t.args = append(t.args, values)
+		return field.Column + " IN ?", nil
+
+	case callNode:
+		return t.call(n)
+
+	case fieldNode:
+		// A yes/no field on its own is true when set
+		field, err := t.field(n)
+		if err != nil {
+			return "", err
+		}
+		if field.Type != TypeBool {
+			return "", fmt.Errorf("%s is not a yes/no field, compare it to a value", n.name)
+		}
+		return field.Column + " = TRUE", nil
+	}
+
+	return "", fmt.Errorf("expected a condition")
+}
+
+// flipped is the operator to use when the value comes before the field
+var flipped = map[string]string{"eq": "eq", "ne": "ne", "gt": "lt", "ge": "le", "lt": "gt", "le": "ge"}
+
+func (t *translator) compare(n compareNode) (string, error) {
+	op := n.op
+	left, leftIsField := n.left.(fieldNode)
+	literal, rightIsLiteral := n.right.(literalNode)
+
+	if !leftIsField || !rightIsLiteral {
+		right, rightIsField := n.right.(fieldNode)
+		value, leftIsLiteral := n.left.(literalNode)
+		if !rightIsField || !leftIsLiteral {
+			return "", fmt.Errorf("comparisons need a field on one side and a value on the other")
+		}
+		left, literal, op = right, value, flipped[op]
+	}
+
+	field, err := t.field(left)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := fieldValue(field, literal)
+	if err != nil {
+		return "", err
+	}
+
+	if value == nil {
+		switch op {
+		case "eq":
+			return field.Column + " IS NULL", nil
+		case "ne":
+			return field.Column + " IS NOT NULL", nil
+		}
+		return "", fmt.Errorf("null can only be compared with eq or ne")
+	}
+
+	if field.Type == TypeBool && op != "eq" && op != "ne" {
+		return "", fmt.Errorf("%s can only be compared with eq or ne", field.Name)
+	}
+
+	if field.Compare != nil {
+		condition, args, err := field.Compare(comparisonOperators[op], value)
+		if err != nil {
+			return "", err
+		}
+		t.args = append(t.args, args...)
+		return condition, nil
+	}
+
+	t.args = append(t.args, value)
+	return field.Column + " " + comparisonOperators[op] + " ?", nil
+}
+
+// likePatterns are the string functions, as LIKE patterns around the value
+var likePatterns = map[string][2]string{
+	"contains":   {"%", "%"},
+	"startswith": {"", "%"},
+	"endswith":   {"%", ""},
+}
+
+func (t *translator) call(n callNode) (string, error) {
+	pattern, ok := likePatterns[n.name]
+	if !ok {
+		return "", fmt.Errorf("unsupported function %s", n.name)
+	}
+	if len(n.args) != 2 {
+		return "", fmt.Errorf("%s takes a field and a string", n.name)
+	}
+
+	f, isField := n.args[0].(fieldNode)
+	literal, isLiteral := n.args[1].(literalNode)
+	if !isField || !isLiteral || literal.kind != tokenString {
+		return "", fmt.Errorf("%s takes a field and a string", n.name)
+	}
+
+	field, err := t.field(f)
+	if err != nil {
+		return "", err
+	}
+	if field.Type != TypeString || field.Enum != nil {
+		return "", fmt.Errorf("%s only works on text fields", n.name)
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(literal.value.(string))
+	t.args = append(t.args, pattern[0]+escaped+pattern[1])
+	return field.Column + ` LIKE ? ESCAPE '\'`, nil
+}
+
+// fieldValue checks a literal against the field type and returns the value to bind
+func fieldValue(field Field, literal literalNode) (interface{}, error) {
+	if literal.value == nil {
+		return nil, nil
+	}
+
+	mismatch := fmt.Errorf("%s is not a valid value for %s", literal.text, field.Name)
+
+	switch field.Type {
+	case TypeString:
+		value, ok := literal.value.(string)
+		if !ok || literal.kind != tokenString {
+			return nil, mismatch
+		}
+		if field.Enum != nil {
+			stored, ok := field.Enum[value]
+			if !ok {
+				return nil, mismatch
+			}
+			return stored, nil
+		}
+		return value, nil
+
+	case TypeInt:
+		value, ok := literal.value.(float64)
+		if !ok || value != math.Trunc(value) {
+			return nil, mismatch
+		}
+		return int64(value), nil
+
+	case TypeDecimal:
+		value, ok := literal.value.(float64)
+		if !ok {
+			return nil, mismatch
+		}
+		return value, nil
+
+	case TypeBool:
+		value, ok := literal.value.(bool)
+		if !ok {
+			return nil, mismatch
+		}
+		return value, nil
+
+	case TypeDateTime:
+		value, ok := literal.value.(time.Time)
+		if !ok {
+			return nil, mismatch
+		}
+		return value, nil
+	}
+
+	return nil, mismatch
+}
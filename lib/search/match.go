@@ -0,0 +1,164 @@
+package search
+
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/farhapartex/real_estate_be/utils"
+)
+
+// Converter converts an amount from one currency to another, reporting false
+// when either has no exchange rate
+type Converter func(amount money.Amount, from, to string) (money.Amount, bool)
+
+// Matches reports whether a live property satisfies the criteria, applying the
+// same rules as the property search query without a database. Price bounds are
+// converted to the listing currency with convert, as is the maximum deposit;
+// rental terms are read from property.RentalTerms. Criteria with an unparsable
+// location or date never match.
+func Matches(criteria models.SearchCriteria, property models.Property, convert Converter) bool {
+	if !property.Status.IsSearchable() {
+		return false
+	}
+
+	if criteria.Purpose != "" && string(property.Purpose) != criteria.Purpose {
+		return false
+	}
+
+	if criteria.PropertyType != "" && property.PropertyType != criteria.PropertyType {
+		return false
+	}
+
+	if criteria.MinPrice > 0 {
+		bound, ok := convert(criteria.MinPrice, criteria.Currency, property.Currency)
+		if !ok || property.Price < bound {
+			return false
+		}
+	}
+
+	if criteria.MaxPrice > 0 {
+		bound, ok := convert(criteria.MaxPrice, criteria.Currency, property.Currency)
+		if !ok || property.Price > bound {
+			return false
+		}
+	}
+
+	if criteria.BedRooms > 0 && property.Bedrooms < criteria.BedRooms {
+		return false
+	}
+
+	if criteria.BathRooms > 0 && property.Bathrooms < criteria.BathRooms {
+		return false
+	}
+
+	if criteria.MinSize > 0 && property.Size < criteria.MinSize {
+		return false
+	}
+
+	if criteria.MaxSize > 0 && property.Size > criteria.MaxSize {
+		return false
+	}
+
+	if criteria.CountryID > 0 && property.CountryID != criteria.CountryID {
+		return false
+	}
+
+	if criteria.DivisionID > 0 && property.DivisionID != criteria.DivisionID {
+		return false
+	}
+
+	if criteria.DistrictID > 0 && property.DistrictID != criteria.DistrictID {
+		return false
+	}
+
+	if !matchesRentalTerms(criteria, property, convert) {
+		return false
+	}
+
+	return matchesLocation(criteria, property)
+}
+
+// matchesRentalTerms mirrors the rental terms filter of the search: any of
+// them needs a listing with terms
+func matchesRentalTerms(criteria models.SearchCriteria, property models.Property, convert Converter) bool {
+	if criteria.RentPeriod == "" && criteria.TenantPreference == "" && criteria.AvailableBefore == "" &&
+		criteria.MaxDeposit == 0 && criteria.MaxAdvanceMonths == 0 && criteria.LeaseMonths == 0 {
+		return true
+	}
+
+	terms := property.RentalTerms
+	if terms == nil {
+		return false
+	}
+
+	if criteria.RentPeriod != "" && string(terms.RentPeriod) != criteria.RentPeriod {
+		return false
+	}
+
+	// Listings open to any tenant match both preferences
+	if criteria.TenantPreference != "" && string(terms.TenantPreference) != criteria.TenantPreference &&
+		terms.TenantPreference != models.TenantAny {
+		return false
+	}
+
+	if criteria.AvailableBefore != "" {
+		availableBefore, err := time.Parse("2006-01-02", criteria.AvailableBefore)
+		if err != nil {
+			return false
+		}
+		if terms.AvailableFrom != nil && terms.AvailableFrom.After(availableBefore) {
+			return false
+		}
+	}
+
+	if criteria.MaxAdvanceMonths > 0 && terms.AdvanceMonths > criteria.MaxAdvanceMonths {
+		return false
+	}
+
+	if criteria.LeaseMonths > 0 && terms.MinLeaseMonths > criteria.LeaseMonths {
+		return false
+	}
+
+	if criteria.MaxDeposit > 0 {
+		bound, ok := convert(criteria.MaxDeposit, criteria.Currency, property.Currency)
+		if !ok || terms.SecurityDeposit > bound {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesLocation(criteria models.SearchCriteria, property models.Property) bool {
+	if criteria.BBox == "" && criteria.Near == "" {
+		return true
+	}
+
+	if property.Latitude == nil || property.Longitude == nil {
+		return false
+	}
+	point := utils.LatLng{Lat: *property.Latitude, Lng: *property.Longitude}
+
+	if criteria.BBox != "" {
+		box, err := utils.ParseBoundingBox(criteria.BBox)
+		if err != nil {
+			return false
+		}
+		if point.Lat < box.MinLat || point.Lat > box.MaxLat || point.Lng < box.MinLng || point.Lng > box.MaxLng {
+			return false
+		}
+	}
+
+	if criteria.Near != "" {
+		center, err := utils.ParseLatLng(criteria.Near)
+		if err != nil {
+			return false
+		}
+		if criteria.RadiusKm > 0 && utils.HaversineKm(center, point) > criteria.RadiusKm {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+// convertAt converts through BDT, the base currency, like the exchange rates in
+// effect do. Currencies without a rate do not convert.
+func convertAt(t *testing.T, rates map[string]string) Converter {
+	toBase := map[string]money.Rate{"BDT": money.One()}
+	for currency, text := range rates {
+		rate, err := money.ParseRate(text)
+		if err != nil {
+			t.Fatalf("ParseRate(%q): %v", text, err)
+		}
+		toBase[currency] = rate
+	}
+
+	return func(amount money.Amount, from, to string) (money.Amount, bool) {
+		fromRate, ok := toBase[from]
+		if !ok {
+			return 0, false
+		}
+		toRate, ok := toBase[to]
+		if !ok {
+			return 0, false
+		}
+		return amount.Convert(fromRate.Quo(toRate)), true
+	}
+}
+
+func amount(t *testing.T, text string) money.Amount {
+	parsed, err := money.Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", text, err)
+	}
+	return parsed
+}
+
+func TestMatches(t *testing.T) {
+	convert := convertAt(t, map[string]string{"USD": "110"})
+
+	latitude, longitude := 23.8103, 90.4125
+	listing := models.Property{
+		Status:       models.StatusActive,
+		Purpose:      "sale",
+		PropertyType: "apartment",
+		Price:        amount(t, "5500000"),
+		Currency:     "BDT",
+		Bedrooms:     3,
+		Bathrooms:    2,
+		Size:         1400,
+		CountryID:    1,
+		DivisionID:   3,
+		DistrictID:   7,
+		Latitude:     &latitude,
+		Longitude:    &longitude,
+	}
+
+	withoutLocation := listing
+	withoutLocation.Latitude, withoutLocation.Longitude = nil, nil
+
+	inEuros := listing
+	inEuros.Currency = "EUR"
+
+	draft := listing
+	draft.Status = models.StatusDraft
+
+	moveIn := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	forRent := listing
+	forRent.Purpose = "rent"
+	forRent.Price = amount(t, "45000")
+	forRent.RentalTerms = &models.PropertyRentalTerms{
+		RentPeriod:       models.RentPeriodMonthly,
+		SecurityDeposit:  amount(t, "110000"),
+		AdvanceMonths:    2,
+		MinLeaseMonths:   12,
+		AvailableFrom:    &moveIn,
+		TenantPreference: models.TenantFamily,
+	}
+
+	openToAll := forRent
+	openToAll.RentalTerms = &models.PropertyRentalTerms{
+		RentPeriod:       models.RentPeriodMonthly,
+		TenantPreference: models.TenantAny,
+	}
+
+	tests := []struct {
+		name     string
+		criteria models.SearchCriteria
+		property models.Property
+		want     bool
+	}{
+		{"no criteria", models.SearchCriteria{}, listing, true},
+		{"not searchable", models.SearchCriteria{}, draft, false},
+		{"purpose", models.SearchCriteria{Purpose: "rent"}, listing, false},
+
+		{"min price in listing currency", models.SearchCriteria{MinPrice: amount(t, "5500000"), Currency: "BDT"}, listing, true},
+		{"max price in listing currency", models.SearchCriteria{MaxPrice: amount(t, "5499999.99"), Currency: "BDT"}, listing, false},
+		{"min price converted at the bound", models.SearchCriteria{MinPrice: amount(t, "50000"), Currency: "USD"}, listing, true},
+		{"min price converted above", models.SearchCriteria{MinPrice: amount(t, "50000.01"), Currency: "USD"}, listing, false},
+		{"max price converted at the bound", models.SearchCriteria{MaxPrice: amount(t, "50000"), Currency: "USD"}, listing, true},
+		{"max price converted below", models.SearchCriteria{MaxPrice: amount(t, "49999.99"), Currency: "USD"}, listing, false},
+		{"criteria currency without a rate", models.SearchCriteria{MinPrice: amount(t, "1"), Currency: "EUR"}, listing, false},
+		{"listing currency without a rate", models.SearchCriteria{MaxPrice: amount(t, "100000"), Currency: "USD"}, inEuros, false},
+		{"no price bounds, no rate needed", models.SearchCriteria{Currency: "EUR"}, inEuros, true},
+
+		{"bedrooms minimum met", models.SearchCriteria{BedRooms: 3}, listing, true},
+		{"bedrooms minimum missed", models.SearchCriteria{BedRooms: 4}, listing, false},
+		{"bathrooms minimum met", models.SearchCriteria{BathRooms: 2}, listing, true},
+		{"bathrooms minimum missed", models.SearchCriteria{BathRooms: 3}, listing, false},
+
+		{"district", models.SearchCriteria{DistrictID: 7}, listing, true},
+		{"other district", models.SearchCriteria{DistrictID: 8}, listing, false},
+
+		{"near within radius", models.SearchCriteria{Near: "23.78,90.40", RadiusKm: 5}, listing, true},
+		{"near outside radius", models.SearchCriteria{Near: "22.3569,91.7832", RadiusKm: 10}, listing, false},
+		{"near without radius", models.SearchCriteria{Near: "22.3569,91.7832"}, listing, true},
+		{"near without coordinates", models.SearchCriteria{Near: "23.78,90.40", RadiusKm: 5}, withoutLocation, false},
+		{"unparsable near", models.SearchCriteria{Near: "dhaka", RadiusKm: 5}, listing, false},
+		{"inside bbox", models.SearchCriteria{BBox: "90.3,23.7,90.5,23.9"}, listing, true},
+		{"outside bbox", models.SearchCriteria{BBox: "91.7,22.3,91.9,22.5"}, listing, false},
+		{"unparsable bbox", models.SearchCriteria{BBox: "90.3,23.7"}, listing, false},
+
+		{"rental criteria on a listing without terms", models.SearchCriteria{RentPeriod: "monthly"}, listing, false},
+		{"rent period", models.SearchCriteria{RentPeriod: "monthly"}, forRent, true},
+		{"other rent period", models.SearchCriteria{RentPeriod: "yearly"}, forRent, false},
+		{"tenant preference", models.SearchCriteria{TenantPreference: "family"}, forRent, true},
+		{"other tenant preference", models.SearchCriteria{TenantPreference: "bachelor"}, forRent, false},
+		{"listing open to any tenant", models.SearchCriteria{TenantPreference: "bachelor"}, openToAll, true},
+		{"available on the date", models.SearchCriteria{AvailableBefore: "2026-11-01"}, forRent, true},
+		{"available too late", models.SearchCriteria{AvailableBefore: "2026-10-31"}, forRent, false},
+		{"available right away", models.SearchCriteria{AvailableBefore: "2026-10-31"}, openToAll, true},
+		{"unparsable available_before", models.SearchCriteria{AvailableBefore: "next month"}, forRent, false},
+		{"advance months within the maximum", models.SearchCriteria{MaxAdvanceMonths: 2}, forRent, true},
+		{"advance months above the maximum", models.SearchCriteria{MaxAdvanceMonths: 1}, forRent, false},
+		{"lease long enough", models.SearchCriteria{LeaseMonths: 12}, forRent, true},
+		{"lease too short", models.SearchCriteria{LeaseMonths: 6}, forRent, false},
+		{"max deposit converted at the bound", models.SearchCriteria{MaxDeposit: amount(t, "1000"), Currency: "USD"}, forRent, true},
+		{"max deposit converted below", models.SearchCriteria{MaxDeposit: amount(t, "999.99"), Currency: "USD"}, forRent, false},
+		{"max deposit without a rate", models.SearchCriteria{MaxDeposit: amount(t, "1000"), Currency: "EUR"}, forRent, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Matches(test.criteria, test.property, convert); got != test.want {
+				t.Errorf("Matches() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
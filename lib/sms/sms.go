@@ -0,0 +1,70 @@
+package sms
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Sender delivers text messages. Phone numbers are expected in E.164 format.
+type Sender interface {
+	Send(to, body string) error
+}
+
+// LogSender only logs messages. It is used when no SMS provider is configured,
+// so the channel can be tried out without sending anything.
+type LogSender struct{}
+
+func (LogSender) Send(to, body string) error {
+	log.Printf("SMS not sent to %s (no provider configured): %s", to, body)
+	return nil
+}
+
+// TwilioSender sends messages through the Twilio REST API
+type TwilioSender struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+	client     *http.Client
+}
+
+func NewTwilioSender(accountSID, authToken, from string) *TwilioSender {
+	return &TwilioSender{
+		AccountSID: accountSID,
+		AuthToken:  authToken,
+		From:       from,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *TwilioSender) Send(to, body string) error {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", s.From)
+	form.Set("Body", body)
+
+	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", s.AccountSID)
+	request, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(s.AccountSID, s.AuthToken)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		details, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("error sending sms, status code: %d, body: %s", response.StatusCode, details)
+	}
+
+	return nil
+}
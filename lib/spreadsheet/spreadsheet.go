@@ -0,0 +1,56 @@
+// Package spreadsheet reads uploaded CSV and XLSX files as rows of text cells
+// and writes exports in the same formats
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"path"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format, upload a CSV or XLSX file")
+
+// Format returns the format of a file by its extension, or "" if it is not supported
+func Format(fileName string) string {
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV
+	case ".xlsx":
+		return FormatXLSX
+	}
+	return ""
+}
+
+// Read returns the rows of a CSV file or of the first sheet of an XLSX file.
+// Rows can have different lengths; missing trailing cells are simply absent.
+func Read(format string, data []byte) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(data)
+	case FormatXLSX:
+		return readXLSX(data)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	// Excel writes a byte order mark in front of UTF-8 CSV files
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.New("invalid CSV file: " + err.Error())
+	}
+	return rows, nil
+}
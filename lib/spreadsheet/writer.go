@@ -0,0 +1,279 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/flate"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Writer writes rows of a CSV file or of a single sheet XLSX file as they come,
+// without keeping earlier rows in memory
+type Writer interface {
+	// WriteRow writes one row. Cells can be strings, numbers, bools, times or nil.
+	WriteRow(cells []interface{}) error
+	// Flush sends what has been written so far on to the destination
+	Flush() error
+	// Close finishes the file; nothing can be written after it
+	Close() error
+}
+
+// ContentType is the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter returns a writer of the format that writes to w. When w can be
+// flushed (an HTTP response), Flush flushes it too.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type flusher interface {
+	Flush()
+}
+
+func flushDestination(w io.Writer) {
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+}
+
+// cellText formats a cell the way both formats show it
+func cellText(cell interface{}) (text string, numeric bool) {
+	switch v := cell.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, false
+	case bool:
+		return strconv.FormatBool(v), false
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05"), false
+	}
+	return fmt.Sprint(cell), false
+}
+
+type csvWriter struct {
+	dest   io.Writer
+	buf    *bufio.Writer
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	buf := bufio.NewWriter(w)
+	// The byte order mark makes Excel read the file as UTF-8
+	if _, err := buf.WriteString("\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	return &csvWriter{dest: w, buf: buf, writer: csv.NewWriter(buf)}, nil
+}
+
+func (w *csvWriter) WriteRow(cells []interface{}) error {
+	w.record = w.record[:0]
+	for _, cell := range cells {
+		text, numeric := cellText(cell)
+		if !numeric {
+			text = escapeFormula(text)
+		}
+		w.record = append(w.record, text)
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return err
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	flushDestination(w.dest)
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	return w.Flush()
+}
+
+// escapeFormula keeps spreadsheet programs from running text that starts like
+// a formula, e.g. a listing title of "=HYPERLINK(...)"
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter streams the sheet into the archive with inline strings, so unlike
+// shared strings nothing has to be collected before the sheet is written. The
+// other parts are small and follow the sheet when the file is closed.
+type xlsxWriter struct {
+	dest    io.Writer
+	archive *zip.Writer
+	deflate *flate.Writer
+	sheet   *bufio.Writer
+	row     int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	x := &xlsxWriter{dest: w, archive: zip.NewWriter(w)}
+
+	// Keep hold of the compressor so Flush can push out what it has buffered
+	x.archive.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		fw, err := flate.NewWriter(out, flate.DefaultCompression)
+		x.deflate = fw
+		return fw, err
+	})
+
+	part, err := x.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x.sheet = bufio.NewWriter(part)
+	if _, err := x.sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) WriteRow(cells []interface{}) error {
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+
+	for i, cell := range cells {
+		text, numeric := cellText(cell)
+		if text == "" {
+			continue
+		}
+
+		ref := columnName(i) + strconv.Itoa(x.row)
+		if numeric {
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%s</v></c>`, ref, text)
+			continue
+		}
+
+		fmt.Fprintf(x.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		if err := xml.EscapeText(x.sheet, []byte(text)); err != nil {
+			return err
+		}
+		x.sheet.WriteString(`</t></is></c>`)
+	}
+
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxWriter) Flush() error {
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	if x.deflate != nil {
+		if err := x.deflate.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := x.archive.Flush(); err != nil {
+		return err
+	}
+	flushDestination(x.dest)
+	return nil
+}
+
+func (x *xlsxWriter) Close() error {
+	if _, err := x.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbookXML},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, p := range parts {
+		part, err := x.archive.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(part, p.content); err != nil {
+			return err
+		}
+	}
+
+	if err := x.archive.Close(); err != nil {
+		return err
+	}
+	flushDestination(x.dest)
+	return nil
+}
+
+// columnName turns a zero based column index into its letters: 0 is A, 26 is AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
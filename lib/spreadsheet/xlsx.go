@@ -0,0 +1,196 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize caps how much of a single file inside the XLSX archive is
+// inflated, so a small upload cannot expand into gigabytes
+const maxPartSize = 64 << 20
+
+var errInvalidXLSX = errors.New("invalid XLSX file")
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is rich or plain text, used by shared strings and inline strings
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+
+	var text strings.Builder
+	for _, run := range t.Runs {
+		text.WriteString(run.T)
+	}
+	return text.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errInvalidXLSX
+	}
+
+	sheetPath, err := firstSheetPath(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if err := readXMLPart(archive, "xl/sharedStrings.xml", &shared); err != nil && !errors.Is(err, errMissingPart) {
+		return nil, err
+	}
+
+	var sheet xlsxSheet
+	if err := readXMLPart(archive, sheetPath, &sheet); err != nil {
+		return nil, errInvalidXLSX
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		cells := []string{}
+		for _, cell := range row.Cells {
+			column := len(cells)
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, errInvalidXLSX
+				}
+			}
+
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, errInvalidXLSX
+				}
+				cells[column] = shared.Items[index].String()
+			case "inlineStr":
+				cells[column] = cell.Inline.String()
+			case "b":
+				cells[column] = strconv.FormatBool(cell.Value == "1")
+			default:
+				cells[column] = cell.Value
+			}
+		}
+		rows = append(rows, cells)
+	}
+
+	return rows, nil
+}
+
+// firstSheetPath finds the file of the first sheet through the workbook relationships
+func firstSheetPath(archive *zip.Reader) (string, error) {
+	var workbook xlsxWorkbook
+	if err := readXMLPart(archive, "xl/workbook.xml", &workbook); err != nil || len(workbook.Sheets) == 0 {
+		return "", errInvalidXLSX
+	}
+
+	var relationships xlsxRelationships
+	if err := readXMLPart(archive, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return "", errInvalidXLSX
+	}
+
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID != workbook.Sheets[0].RelationID {
+			continue
+		}
+
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/"), nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+
+	return "", errInvalidXLSX
+}
+
+var errMissingPart = errors.New("missing part")
+
+func readXMLPart(archive *zip.Reader, name string, v interface{}) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return errInvalidXLSX
+		}
+		defer reader.Close()
+
+		content, err := io.ReadAll(io.LimitReader(reader, maxPartSize+1))
+		if err != nil || len(content) > maxPartSize {
+			return errInvalidXLSX
+		}
+
+		if err := xml.Unmarshal(content, v); err != nil {
+			return errInvalidXLSX
+		}
+		return nil
+	}
+
+	return errMissingPart
+}
+
+// columnIndex turns the letters of a cell reference such as "AB12" into a
+// zero based column index
+func columnIndex(ref string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+
+	if letters == 0 || letters > 3 {
+		return 0, errInvalidXLSX
+	}
+	return index - 1, nil
+}
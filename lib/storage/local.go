@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	LocalUploadPath   = "/storage/upload/"
+	LocalDownloadPath = "/storage/files/"
+
+	// public URLs are signed for a fixed weekly window so they stay cacheable
+	localPublicURLWindow = 7 * 24 * time.Hour
+	localMetaDir         = ".meta"
+)
+
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// LocalStorage keeps files on disk for development and CI. It signs its own URLs
+// with HMAC and the Gin router serves them (see views.LocalStorageUpload).
+type LocalStorage struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+type localMeta struct {
+	ContentType string `json:"content_type"`
+}
+
+func NewLocalStorage(root string, baseURL string, secret []byte) *LocalStorage {
+	return &LocalStorage{
+		root:    root,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
+	}
+}
+
+func (l *LocalStorage) PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error) {
+	if _, err := l.filePath(key); err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(expires).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	query.Set("content_type", contentType)
+	query.Set("size", strconv.FormatInt(size, 10))
+	query.Set("signature", l.sign("PUT", key, expiresAt, contentType, size))
+
+	return l.baseURL + LocalUploadPath + key + "?" + query.Encode(), nil
+}
+
+func (l *LocalStorage) PresignDownload(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if _, err := l.filePath(key); err != nil {
+		return "", err
+	}
+
+	return l.downloadURL(key, time.Now().Add(expires).Unix()), nil
+}
+
+func (l *LocalStorage) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	filePath, err := l.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New("failed to read file info")
+	}
+
+	info := &ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		LastModified: stat.ModTime(),
+	}
+
+	if metaBytes, err := os.ReadFile(l.metaPath(key)); err == nil {
+		var meta localMeta
+		if json.Unmarshal(metaBytes, &meta) == nil {
+			info.ContentType = meta.ContentType
+		}
+	}
+
+	return info, nil
+}
+
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := l.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return errors.New("failed to delete file")
+	}
+	os.Remove(l.metaPath(key))
+
+	return nil
+}
+
+func (l *LocalStorage) Copy(ctx context.Context, sourceKey string, destinationKey string) error {
+	info, err := l.Head(ctx, sourceKey)
+	if err != nil {
+		return err
+	}
+
+	source, err := l.Open(sourceKey)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	return l.Write(destinationKey, info.ContentType, source)
+}
+
+func (l *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return l.Open(key)
+}
+
+func (l *LocalStorage) Put(ctx context.Context, key string, contentType string, body io.Reader, size int64) error {
+	return l.Write(key, contentType, io.LimitReader(body, size))
+}
+
+func (l *LocalStorage) PublicURL(key string) string {
+	window := int64(localPublicURLWindow / time.Second)
+	expiresAt := (time.Now().Unix()/window + 2) * window
+
+	return l.downloadURL(key, expiresAt)
+}
+
+// VerifyUpload checks an upload URL and returns the content type and exact size it was signed for
+func (l *LocalStorage) VerifyUpload(key string, query url.Values) (string, int64, error) {
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return "", 0, ErrInvalidSignature
+	}
+
+	size, err := strconv.ParseInt(query.Get("size"), 10, 64)
+	if err != nil {
+		return "", 0, ErrInvalidSignature
+	}
+
+	contentType := query.Get("content_type")
+	expected := l.sign("PUT", key, expiresAt, contentType, size)
+	if !l.validSignature(expected, query.Get("signature"), expiresAt) {
+		return "", 0, ErrInvalidSignature
+	}
+
+	return contentType, size, nil
+}
+
+// VerifyDownload checks a download URL
+func (l *LocalStorage) VerifyDownload(key string, query url.Values) error {
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	expected := l.sign("GET", key, expiresAt, "", 0)
+	if !l.validSignature(expected, query.Get("signature"), expiresAt) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Write stores a file and its content type
+func (l *LocalStorage) Write(key string, contentType string, body io.Reader) error {
+	filePath, err := l.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return errors.New("failed to store file")
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return errors.New("failed to store file")
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, body); err != nil {
+		tmpFile.Close()
+		return errors.New("failed to store file")
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return errors.New("failed to store file")
+	}
+
+	if err := os.Rename(tmpFile.Name(), filePath); err != nil {
+		return errors.New("failed to store file")
+	}
+
+	metaPath := l.metaPath(key)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0o755); err != nil {
+		return errors.New("failed to store file")
+	}
+
+	metaBytes, _ := json.Marshal(localMeta{ContentType: contentType})
+	if err := os.WriteFile(metaPath, metaBytes, 0o644); err != nil {
+		return errors.New("failed to store file")
+	}
+
+	return nil
+}
+
+// Open returns the stored file for reading
+func (l *LocalStorage) Open(key string) (*os.File, error) {
+	filePath, err := l.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New("failed to open file")
+	}
+
+	return file, nil
+}
+
+func (l *LocalStorage) downloadURL(key string, expiresAt int64) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	query.Set("signature", l.sign("GET", key, expiresAt, "", 0))
+
+	return l.baseURL + LocalDownloadPath + key + "?" + query.Encode()
+}
+
+func (l *LocalStorage) sign(method string, key string, expiresAt int64, contentType string, size int64) string {
+	mac := hmac.New(sha256.New, l.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s\n%d", method, key, expiresAt, contentType, size)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *LocalStorage) validSignature(expected string, actual string, expiresAt int64) bool {
+	if time.Now().Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(actual))
+}
+
+// filePath maps a key into the storage root, rejecting keys that escape it
+func (l *LocalStorage) filePath(key string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if cleaned == "" || cleaned != key || strings.HasPrefix(cleaned, localMetaDir+"/") {
+		return "", errors.New("invalid file key")
+	}
+
+	return filepath.Join(l.root, filepath.FromSlash(cleaned)), nil
+}
+
+func (l *LocalStorage) metaPath(key string) string {
+	return filepath.Join(l.root, localMetaDir, filepath.FromSlash(key)+".json")
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	s3client "github.com/farhapartex/real_estate_be/lib/aws"
+)
+
+type S3Storage struct {
+	client *s3client.S3Client
+}
+
+func NewS3Storage(client *s3client.S3Client) *S3Storage {
+	return &S3Storage{client: client}
+}
+
+func (s *S3Storage) PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error) {
+	return s.client.GeneratePresignUploadURL(ctx, key, contentType, size, expires)
+}
+
+func (s *S3Storage) PresignDownload(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.client.GeneratePresignDownloadURL(ctx, key, expires)
+}
+
+func (s *S3Storage) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	output, err := s.client.HeadFile(ctx, key)
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New("failed to read file info")
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(output.ContentLength),
+		ContentType:  aws.ToString(output.ContentType),
+		LastModified: aws.ToTime(output.LastModified),
+	}, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	return s.client.DeleteFile(ctx, key)
+}
+
+func (s *S3Storage) Copy(ctx context.Context, sourceKey string, destinationKey string) error {
+	return s.client.CopyFile(ctx, sourceKey, destinationKey)
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	body, err := s.client.GetFile(ctx, key)
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New("failed to read file")
+	}
+
+	return body, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, contentType string, body io.Reader, size int64) error {
+	return s.client.PutFile(ctx, key, contentType, body, size)
+}
+
+func (s *S3Storage) PublicURL(key string) string {
+	return s3client.PublicURL(key)
+}
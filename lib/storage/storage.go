@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage is the object store used for listing files. Clients upload and download
+// directly through presigned URLs; the API only signs, inspects and cleans up.
+type Storage interface {
+	PresignUpload(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error)
+	PresignDownload(ctx context.Context, key string, expires time.Duration) (string, error)
+	Head(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	Copy(ctx context.Context, sourceKey string, destinationKey string) error
+
+	// Get and Put are for server-side processing such as image renditions
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Put(ctx context.Context, key string, contentType string, body io.Reader, size int64) error
+
+	// PublicURL is the long-lived URL for files that anyone may see, like gallery photos
+	PublicURL(key string) string
+}
+
+var defaultStorage Storage
+
+// SetDefault registers the storage used by PublicURL
+func SetDefault(s Storage) {
+	defaultStorage = s
+}
+
+// PublicURL returns the public URL of key on the default storage
+func PublicURL(key string) string {
+	if defaultStorage == nil {
+		return key
+	}
+	return defaultStorage.PublicURL(key)
+}
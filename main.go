@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/farhapartex/real_estate_be/config"
 	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/lib/storage"
 	"github.com/farhapartex/real_estate_be/middlewares"
 	"github.com/farhapartex/real_estate_be/routes"
 	"github.com/gin-gonic/gin"
@@ -18,12 +20,23 @@ func main() {
 	config.MigrateDB()
 
 	authController := controllers.NewAuthController(config.DB)
-
-	r := gin.Default()
+	authController.Storage = config.NewStorage()
+	storage.SetDefault(authController.Storage)
+	authController.Events = config.NewEventHub()
+	authController.SMS = config.NewSMSSender()
+	authController.StartMediaProcessing(context.Background())
+	authController.StartViewTracking(context.Background())
+	authController.StartSavedSearchAlerts(context.Background())
+	authController.StartPropertyPurge(context.Background())
+	authController.StartPropertyImports(context.Background())
+	authController.StartPartnerFeeds(context.Background())
+
+	r := gin.New()
 
 	// setup middlewares
-	r.Use(gin.Logger())
+	r.Use(middlewares.LoggerMiddleware())
 	r.Use(gin.Recovery())
+	r.Use(middlewares.RequestIDMiddleware())
 	r.Use(middlewares.CORSMiddleware())
 
 	// setup routes
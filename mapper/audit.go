@@ -0,0 +1,39 @@
+package mapper
+
+import (
+	"encoding/json"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+func AuditLogToDTO(entry models.AuditLog) dto.AuditLogDTO {
+	actorName := ""
+	if entry.Actor != nil {
+		actorName = entry.Actor.FirstName + " " + entry.Actor.LastName
+	}
+
+	changes := json.RawMessage(entry.Changes)
+	if len(changes) == 0 {
+		changes = json.RawMessage("{}")
+	}
+
+	snapshot := json.RawMessage(entry.Snapshot)
+	if len(snapshot) == 0 {
+		snapshot = json.RawMessage("null")
+	}
+
+	return dto.AuditLogDTO{
+		ID:             entry.ID,
+		EntityType:     string(entry.EntityType),
+		EntityID:       entry.EntityID,
+		Action:         string(entry.Action),
+		ActorID:        entry.ActorID,
+		ActorName:      actorName,
+		RequestID:      entry.RequestID,
+		Changes:        changes,
+		Snapshot:       snapshot,
+		RestoredFromID: entry.RestoredFromID,
+		CreatedAt:      entry.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,61 @@
+package mapper
+
+import (
+	"strings"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/farhapartex/real_estate_be/utils"
+)
+
+// MessageToDTO maps a message as seen by viewerID. Contact details in messages from
+// the other side are masked while hideContacts is set.
+func MessageToDTO(message models.Message, viewerID uint, hideContacts bool, read bool) dto.MessageDTO {
+	response := dto.MessageDTO{
+		ID:        message.ID,
+		SenderID:  message.SenderID,
+		Body:      message.Body,
+		Read:      read,
+		CreatedAt: message.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if hideContacts && message.HasContactInfo && message.SenderID != viewerID {
+		response.Body = utils.MaskContactInfo(message.Body, "[hidden]")
+		response.Masked = true
+	}
+
+	return response
+}
+
+func ConversationToDTO(conversation models.Conversation, viewerID uint, lastMessage *dto.MessageDTO) dto.ConversationDTO {
+	response := dto.ConversationDTO{
+		ID: conversation.ID,
+		Property: dto.ConversationPropertyDTO{
+			ID:    conversation.Property.ID,
+			Title: conversation.Property.Title,
+		},
+		Participants:  []dto.ConversationParticipantDTO{},
+		LastMessage:   lastMessage,
+		ContactShared: len(conversation.Participants) > 0,
+		LastMessageAt: FormatOptionalTime(conversation.LastMessageAt),
+		CreatedAt:     conversation.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, participant := range conversation.Participants {
+		response.Participants = append(response.Participants, dto.ConversationParticipantDTO{
+			UserID: participant.UserID,
+			Name:   strings.TrimSpace(participant.User.FirstName + " " + participant.User.LastName),
+			Role:   string(participant.Role),
+		})
+
+		if participant.UserID == viewerID {
+			response.UnreadCount = participant.UnreadCount
+			response.ShareContact = participant.ShareContact
+		}
+		if !participant.ShareContact {
+			response.ContactShared = false
+		}
+	}
+
+	return response
+}
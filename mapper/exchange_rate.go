@@ -0,0 +1,24 @@
+package mapper
+
+import (
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+func ExchangeRateToDTO(rate models.ExchangeRate) dto.ExchangeRateDTO {
+	createdByName := ""
+	if rate.CreatedBy != nil {
+		createdByName = rate.CreatedBy.FirstName + " " + rate.CreatedBy.LastName
+	}
+
+	return dto.ExchangeRateDTO{
+		ID:            rate.ID,
+		BaseCurrency:  rate.BaseCurrency,
+		Currency:      rate.Currency,
+		Rate:          rate.Rate,
+		EffectiveAt:   rate.EffectiveAt.Format("2006-01-02 15:04:05"),
+		CreatedByID:   rate.CreatedByID,
+		CreatedByName: createdByName,
+		CreatedAt:     rate.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+func InquiryToDTO(inquiry models.Inquiry) dto.InquiryDTO {
+	response := dto.InquiryDTO{
+		ID: inquiry.ID,
+		Property: dto.InquiryPropertyDTO{
+			ID:    inquiry.Property.ID,
+			Title: inquiry.Property.Title,
+		},
+		SenderID:  inquiry.SenderID,
+		Name:      inquiry.Name,
+		Email:     inquiry.Email,
+		Phone:     inquiry.Phone,
+		Message:   inquiry.Message,
+		Status:    string(inquiry.Status),
+		RepliedAt: FormatOptionalTime(inquiry.RepliedAt),
+		ClosedAt:  FormatOptionalTime(inquiry.ClosedAt),
+		CreatedAt: inquiry.CreatedAt.Format("2006-01-02 15:04:05"),
+		Replies:   []dto.InquiryReplyDTO{},
+	}
+
+	for _, reply := range inquiry.Replies {
+		response.Replies = append(response.Replies, dto.InquiryReplyDTO{
+			ID:        reply.ID,
+			Message:   reply.Message,
+			CreatedAt: reply.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response
+}
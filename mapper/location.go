@@ -48,6 +48,9 @@ func DistrictDtoToModelMapper(request dto.DistrictRequestDTO, division models.Di
 		DivisionId: request.DivisionId,
 		CountryId:  division.CountryId,
 		Status:     true,
+		Latitude:   request.Latitude,
+		Longitude:  request.Longitude,
+		RadiusKm:   request.RadiusKm,
 	}
 }
 
@@ -63,7 +66,10 @@ func DistrictModelToDTOMapper(district models.District) dto.DistrictResponseDTO
 				Name: district.Division.Country.Name,
 			},
 		},
-		Status: district.Status,
+		Status:    district.Status,
+		Latitude:  district.Latitude,
+		Longitude: district.Longitude,
+		RadiusKm:  district.RadiusKm,
 	}
 }
 
@@ -0,0 +1,35 @@
+package mapper
+
+import (
+	"encoding/json"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+func NotificationToDTO(notification models.Notification) dto.NotificationDTO {
+	data := json.RawMessage(notification.Payload)
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+
+	return dto.NotificationDTO{
+		ID:        notification.ID,
+		Type:      string(notification.Type),
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Data:      data,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    FormatOptionalTime(notification.ReadAt),
+		CreatedAt: notification.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func NotificationPreferenceToDTO(notificationType models.NotificationType, channels models.NotificationChannels) dto.NotificationPreferenceDTO {
+	return dto.NotificationPreferenceDTO{
+		Type:  string(notificationType),
+		InApp: channels.InApp,
+		Email: channels.Email,
+		SMS:   channels.SMS,
+	}
+}
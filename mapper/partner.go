@@ -0,0 +1,122 @@
+package mapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+func PartnerToDTO(partner models.Partner) dto.PartnerDTO {
+	var purpose *string
+	if partner.Purpose != nil {
+		value := string(*partner.Purpose)
+		purpose = &value
+	}
+
+	return dto.PartnerDTO{
+		ID:              partner.ID,
+		Name:            partner.Name,
+		Active:          partner.Active,
+		Purpose:         purpose,
+		CountryID:       partner.CountryID,
+		FeedGeneratedAt: FormatOptionalTime(partner.FeedGeneratedAt),
+		LastFetchedAt:   FormatOptionalTime(partner.LastFetchedAt),
+		CreatedAt:       partner.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// PropertyToFeedListing maps a listing with its locations and gallery preloaded.
+// feature may be nil when the owner never filled in the features; baseURL is
+// the web app, without it the listing has no link.
+func PropertyToFeedListing(property models.Property, feature *models.PropertyFeature, baseURL string) dto.FeedListingDTO {
+	listing := dto.FeedListingDTO{
+		ID:           property.ID,
+		Title:        property.Title,
+		Type:         "For Sale",
+		Content:      property.Description,
+		Price:        dto.FeedPriceDTO{Amount: property.Price, Currency: property.Currency},
+		PropertyType: property.PropertyType,
+		FloorArea:    property.Size,
+		Rooms:        property.Bedrooms,
+		Bathrooms:    property.Bathrooms,
+		Year:         property.BuiltYear,
+		Address:      property.Address,
+		City:         property.District.Name,
+		Region:       property.Division.Name,
+		Country:      property.Country.Name,
+		Latitude:     property.Latitude,
+		Longitude:    property.Longitude,
+		Features:     []string{},
+		Pictures:     []dto.FeedPictureDTO{},
+		Date:         property.CreatedAt.Format("02/01/2006"),
+		Time:         property.CreatedAt.Format("15:04"),
+		CreatedAt:    property.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    property.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if property.Purpose == models.PurposeRent {
+		listing.Type = "For Rent"
+		listing.Price.Period = "monthly"
+	}
+
+	if baseURL != "" {
+		listing.URL = fmt.Sprintf("%s/properties/%d", baseURL, property.ID)
+	}
+
+	if feature != nil {
+		listing.Features = append(listing.Features, feature.Features...)
+		for _, group := range []interface{}{
+			feature.AmenitiesData,
+			feature.SecurityFeatureData,
+			feature.TechnologyFeatureData,
+			feature.LuxuryFeatureData,
+			feature.CommunityFeatureData,
+			feature.UtilsFeatureData,
+			feature.EnergyFeatureData,
+		} {
+			listing.Features = append(listing.Features, enabledFeatures(group)...)
+		}
+		listing.Parking = feature.AmenitiesData.Parking
+	}
+
+	for _, media := range property.Media {
+		url := PropertyMediaURL(media, "full")
+		if media.Status != models.MediaUploaded || url == "" {
+			continue
+		}
+		listing.Pictures = append(listing.Pictures, dto.FeedPictureDTO{
+			URL:   url,
+			Title: media.Caption,
+		})
+	}
+
+	return listing
+}
+
+// enabledFeatures returns the names of the yes/no features that are set in a
+// feature group, by their JSON names, sorted
+func enabledFeatures(group interface{}) []string {
+	data, err := json.Marshal(group)
+	if err != nil {
+		return nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil
+	}
+
+	var names []string
+	for name, value := range values {
+		if enabled, ok := value.(bool); ok && enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
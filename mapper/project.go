@@ -0,0 +1,105 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+// ProjectDTOToModel maps a validated project request
+func ProjectDTOToModel(request dto.ProjectRequestDTO, ownerID uint) models.Project {
+	project := models.Project{
+		OwnerID:     ownerID,
+		Name:        request.Name,
+		Description: request.Description,
+		CountryID:   request.CountryID,
+		DivisionID:  request.DivisionID,
+		DistrictID:  request.DistrictID,
+		Address:     request.Address,
+		Latitude:    request.Latitude,
+		Longitude:   request.Longitude,
+		TotalFloors: request.TotalFloors,
+		Features:    request.Features,
+
+		AmenitiesData:        models.Amenities(request.Amenities),
+		SecurityFeatureData:  models.SecurityFeature(request.SecurityFeature),
+		CommunityFeatureData: models.CommunityFeature(request.CommunityFeature),
+		UtilsFeatureData:     models.UtilsFeature(request.UtilsFeature),
+		EnergyFeatureData:    models.EnergyFeature(request.EnergyFeature),
+	}
+	if request.CompletionDate != nil {
+		if completionDate, err := time.Parse("2006-01-02", *request.CompletionDate); err == nil {
+			project.CompletionDate = &completionDate
+		}
+	}
+	return project
+}
+
+func ProjectModelToDTO(project models.Project) dto.ProjectDTO {
+	features := []string(project.Features)
+	if features == nil {
+		features = []string{}
+	}
+
+	return dto.ProjectDTO{
+		ID:          project.ID,
+		OwnerID:     project.OwnerID,
+		Name:        project.Name,
+		Description: project.Description,
+		Country: dto.CountryMinimalDTO{
+			ID:   uint32(project.Country.ID),
+			Name: project.Country.Name,
+		},
+		Division: dto.DivisionMinimal2DTO{
+			ID:   uint32(project.Division.ID),
+			Name: project.Division.Name,
+		},
+		District: dto.DistrictMinimalResponseDTO{
+			ID:   uint32(project.District.ID),
+			Name: project.District.Name,
+		},
+		Address:        project.Address,
+		Latitude:       project.Latitude,
+		Longitude:      project.Longitude,
+		CompletionDate: FormatOptionalDate(project.CompletionDate),
+		TotalFloors:    project.TotalFloors,
+		Features:       features,
+
+		Amenities:        dto.AmenitiesDTO(project.AmenitiesData),
+		SecurityFeature:  dto.SecurityFeatureDTO(project.SecurityFeatureData),
+		CommunityFeature: dto.CommunityFeatureDTO(project.CommunityFeatureData),
+		UtilsFeature:     dto.UtilsFeatureDTO(project.UtilsFeatureData),
+		EnergyFeature:    dto.EnergyFeatureDTO(project.EnergyFeatureData),
+
+		CoverURL: PropertyCoverURL(project.Media),
+		Gallery:  PropertyGalleryToDTO(project.Media),
+
+		CreatedAt: project.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: project.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func ProjectModelToListDTO(project models.Project, units dto.ProjectUnitsDTO) dto.ProjectListDTO {
+	return dto.ProjectListDTO{
+		ID:   project.ID,
+		Name: project.Name,
+		Country: dto.CountryMinimalDTO{
+			ID:   uint32(project.Country.ID),
+			Name: project.Country.Name,
+		},
+		Division: dto.DivisionMinimal2DTO{
+			ID:   uint32(project.Division.ID),
+			Name: project.Division.Name,
+		},
+		District: dto.DistrictMinimalResponseDTO{
+			ID:   uint32(project.District.ID),
+			Name: project.District.Name,
+		},
+		Address:         project.Address,
+		CompletionDate:  FormatOptionalDate(project.CompletionDate),
+		CoverURL:        PropertyCoverURL(project.Media),
+		CreatedAt:       project.CreatedAt.Format("2006-01-02 15:04:05"),
+		ProjectUnitsDTO: units,
+	}
+}
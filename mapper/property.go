@@ -1,7 +1,11 @@
 package mapper
 
 import (
+	"math"
+	"time"
+
 	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/lib/storage"
 	"github.com/farhapartex/real_estate_be/models"
 )
 
@@ -11,6 +15,7 @@ func PropertyDtoToModelMapper(request dto.PropertyRequestDTO, userID uint) model
 		Title:        request.Title,
 		Purpose:      models.PropertyString(request.Purpose),
 		Price:        request.Price,
+		Currency:     request.Currency,
 		Status:       models.StatusDraft, // Default status is draft
 		PropertyType: request.PropertyType,
 		Bedrooms:     request.Bedrooms,
@@ -21,7 +26,12 @@ func PropertyDtoToModelMapper(request dto.PropertyRequestDTO, userID uint) model
 		DivisionID:   request.DivisionID,
 		DistrictID:   request.DistrictID,
 		Address:      request.Address,
+		Latitude:     request.Latitude,
+		Longitude:    request.Longitude,
 		Description:  request.Description,
+		ExternalRef:  request.ExternalRef,
+		ProjectID:    request.ProjectID,
+		Floor:        request.Floor,
 	}
 }
 
@@ -31,6 +41,7 @@ func PropertyModelToResponseDTOMapper(property models.Property) dto.PropertyList
 		Title:        property.Title,
 		Purpose:      string(property.Purpose),
 		Price:        property.Price,
+		Currency:     property.Currency,
 		PropertyType: property.PropertyType,
 		Country: dto.CountryMinimalDTO{
 			ID:   uint32(property.Country.ID),
@@ -44,33 +55,187 @@ func PropertyModelToResponseDTOMapper(property models.Property) dto.PropertyList
 			ID:   uint32(property.District.ID),
 			Name: property.District.Name,
 		},
-		Status:    string(property.Status),
-		Address:   property.Address,
-		CreatedAt: property.CreatedAt.Format("2006-01-02 15:04:05"),
-		Views:     0,
-		Inquiries: 0,
+		Status:     string(property.Status),
+		Address:    property.Address,
+		Latitude:   property.Latitude,
+		Longitude:  property.Longitude,
+		DistanceKm: property.Distance,
+		ProjectID:  property.ProjectID,
+		Floor:      property.Floor,
+		CoverURL:   propertyCoverURL(property),
+		CreatedAt:  property.CreatedAt.Format("2006-01-02 15:04:05"),
+		Views:      int(property.Views),
+		Inquiries:  int(property.Inquiries),
+
+		Favorites:   int(property.Favorites),
+		IsFavorited: property.IsFavorited,
+
+		ReducedByPercent: PriceReductionPercent(property),
 	}
 }
 
 func PropertyModelToDetailsResponseDTOMapper(property models.Property) dto.PropertyResponseDTO {
-	return dto.PropertyResponseDTO{
+	response := dto.PropertyResponseDTO{
 		ID:           uint32(property.ID),
 		Title:        property.Title,
 		Purpose:      string(property.Purpose),
 		Price:        property.Price,
+		Currency:     property.Currency,
 		PropertyType: property.PropertyType,
 		CountryID:    uint32(property.CountryID),
 		DivisionID:   uint32(property.DivisionID),
 		DistrictID:   uint32(property.DistrictID),
 		Status:       string(property.Status),
 		Address:      property.Address,
+		Latitude:     property.Latitude,
+		Longitude:    property.Longitude,
 		CreatedAt:    property.CreatedAt.Format("2006-01-02 15:04:05"),
 		Description:  property.Description,
 		BedRooms:     property.Bedrooms,
 		BathRooms:    property.Bathrooms,
 		Size:         property.Size,
 		BuiltYear:    property.BuiltYear,
+		ExternalRef:  property.ExternalRef,
+		Floor:        property.Floor,
+
+		SubmittedAt:     FormatOptionalTime(property.SubmittedAt),
+		ApprovedAt:      FormatOptionalTime(property.ApprovedAt),
+		RejectedAt:      FormatOptionalTime(property.RejectedAt),
+		RejectionReason: property.RejectionReason,
+
+		CoverURL: propertyCoverURL(property),
+		Gallery:  PropertyGalleryToDTO(property.Media),
+
+		IsFavorited: property.IsFavorited,
+
+		PriceHistory:     PriceHistoryToDTO(property.PriceHistory),
+		ReducedByPercent: PriceReductionPercent(property),
+
+		RentalTerms: RentalTermsToDTO(property.RentalTerms),
+	}
+
+	if property.Project != nil {
+		project := ProjectModelToDTO(*property.Project)
+		response.Project = &project
+	}
+
+	return response
+}
+
+// RentalTermsDTOToModel maps validated rental terms; a missing tenant
+// preference means any tenant
+func RentalTermsDTOToModel(request dto.RentalTermsRequestDTO, propertyID uint) models.PropertyRentalTerms {
+	terms := models.PropertyRentalTerms{
+		PropertyID:       propertyID,
+		RentPeriod:       models.RentPeriod(request.RentPeriod),
+		SecurityDeposit:  request.SecurityDeposit,
+		AdvanceMonths:    request.AdvanceMonths,
+		ServiceCharge:    request.ServiceCharge,
+		MinLeaseMonths:   request.MinLeaseMonths,
+		TenantPreference: models.TenantPreference(request.TenantPreference),
+	}
+	if terms.TenantPreference == "" {
+		terms.TenantPreference = models.TenantAny
+	}
+	if request.AvailableFrom != nil {
+		if availableFrom, err := time.Parse("2006-01-02", *request.AvailableFrom); err == nil {
+			terms.AvailableFrom = &availableFrom
+		}
+	}
+	return terms
+}
+
+func RentalTermsToDTO(terms *models.PropertyRentalTerms) *dto.RentalTermsDTO {
+	if terms == nil {
+		return nil
+	}
+
+	return &dto.RentalTermsDTO{
+		RentPeriod:       string(terms.RentPeriod),
+		SecurityDeposit:  terms.SecurityDeposit,
+		AdvanceMonths:    terms.AdvanceMonths,
+		ServiceCharge:    terms.ServiceCharge,
+		MinLeaseMonths:   terms.MinLeaseMonths,
+		AvailableFrom:    FormatOptionalDate(terms.AvailableFrom),
+		TenantPreference: string(terms.TenantPreference),
+	}
+}
+
+func PriceHistoryToDTO(changes []models.PropertyPriceChange) []dto.PriceChangeDTO {
+	responseDTOs := []dto.PriceChangeDTO{}
+	for _, change := range changes {
+		percent := 0.0
+		if change.OldPrice > 0 {
+			percent = roundPercent((change.NewPrice - change.OldPrice).Float64() / change.OldPrice.Float64() * 100)
+		}
+
+		responseDTOs = append(responseDTOs, dto.PriceChangeDTO{
+			OldPrice:      change.OldPrice,
+			NewPrice:      change.NewPrice,
+			Currency:      change.Currency,
+			ChangePercent: percent,
+			ChangedAt:     change.ChangedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return responseDTOs
+}
+
+// PriceReductionPercent is how far the price is below the original price, for the
+// "reduced by X%" badge. nil when it is not below.
+func PriceReductionPercent(property models.Property) *float64 {
+	original := property.OriginalPrice
+	if original == nil && len(property.PriceHistory) > 0 {
+		original = &property.PriceHistory[0].OldPrice
+	}
+
+	if original == nil || *original <= 0 || property.Price >= *original {
+		return nil
+	}
+
+	percent := roundPercent((*original - property.Price).Float64() / original.Float64() * 100)
+	return &percent
+}
+
+func roundPercent(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+func PropertyModelToModerationDTOMapper(property models.Property) dto.PropertyModerationDTO {
+	return dto.PropertyModerationDTO{
+		PropertyListDTO: PropertyModelToResponseDTOMapper(property),
+		OwnerID:         property.OwnerID,
+		OwnerName:       property.Owner.FirstName + " " + property.Owner.LastName,
+		OwnerEmail:      property.Owner.Email,
+		SubmittedAt:     FormatOptionalTime(property.SubmittedAt),
+	}
+}
+
+func PropertyModelToDeletedDTOMapper(property models.Property, retention time.Duration) dto.DeletedPropertyDTO {
+	deletedAt := property.DeletedAt.Time
+
+	return dto.DeletedPropertyDTO{
+		PropertyModerationDTO: PropertyModelToModerationDTOMapper(property),
+		DeletedAt:             deletedAt.Format("2006-01-02 15:04:05"),
+		PurgeAt:               deletedAt.Add(retention).Format("2006-01-02 15:04:05"),
+	}
+}
+
+func FormatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
+
+func FormatOptionalDate(t *time.Time) *string {
+	if t == nil {
+		return nil
 	}
+
+	formatted := t.Format("2006-01-02")
+	return &formatted
 }
 
 func PropertyFeatureModelToDTO(propFeat models.PropertyFeature) dto.PropertyFeatureDetailsDTO {
@@ -103,3 +268,143 @@ func PropertyFeatureDTOToModel(request dto.PropertyFeatureDTO) models.PropertyFe
 		EnergyFeatureData:     models.EnergyFeature(request.EnergyFeature),
 	}
 }
+
+func PropertyStatusHistoryToDTO(history models.PropertyStatusHistory) dto.PropertyStatusHistoryDTO {
+	actorName := ""
+	if history.Actor != nil {
+		actorName = history.Actor.FirstName + " " + history.Actor.LastName
+	}
+
+	return dto.PropertyStatusHistoryDTO{
+		ID:         history.ID,
+		FromStatus: string(history.FromStatus),
+		ToStatus:   string(history.ToStatus),
+		ActorType:  string(history.ActorType),
+		ActorID:    history.ActorID,
+		ActorName:  actorName,
+		Reason:     history.Reason,
+		CreatedAt:  history.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func PropertyMediaToDTO(media models.PropertyMedia) dto.PropertyMediaDTO {
+	response := dto.PropertyMediaDTO{
+		ID:          media.ID,
+		URL:         PropertyMediaURL(media, "full"),
+		ContentType: media.ContentType,
+		SizeBytes:   media.SizeBytes,
+		Width:       media.Width,
+		Height:      media.Height,
+		SortOrder:   media.SortOrder,
+		Caption:     media.Caption,
+		IsCover:     media.IsCover,
+		Status:      string(media.Status),
+		CreatedAt:   media.CreatedAt.Format("2006-01-02 15:04:05"),
+
+		ProcessingStatus: string(media.ProcessingStatus),
+		BlurHash:         media.BlurHash,
+		DominantColor:    media.DominantColor,
+		Renditions:       map[string]string{},
+	}
+
+	for _, rendition := range media.Renditions {
+		response.Renditions[rendition.Name] = storage.PublicURL(rendition.S3Key)
+	}
+
+	return response
+}
+
+// PropertyMediaURL returns the URL of the named rendition, empty until the photo
+// is processed. The original upload is never linked: it keeps the EXIF location
+// and has no watermark.
+func PropertyMediaURL(media models.PropertyMedia, rendition string) string {
+	for _, item := range media.Renditions {
+		if item.Name == rendition {
+			return storage.PublicURL(item.S3Key)
+		}
+	}
+	return ""
+}
+
+// PropertyGalleryToDTO maps the uploaded photos of a property, keeping their order
+func PropertyGalleryToDTO(media []models.PropertyMedia) []dto.PropertyMediaDTO {
+	gallery := []dto.PropertyMediaDTO{}
+	for _, item := range media {
+		if item.Status != models.MediaUploaded {
+			continue
+		}
+		gallery = append(gallery, PropertyMediaToDTO(item))
+	}
+
+	return gallery
+}
+
+// propertyCoverURL is the cover of a listing; units without photos of their
+// own show the project cover, when the project is preloaded
+func propertyCoverURL(property models.Property) *string {
+	if cover := PropertyCoverURL(property.Media); cover != nil || property.Project == nil {
+		return cover
+	}
+	return PropertyCoverURL(property.Project.Media)
+}
+
+// PropertyCoverURL returns the cover photo URL, falling back to the first photo
+func PropertyCoverURL(media []models.PropertyMedia) *string {
+	var fallback *models.PropertyMedia
+	for i, item := range media {
+		if item.Status != models.MediaUploaded {
+			continue
+		}
+		if item.IsCover {
+			return optionalURL(PropertyMediaURL(item, "card"))
+		}
+		if fallback == nil {
+			fallback = &media[i]
+		}
+	}
+
+	if fallback == nil {
+		return nil
+	}
+
+	return optionalURL(PropertyMediaURL(*fallback, "card"))
+}
+
+func optionalURL(url string) *string {
+	if url == "" {
+		return nil
+	}
+	return &url
+}
+
+func WatermarkSettingToDTO(setting models.WatermarkSetting) dto.WatermarkSettingDTO {
+	response := dto.WatermarkSettingDTO{
+		Enabled:   setting.Enabled,
+		Source:    string(setting.Source),
+		Position:  setting.Position,
+		Opacity:   setting.Opacity,
+		UpdatedAt: setting.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if setting.LogoKey != nil {
+		url := storage.PublicURL(*setting.LogoKey)
+		response.LogoURL = &url
+	}
+
+	return response
+}
+
+func PropertyAttachmentToDTO(attachment models.PropertyAttachment, available bool) dto.PropertyAttachmentDTO {
+	return dto.PropertyAttachmentDTO{
+		ID:          attachment.ID,
+		Kind:        string(attachment.Kind),
+		Title:       attachment.Title,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		SizeBytes:   attachment.SizeBytes,
+		Visibility:  string(attachment.Visibility),
+		Status:      string(attachment.Status),
+		Available:   available,
+		CreatedAt:   attachment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,24 @@
+package mapper
+
+import (
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+func PropertyImportToDTO(record models.PropertyImport) dto.PropertyImportDTO {
+	return dto.PropertyImportDTO{
+		ID:          record.ID,
+		FileName:    record.FileName,
+		Format:      record.Format,
+		DryRun:      record.DryRun,
+		Status:      string(record.Status),
+		TotalRows:   record.TotalRows,
+		CreatedRows: record.CreatedRows,
+		UpdatedRows: record.UpdatedRows,
+		FailedRows:  record.FailedRows,
+		Error:       record.Error,
+		CreatedAt:   record.CreatedAt.Format("2006-01-02 15:04:05"),
+		StartedAt:   FormatOptionalTime(record.StartedAt),
+		FinishedAt:  FormatOptionalTime(record.FinishedAt),
+	}
+}
@@ -0,0 +1,190 @@
+package mapper
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+// ResoStandardStatuses maps the RESO StandardStatus values of listings on the
+// market to the stored status
+var ResoStandardStatuses = map[string]string{
+	"Active":                string(models.StatusActive),
+	"Active Under Contract": string(models.StatusUnderOffer),
+}
+
+// ResoPropertyTypes maps the RESO PropertyType values to the stored purpose
+var ResoPropertyTypes = map[string]string{
+	"Residential":       string(models.PurposeSale),
+	"Residential Lease": string(models.PurposeRent),
+}
+
+// ResoFurnished maps the RESO Furnished values to the stored amenity flag
+var ResoFurnished = map[string]string{
+	"Furnished":   "true",
+	"Unfurnished": "false",
+}
+
+func resoValue(values map[string]string, stored string) interface{} {
+	for name, value := range values {
+		if value == stored {
+			return name
+		}
+	}
+	return nil
+}
+
+// PropertyToReso maps a listing, with its locations, owner and gallery
+// preloaded, to RESO Data Dictionary fields. feature and office may be nil.
+func PropertyToReso(property models.Property, feature *models.PropertyFeature, office *models.OwnerProfile) map[string]interface{} {
+	record := map[string]interface{}{
+		"ListingKey":              strconv.FormatUint(uint64(property.ID), 10),
+		"ListingKeyNumeric":       property.ID,
+		"ListingId":               property.ExternalRef,
+		"StandardStatus":          resoValue(ResoStandardStatuses, string(property.Status)),
+		"PropertyType":            resoValue(ResoPropertyTypes, string(property.Purpose)),
+		"PropertySubType":         property.PropertyType,
+		"ListPrice":               property.Price,
+		"CurrencyCode":            property.Currency,
+		"BedroomsTotal":           property.Bedrooms,
+		"BathroomsTotalInteger":   property.Bathrooms,
+		"LivingArea":              property.Size,
+		"YearBuilt":               nil,
+		"UnparsedAddress":         property.Address,
+		"City":                    property.District.Name,
+		"StateOrProvince":         property.Division.Name,
+		"Country":                 property.Country.Code,
+		"Latitude":                property.Latitude,
+		"Longitude":               property.Longitude,
+		"PublicRemarks":           property.Description,
+		"PhotosCount":             len(property.Media),
+		"ListAgentKey":            strconv.FormatUint(uint64(property.OwnerID), 10),
+		"ListAgentFirstName":      property.Owner.FirstName,
+		"ListAgentLastName":       property.Owner.LastName,
+		"ListAgentPreferredPhone": nil,
+		"ListOfficeName":          nil,
+		"OriginalEntryTimestamp":  property.CreatedAt.Format(time.RFC3339),
+		"ModificationTimestamp":   property.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if property.BuiltYear > 0 {
+		record["YearBuilt"] = property.BuiltYear
+	}
+
+	if office != nil {
+		if office.PhoneNumber != "" {
+			record["ListAgentPreferredPhone"] = office.PhoneNumber
+		}
+		record["ListOfficeName"] = office.CompanyName
+	}
+
+	var amenities models.Amenities
+	var security models.SecurityFeature
+	var technology models.TechnologyFeature
+	var luxury models.LuxuryFeature
+	var community models.CommunityFeature
+	var utilities models.UtilsFeature
+	var energy models.EnergyFeature
+	interior := []string{}
+	if feature != nil {
+		amenities = feature.AmenitiesData
+		security = feature.SecurityFeatureData
+		technology = feature.TechnologyFeatureData
+		luxury = feature.LuxuryFeatureData
+		community = feature.CommunityFeatureData
+		utilities = feature.UtilsFeatureData
+		energy = feature.EnergyFeatureData
+		interior = append(interior, feature.Features...)
+	}
+
+	record["CoolingYN"] = amenities.AirConditioning
+	record["HeatingYN"] = amenities.Heating
+	record["FireplaceYN"] = amenities.Fireplace || luxury.Fireplace
+	record["PoolPrivateYN"] = amenities.Pool || luxury.Pool
+	record["Furnished"] = resoValue(ResoFurnished, strconv.FormatBool(amenities.Furnished))
+	record["ParkingTotal"] = amenities.Parking
+	record["GarageSpaces"] = amenities.Garages
+	record["LotSizeArea"] = amenities.LotSize
+	record["InteriorFeatures"] = interior
+
+	record["Appliances"] = resoList(map[string]bool{
+		"Dishwasher":   technology.Dishwasher,
+		"Disposal":     technology.GarbageDisposal,
+		"Refrigerator": technology.Refrigerator,
+		"Microwave":    technology.Microwave,
+		"Range":        technology.StoveOven,
+		"Washer":       amenities.WasherDryer,
+		"Dryer":        amenities.WasherDryer,
+	})
+	record["SecurityFeatures"] = resoList(map[string]bool{
+		"Security System":  security.SecuritySystem,
+		"Security Service": security.Doorman,
+		"Security Camera":  security.SecurityCamera,
+		"Gated Community":  security.GatedCommunity,
+		"Fire Alarm":       security.FireAlarm,
+	})
+	record["AssociationAmenities"] = resoList(map[string]bool{
+		"Pool":             luxury.Pool,
+		"Fitness Center":   luxury.Gym || amenities.Gym,
+		"Sauna":            luxury.Sauna,
+		"Concierge":        community.Concierge,
+		"Business Center":  community.BusinessCenter,
+		"Meeting Room":     community.ConferenceRoom,
+		"Playground":       community.Playground,
+		"Barbecue":         community.BBQArea,
+		"Garden":           community.CommunityGarden,
+		"Tennis Court(s)":  community.TennisCourt,
+		"Basketball Court": community.BasketballCourt,
+		"Elevator(s)":      amenities.Elevator,
+	})
+	record["RentIncludes"] = resoList(map[string]bool{
+		"Water":            utilities.WaterIncluded,
+		"Gas":              utilities.GasIncluded,
+		"Electricity":      utilities.ElectricityIncluded,
+		"Trash Collection": utilities.TrashRemovalIncluded,
+		"Internet":         utilities.InternetIncluded,
+	})
+	record["GreenEnergyGeneration"] = resoList(map[string]bool{
+		"Solar": energy.SolarPanels,
+	})
+	record["GreenEnergyEfficient"] = resoList(map[string]bool{
+		"Appliances": energy.EnergyEfficientAppliances,
+		"Thermostat": energy.ProgrammableThermostat,
+	})
+	record["Flooring"] = resoList(map[string]bool{
+		"Hardwood": amenities.HardwoodFloors,
+	})
+
+	return record
+}
+
+// resoList returns the names that are set, sorted
+func resoList(values map[string]bool) []string {
+	names := []string{}
+	for name, set := range values {
+		if set {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PropertyMediaToReso maps a gallery photo, with its renditions preloaded, to the RESO Media resource
+func PropertyMediaToReso(media models.PropertyMedia) map[string]interface{} {
+	return map[string]interface{}{
+		"MediaKey":                   strconv.FormatUint(uint64(media.ID), 10),
+		"ResourceName":               "Property",
+		"ResourceRecordKey":          strconv.FormatUint(uint64(*media.PropertyID), 10),
+		"MediaCategory":              "Photo",
+		"MediaURL":                   PropertyMediaURL(media, "full"),
+		"MimeType":                   media.ContentType,
+		"Order":                      media.SortOrder,
+		"ShortDescription":           media.Caption,
+		"ImageWidth":                 media.Width,
+		"ImageHeight":                media.Height,
+		"MediaModificationTimestamp": media.UpdatedAt.Format(time.RFC3339),
+	}
+}
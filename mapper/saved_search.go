@@ -0,0 +1,72 @@
+package mapper
+
+import (
+	"strings"
+
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+)
+
+func SavedSearchCriteriaToModel(criteria dto.SavedSearchCriteriaDTO) models.SearchCriteria {
+	return models.SearchCriteria{
+		Purpose:      criteria.Purpose,
+		PropertyType: strings.TrimSpace(criteria.PropertyType),
+		MinPrice:     criteria.MinPrice,
+		MaxPrice:     criteria.MaxPrice,
+		Currency:     strings.ToUpper(strings.TrimSpace(criteria.Currency)),
+		BedRooms:     criteria.BedRooms,
+		BathRooms:    criteria.BathRooms,
+		MinSize:      criteria.MinSize,
+		MaxSize:      criteria.MaxSize,
+		CountryID:    criteria.CountryID,
+		DivisionID:   criteria.DivisionID,
+		DistrictID:   criteria.DistrictID,
+		Near:         strings.TrimSpace(criteria.Near),
+		RadiusKm:     criteria.RadiusKm,
+		BBox:         strings.TrimSpace(criteria.BBox),
+
+		RentPeriod:       criteria.RentPeriod,
+		TenantPreference: criteria.TenantPreference,
+		AvailableBefore:  criteria.AvailableBefore,
+		MaxDeposit:       criteria.MaxDeposit,
+		MaxAdvanceMonths: criteria.MaxAdvanceMonths,
+		LeaseMonths:      criteria.LeaseMonths,
+	}
+}
+
+func SavedSearchToDTO(search models.SavedSearch) dto.SavedSearchDTO {
+	criteria := search.Criteria
+
+	return dto.SavedSearchDTO{
+		ID:   search.ID,
+		Name: search.Name,
+		Criteria: dto.SavedSearchCriteriaDTO{
+			Purpose:      criteria.Purpose,
+			PropertyType: criteria.PropertyType,
+			MinPrice:     criteria.MinPrice,
+			MaxPrice:     criteria.MaxPrice,
+			Currency:     criteria.Currency,
+			BedRooms:     criteria.BedRooms,
+			BathRooms:    criteria.BathRooms,
+			MinSize:      criteria.MinSize,
+			MaxSize:      criteria.MaxSize,
+			CountryID:    criteria.CountryID,
+			DivisionID:   criteria.DivisionID,
+			DistrictID:   criteria.DistrictID,
+			Near:         criteria.Near,
+			RadiusKm:     criteria.RadiusKm,
+			BBox:         criteria.BBox,
+
+			RentPeriod:       criteria.RentPeriod,
+			TenantPreference: criteria.TenantPreference,
+			AvailableBefore:  criteria.AvailableBefore,
+			MaxDeposit:       criteria.MaxDeposit,
+			MaxAdvanceMonths: criteria.MaxAdvanceMonths,
+			LeaseMonths:      criteria.LeaseMonths,
+		},
+		Frequency:     string(search.Frequency),
+		AlertsEnabled: search.AlertsEnabled,
+		LastAlertAt:   FormatOptionalTime(search.LastAlertAt),
+		CreatedAt:     search.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -29,7 +29,8 @@ func CORSMiddleware() gin.HandlerFunc {
 
 		ctx.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		ctx.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Visitor-Id, Last-Event-ID, X-Request-ID")
+		ctx.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		ctx.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
 		if ctx.Request.Method == "OPTIONS" {
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loggedSecrets are query parameters that carry credentials, such as partner
+// feed tokens and stream tickets
+var loggedSecrets = []string{"token", "ticket", "access_token"}
+
+// LoggerMiddleware logs requests like gin.Logger, with the credentials in the
+// query string masked
+func LoggerMiddleware() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			param.StatusCode,
+			param.Latency,
+			param.ClientIP,
+			param.Method,
+			maskQuerySecrets(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+func maskQuerySecrets(path string) string {
+	base, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return base + "?[unparsable query]"
+	}
+
+	masked := false
+	for _, name := range loggedSecrets {
+		if _, ok := query[name]; ok {
+			query.Set(name, "REDACTED")
+			masked = true
+		}
+	}
+	if !masked {
+		return path
+	}
+	return base + "?" + query.Encode()
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/farhapartex/real_estate_be/config"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/farhapartex/real_estate_be/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// OptionalAuthMiddleware sets the user like AuthMiddleware when a valid token is
+// sent, and lets the request through anonymously otherwise. Used on public routes
+// whose response depends on who is asking.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.Next()
+			return
+		}
+
+		claims, err := utils.ValidateJWT(parts[1])
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		var user models.User
+		if err := config.DB.First(&user, claims.Id).Error; err == nil && user.Status == "active" {
+			c.Set("user", user)
+			c.Set("userId", user.ID)
+		}
+
+		c.Next()
+	}
+}
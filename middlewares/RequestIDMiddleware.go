@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware tags every request with an id, reusing the one sent by a
+// proxy when it looks sane. It is echoed back in the response and stored as
+// "requestId" so audit records can be traced to the request that made them.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set("requestId", requestID)
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamAuthMiddleware signs in the event stream with a single-use ticket from
+// the ticket query parameter, for clients that cannot set headers such as the
+// browser's EventSource. Requests without a ticket go through AuthMiddleware.
+func StreamAuthMiddleware(redeem func(ticket string) (*models.User, error)) gin.HandlerFunc {
+	auth := AuthMiddleware()
+
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" || c.GetHeader("Authorization") != "" {
+			auth(c)
+			return
+		}
+
+		user, err := redeem(ticket)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("user", *user)
+		c.Set("userId", user.ID)
+
+		c.Next()
+	}
+}
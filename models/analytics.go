@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PropertyDailyStat holds the per-day counters of a listing. Rows are written by
+// the view buffer flush, never per request.
+type PropertyDailyStat struct {
+	PropertyID uint      `gorm:"primaryKey;autoIncrement:false" json:"property_id"`
+	Day        time.Time `gorm:"primaryKey;type:date" json:"day"`
+	Views      int64     `gorm:"not null;default:0" json:"views"`
+}
+
+// PropertyViewVisitor remembers who viewed a listing on a day so repeat views are
+// not counted. VisitorHash is salted with the day, so visitors cannot be followed
+// across days; rows are dropped once the day is over.
+type PropertyViewVisitor struct {
+	PropertyID  uint      `gorm:"primaryKey;autoIncrement:false"`
+	Day         time.Time `gorm:"primaryKey;type:date;index"`
+	VisitorHash string    `gorm:"primaryKey;type:char(64)"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "gorm.io/gorm"
+
+type AttachmentKind string
+
+const (
+	AttachmentFloorPlan AttachmentKind = "floor_plan"
+	AttachmentBrochure  AttachmentKind = "brochure"
+	AttachmentTitleDeed AttachmentKind = "title_deed"
+	AttachmentOther     AttachmentKind = "other"
+)
+
+// AttachmentVisibility controls who may download an attachment besides the owner
+type AttachmentVisibility string
+
+const (
+	VisibilityPublic     AttachmentVisibility = "public"
+	VisibilityRegistered AttachmentVisibility = "registered"
+	// VisibilityInquiry files unlock once the owner has replied to the user's inquiry
+	VisibilityInquiry AttachmentVisibility = "inquiry"
+)
+
+// PropertyAttachment is a document such as a floor plan or brochure. Unlike photos
+// attachments are never public files; every download goes through a signed URL.
+type PropertyAttachment struct {
+	gorm.Model
+	PropertyID  uint                 `gorm:"index;not null" json:"property_id"`
+	Kind        AttachmentKind       `gorm:"type:varchar(20);not null" json:"kind"`
+	Title       string               `gorm:"type:varchar(255);not null" json:"title"`
+	FileName    string               `gorm:"type:varchar(255);not null" json:"file_name"`
+	S3Key       string               `gorm:"type:varchar(512);uniqueIndex;not null" json:"s3_key"`
+	ContentType string               `gorm:"type:varchar(100);not null" json:"content_type"`
+	SizeBytes   int64                `gorm:"not null" json:"size_bytes"`
+	Visibility  AttachmentVisibility `gorm:"type:varchar(20);default:registered;not null" json:"visibility"`
+	Status      MediaStatus          `gorm:"type:varchar(20);default:pending;index" json:"status"`
+}
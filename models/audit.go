@@ -0,0 +1,190 @@
+package models
+
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+	"github.com/lib/pq"
+)
+
+type AuditEntity string
+
+const (
+	AuditEntityProperty        AuditEntity = "property"
+	AuditEntityPropertyFeature AuditEntity = "property_feature"
+	AuditEntityRentalTerms     AuditEntity = "rental_terms"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+)
+
+// AuditLog records one write to a listing, its features or rental terms. Changes holds the
+// changed fields as {"field": {"old": ..., "new": ...}} and Snapshot the full
+// version after the write (before it, for deletes) so it can be restored.
+type AuditLog struct {
+	ID             uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt      time.Time   `gorm:"index:idx_audit_property_created,priority:2;default:CURRENT_TIMESTAMP" json:"created_at"`
+	PropertyID     uint        `gorm:"index:idx_audit_property_created,priority:1;not null" json:"property_id"`
+	EntityType     AuditEntity `gorm:"type:varchar(30);not null" json:"entity_type"`
+	EntityID       uint        `gorm:"not null" json:"entity_id"`
+	Action         AuditAction `gorm:"type:varchar(20);not null" json:"action"`
+	ActorID        *uint       `json:"actor_id"`
+	Actor          *User       `gorm:"foreignKey:ActorID" json:"actor"`
+	RequestID      string      `gorm:"type:varchar(64);index" json:"request_id"`
+	Changes        []byte      `gorm:"type:jsonb" json:"-"`
+	Snapshot       []byte      `gorm:"type:jsonb" json:"-"`
+	RestoredFromID *uint       `json:"restored_from_id"`
+}
+
+// PropertySnapshot is the version of a listing kept in the audit trail
+type PropertySnapshot struct {
+	Title        string         `json:"title"`
+	Purpose      PropertyString `json:"purpose"`
+	Price        money.Amount   `json:"price"`
+	Currency     string         `json:"currency"`
+	Status       PropertyStatus `json:"status"`
+	PropertyType string         `json:"property_type"`
+	Bedrooms     int            `json:"bedrooms"`
+	Bathrooms    int            `json:"bathrooms"`
+	Size         float64        `json:"size"`
+	BuiltYear    int            `json:"built_year"`
+	CountryID    uint32         `json:"country_id"`
+	DivisionID   uint32         `json:"division_id"`
+	DistrictID   uint32         `json:"district_id"`
+	Address      string         `json:"address"`
+	Latitude     *float64       `json:"latitude"`
+	Longitude    *float64       `json:"longitude"`
+	Description  string         `json:"description"`
+	ExternalRef  *string        `json:"external_ref"`
+	Floor        *int           `json:"floor"`
+}
+
+func NewPropertySnapshot(property Property) PropertySnapshot {
+	return PropertySnapshot{
+		Title:        property.Title,
+		Purpose:      property.Purpose,
+		Price:        property.Price,
+		Currency:     property.Currency,
+		Status:       property.Status,
+		PropertyType: property.PropertyType,
+		Bedrooms:     property.Bedrooms,
+		Bathrooms:    property.Bathrooms,
+		Size:         property.Size,
+		BuiltYear:    property.BuiltYear,
+		CountryID:    property.CountryID,
+		DivisionID:   property.DivisionID,
+		DistrictID:   property.DistrictID,
+		Address:      property.Address,
+		Latitude:     property.Latitude,
+		Longitude:    property.Longitude,
+		Description:  property.Description,
+		ExternalRef:  property.ExternalRef,
+		Floor:        property.Floor,
+	}
+}
+
+// RestoreColumns are the columns a restore writes back. Status is left out,
+// it only changes through the lifecycle transitions.
+func (s PropertySnapshot) RestoreColumns() map[string]interface{} {
+	columns := map[string]interface{}{
+		"title":         s.Title,
+		"purpose":       s.Purpose,
+		"price":         s.Price,
+		"property_type": s.PropertyType,
+		"bedrooms":      s.Bedrooms,
+		"bathrooms":     s.Bathrooms,
+		"size":          s.Size,
+		"built_year":    s.BuiltYear,
+		"country_id":    s.CountryID,
+		"division_id":   s.DivisionID,
+		"district_id":   s.DistrictID,
+		"address":       s.Address,
+		"latitude":      s.Latitude,
+		"longitude":     s.Longitude,
+		"description":   s.Description,
+		"floor":         s.Floor,
+	}
+
+	// Versions from before listings had a currency keep the current one
+	if s.Currency != "" {
+		columns["currency"] = s.Currency
+	}
+	return columns
+}
+
+// PropertyFeatureSnapshot is the version of a listing's features kept in the audit trail
+type PropertyFeatureSnapshot struct {
+	Features          pq.StringArray    `json:"features"`
+	Amenities         Amenities         `json:"amenities"`
+	SecurityFeature   SecurityFeature   `json:"securityFeature"`
+	TechnologyFeature TechnologyFeature `json:"technologyFeature"`
+	LuxuryFeature     LuxuryFeature     `json:"luxuryFeature"`
+	CommunityFeature  CommunityFeature  `json:"communityFeature"`
+	UtilsFeature      UtilsFeature      `json:"utilsFeature"`
+	EnergyFeature     EnergyFeature     `json:"energyFeature"`
+}
+
+func NewPropertyFeatureSnapshot(feature PropertyFeature) PropertyFeatureSnapshot {
+	return PropertyFeatureSnapshot{
+		Features:          feature.Features,
+		Amenities:         feature.AmenitiesData,
+		SecurityFeature:   feature.SecurityFeatureData,
+		TechnologyFeature: feature.TechnologyFeatureData,
+		LuxuryFeature:     feature.LuxuryFeatureData,
+		CommunityFeature:  feature.CommunityFeatureData,
+		UtilsFeature:      feature.UtilsFeatureData,
+		EnergyFeature:     feature.EnergyFeatureData,
+	}
+}
+
+// Apply copies the snapshot onto a feature row; BeforeSave encodes the jsonb columns
+func (s PropertyFeatureSnapshot) Apply(feature *PropertyFeature) {
+	feature.Features = s.Features
+	feature.AmenitiesData = s.Amenities
+	feature.SecurityFeatureData = s.SecurityFeature
+	feature.TechnologyFeatureData = s.TechnologyFeature
+	feature.LuxuryFeatureData = s.LuxuryFeature
+	feature.CommunityFeatureData = s.CommunityFeature
+	feature.UtilsFeatureData = s.UtilsFeature
+	feature.EnergyFeatureData = s.EnergyFeature
+}
+
+// RentalTermsSnapshot is the version of a listing's rental terms kept in the audit trail
+type RentalTermsSnapshot struct {
+	RentPeriod       RentPeriod       `json:"rent_period"`
+	SecurityDeposit  money.Amount     `json:"security_deposit"`
+	AdvanceMonths    int              `json:"advance_months"`
+	ServiceCharge    money.Amount     `json:"service_charge"`
+	MinLeaseMonths   int              `json:"min_lease_months"`
+	AvailableFrom    *time.Time       `json:"available_from"`
+	TenantPreference TenantPreference `json:"tenant_preference"`
+}
+
+func NewRentalTermsSnapshot(terms PropertyRentalTerms) RentalTermsSnapshot {
+	return RentalTermsSnapshot{
+		RentPeriod:       terms.RentPeriod,
+		SecurityDeposit:  terms.SecurityDeposit,
+		AdvanceMonths:    terms.AdvanceMonths,
+		ServiceCharge:    terms.ServiceCharge,
+		MinLeaseMonths:   terms.MinLeaseMonths,
+		AvailableFrom:    terms.AvailableFrom,
+		TenantPreference: terms.TenantPreference,
+	}
+}
+
+// Apply copies the snapshot onto a rental terms row
+func (s RentalTermsSnapshot) Apply(terms *PropertyRentalTerms) {
+	terms.RentPeriod = s.RentPeriod
+	terms.SecurityDeposit = s.SecurityDeposit
+	terms.AdvanceMonths = s.AdvanceMonths
+	terms.ServiceCharge = s.ServiceCharge
+	terms.MinLeaseMonths = s.MinLeaseMonths
+	terms.AvailableFrom = s.AvailableFrom
+	terms.TenantPreference = s.TenantPreference
+}
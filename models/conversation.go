@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+type ParticipantRole string
+
+const (
+	ParticipantCustomer ParticipantRole = "customer"
+	ParticipantOwner    ParticipantRole = "owner"
+)
+
+// Conversation is the message thread between a customer and the owner of a
+// property. There is one per customer and property.
+type Conversation struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	PropertyID    uint       `gorm:"uniqueIndex:idx_conversation_property_customer;not null" json:"property_id"`
+	Property      Property   `gorm:"foreignKey:PropertyID" json:"property"`
+	CustomerID    uint       `gorm:"uniqueIndex:idx_conversation_property_customer;not null" json:"customer_id"`
+	LastMessageAt *time.Time `gorm:"index" json:"last_message_at"`
+
+	Participants []ConversationParticipant `gorm:"foreignKey:ConversationID" json:"participants"`
+}
+
+// ConversationParticipant holds one side's read state. UnreadCount is kept as a
+// counter so inbox badges do not need to count messages.
+type ConversationParticipant struct {
+	ID                uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConversationID    uint            `gorm:"uniqueIndex:idx_participant_conversation_user;not null" json:"conversation_id"`
+	UserID            uint            `gorm:"uniqueIndex:idx_participant_conversation_user;index;not null" json:"user_id"`
+	User              User            `gorm:"foreignKey:UserID" json:"user"`
+	Role              ParticipantRole `gorm:"type:varchar(20);not null" json:"role"`
+	UnreadCount       int             `gorm:"not null;default:0" json:"unread_count"`
+	LastReadMessageID uint            `gorm:"not null;default:0" json:"last_read_message_id"`
+	LastReadAt        *time.Time      `json:"last_read_at"`
+	// ShareContact is this side's consent to show phone numbers and emails
+	ShareContact bool `gorm:"default:false" json:"share_contact"`
+}
+
+type Message struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+	ConversationID uint      `gorm:"index;not null" json:"conversation_id"`
+	SenderID       uint      `gorm:"not null" json:"sender_id"`
+	Body           string    `gorm:"type:text;not null" json:"body"`
+	// HasContactInfo is set when the body contains a phone number or email
+	HasContactInfo bool `gorm:"default:false" json:"has_contact_info"`
+}
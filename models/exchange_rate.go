@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+)
+
+// ExchangeRate is what one unit of Currency is worth in BaseCurrency from
+// EffectiveAt on. Rates are never edited: a newer row replaces the previous
+// one, so the table is also the history of every rate used.
+type ExchangeRate struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	BaseCurrency string     `gorm:"type:varchar(3);not null;index:idx_exchange_rate_effective,priority:1" json:"base_currency"`
+	Currency     string     `gorm:"type:varchar(3);not null;index:idx_exchange_rate_effective,priority:2" json:"currency"`
+	Rate         money.Rate `gorm:"type:numeric(20,10);not null" json:"rate"`
+	EffectiveAt  time.Time  `gorm:"not null;index:idx_exchange_rate_effective,priority:3" json:"effective_at"`
+	CreatedByID  *uint      `json:"created_by_id"`
+	CreatedBy    *User      `gorm:"foreignKey:CreatedByID" json:"created_by"`
+}
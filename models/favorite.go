@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Favorite is a property a user shortlisted
+type Favorite struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UserID     uint      `gorm:"uniqueIndex:idx_favorite_user_property;not null" json:"user_id"`
+	PropertyID uint      `gorm:"uniqueIndex:idx_favorite_user_property;index;not null" json:"property_id"`
+	Property   Property  `gorm:"foreignKey:PropertyID" json:"property"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InquiryStatus string
+
+const (
+	InquiryNew     InquiryStatus = "new"
+	InquiryReplied InquiryStatus = "replied"
+	InquiryClosed  InquiryStatus = "closed"
+)
+
+// Inquiry is a message from a buyer about a listing. Signed in buyers are linked
+// through SenderID; guests only leave their contact details.
+type Inquiry struct {
+	gorm.Model
+	PropertyID uint          `gorm:"index;not null" json:"property_id"`
+	Property   Property      `gorm:"foreignKey:PropertyID" json:"property"`
+	OwnerID    uint          `gorm:"index:idx_inquiry_owner_status,priority:1;not null" json:"owner_id"`
+	SenderID   *uint         `gorm:"index" json:"sender_id"`
+	Sender     *User         `gorm:"foreignKey:SenderID" json:"sender"`
+	Name       string        `gorm:"type:varchar(100);not null" json:"name"`
+	Email      string        `gorm:"type:varchar(255);not null" json:"email"`
+	Phone      string        `gorm:"type:varchar(20)" json:"phone"`
+	Message    string        `gorm:"type:text;not null" json:"message"`
+	Status     InquiryStatus `gorm:"type:varchar(20);default:new;index:idx_inquiry_owner_status,priority:2" json:"status"`
+	// IPHash is kept for rate limiting only
+	IPHash    string     `gorm:"type:char(64);index" json:"-"`
+	RepliedAt *time.Time `json:"replied_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+
+	Replies []InquiryReply `gorm:"foreignKey:InquiryID" json:"replies"`
+}
+
+type InquiryReply struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	InquiryID uint      `gorm:"index;not null" json:"inquiry_id"`
+	AuthorID  uint      `gorm:"not null" json:"author_id"`
+	Message   string    `gorm:"type:text;not null" json:"message"`
+}
@@ -31,4 +31,10 @@ type District struct {
 	DivisionId uint32    `gorm:"index" json:"division_id"`
 	Division   Division  `gorm:"foreignKey:DivisionId" json:"division"`
 	Status     bool      `gorm:"default:true" json:"status"`
+
+	// Optional center point and radius, used to check that property coordinates
+	// really fall inside the district
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	RadiusKm  *float64 `json:"radius_km"`
 }
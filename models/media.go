@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MediaStatus string
+
+const (
+	MediaPending  MediaStatus = "pending"
+	MediaUploaded MediaStatus = "uploaded"
+)
+
+type MediaProcessingStatus string
+
+const (
+	ProcessingPending    MediaProcessingStatus = "pending"
+	ProcessingInProgress MediaProcessingStatus = "processing"
+	ProcessingReady      MediaProcessingStatus = "ready"
+	ProcessingFailed     MediaProcessingStatus = "failed"
+)
+
+// PropertyMedia is a photo of a listing or, with ProjectID, of a project. Rows
+// are created when an upload URL is handed out and only become part of the
+// gallery once the upload is confirmed.
+type PropertyMedia struct {
+	gorm.Model
+	PropertyID  *uint       `gorm:"index" json:"property_id"`
+	ProjectID   *uint       `gorm:"index" json:"project_id"`
+	S3Key       string      `gorm:"type:varchar(512);uniqueIndex;not null" json:"s3_key"`
+	ContentType string      `gorm:"type:varchar(100);not null" json:"content_type"`
+	SizeBytes   int64       `gorm:"not null" json:"size_bytes"`
+	Width       int         `json:"width"`
+	Height      int         `json:"height"`
+	SortOrder   int         `gorm:"default:0" json:"sort_order"`
+	Caption     string      `gorm:"type:varchar(255)" json:"caption"`
+	IsCover     bool        `gorm:"default:false" json:"is_cover"`
+	Status      MediaStatus `gorm:"type:varchar(20);default:pending;index" json:"status"`
+
+	// filled in by the image processing worker
+	ProcessingStatus    MediaProcessingStatus    `gorm:"type:varchar(20);default:pending;index" json:"processing_status"`
+	ProcessingError     *string                  `gorm:"type:text" json:"processing_error"`
+	ProcessingStartedAt *time.Time               `json:"processing_started_at"`
+	ProcessedAt         *time.Time               `json:"processed_at"`
+	BlurHash            string                   `gorm:"type:varchar(64)" json:"blur_hash"`
+	DominantColor       string                   `gorm:"type:varchar(7)" json:"dominant_color"`
+	Renditions          []PropertyMediaRendition `gorm:"foreignKey:MediaID" json:"renditions"`
+}
+
+// PropertyMediaRendition is a resized copy of a photo stored next to the original
+type PropertyMediaRendition struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	MediaID     uint      `gorm:"uniqueIndex:idx_media_rendition;not null" json:"media_id"`
+	Name        string    `gorm:"type:varchar(20);uniqueIndex:idx_media_rendition;not null" json:"name"`
+	S3Key       string    `gorm:"type:varchar(512);not null" json:"s3_key"`
+	ContentType string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	SizeBytes   int64     `json:"size_bytes"`
+	Watermarked bool      `gorm:"default:false" json:"watermarked"`
+}
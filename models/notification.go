@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// NotificationType names a domain event users can be notified about. The same
+// name is used as the event type on the real-time stream.
+type NotificationType string
+
+const (
+	NotificationInquiryReceived  NotificationType = "inquiry.received"
+	NotificationInquiryReplied   NotificationType = "inquiry.replied"
+	NotificationListingApproved  NotificationType = "listing.approved"
+	NotificationListingRejected  NotificationType = "listing.rejected"
+	NotificationListingUpdated   NotificationType = "listing.updated"
+	NotificationMessageReceived  NotificationType = "message.received"
+	NotificationSavedSearchMatch NotificationType = "saved_search.match"
+	NotificationFavoritePrice    NotificationType = "favorite.price_dropped"
+	NotificationFavoriteStatus   NotificationType = "favorite.status_changed"
+)
+
+// NotificationChannels says where a notification is delivered
+type NotificationChannels struct {
+	InApp bool `json:"in_app"`
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+}
+
+// DefaultNotificationChannels applies until a user changes their preferences.
+// Messages are not emailed by default since the conversation is in the app.
+var DefaultNotificationChannels = map[NotificationType]NotificationChannels{
+	NotificationInquiryReceived:  {InApp: true, Email: true},
+	NotificationInquiryReplied:   {InApp: true, Email: true},
+	NotificationListingApproved:  {InApp: true, Email: true},
+	NotificationListingRejected:  {InApp: true, Email: true},
+	NotificationListingUpdated:   {InApp: true, Email: true},
+	NotificationMessageReceived:  {InApp: true},
+	NotificationSavedSearchMatch: {InApp: true, Email: true},
+	NotificationFavoritePrice:    {InApp: true, Email: true},
+	NotificationFavoriteStatus:   {InApp: true, Email: true},
+}
+
+// NotificationTypes lists the types in the order they are shown to users
+var NotificationTypes = []NotificationType{
+	NotificationInquiryReceived,
+	NotificationInquiryReplied,
+	NotificationListingApproved,
+	NotificationListingRejected,
+	NotificationListingUpdated,
+	NotificationMessageReceived,
+	NotificationSavedSearchMatch,
+	NotificationFavoritePrice,
+	NotificationFavoriteStatus,
+}
+
+func (t NotificationType) IsValid() bool {
+	_, ok := DefaultNotificationChannels[t]
+	return ok
+}
+
+// Notification is an entry in a user's notification center
+type Notification struct {
+	ID        uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time        `gorm:"default:CURRENT_TIMESTAMP;index:idx_notification_user_created,priority:2" json:"created_at"`
+	UserID    uint             `gorm:"index:idx_notification_user_created,priority:1;not null" json:"user_id"`
+	Type      NotificationType `gorm:"type:varchar(50);not null" json:"type"`
+	Title     string           `gorm:"size:255;not null" json:"title"`
+	Body      string           `gorm:"type:text" json:"body"`
+	Payload   []byte           `gorm:"type:jsonb" json:"-"`
+	ReadAt    *time.Time       `gorm:"index" json:"read_at"`
+}
+
+// NotificationPreference overrides the default channels of one type for a user
+type NotificationPreference struct {
+	ID        uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint             `gorm:"uniqueIndex:idx_notification_preference_user_type;not null" json:"user_id"`
+	Type      NotificationType `gorm:"uniqueIndex:idx_notification_preference_user_type;type:varchar(50);not null" json:"type"`
+	InApp     bool             `gorm:"not null" json:"in_app"`
+	Email     bool             `gorm:"not null" json:"email"`
+	SMS       bool             `gorm:"not null" json:"sms"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Partner is an external portal that pulls the listing feeds with its own
+// token. Purpose and CountryID, when set, narrow the listings it receives.
+type Partner struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Name      string    `gorm:"type:varchar(150);not null" json:"name"`
+	// TokenHash is the SHA-256 of the token; the token itself is only shown when issued
+	TokenHash string          `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Active    bool            `gorm:"default:true" json:"active"`
+	Purpose   *PropertyString `gorm:"type:varchar(20)" json:"purpose"`
+	CountryID *uint32         `json:"country_id"`
+
+	// FeedGeneratedAt is the time the cached feeds are up to date with, nil
+	// until they are first built; FeedClaimedAt is when a build started
+	FeedGeneratedAt *time.Time `json:"feed_generated_at"`
+	FeedClaimedAt   *time.Time `json:"-"`
+	LastFetchedAt   *time.Time `json:"last_fetched_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+)
+
+// PropertyPriceChange is one entry of a listing's price timeline
+type PropertyPriceChange struct {
+	ID          uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	PropertyID  uint         `gorm:"index:idx_price_change_property_changed,priority:1;not null" json:"property_id"`
+	OldPrice    money.Amount `gorm:"type:numeric(16,2);not null" json:"old_price"`
+	NewPrice    money.Amount `gorm:"type:numeric(16,2);not null" json:"new_price"`
+	Currency    string       `gorm:"type:varchar(3);not null" json:"currency"`
+	ChangedAt   time.Time    `gorm:"index:idx_price_change_property_changed,priority:2;not null" json:"changed_at"`
+	ChangedByID *uint        `json:"changed_by_id"`
+}
+
+// IsDrop reports whether the price went down
+func (c PropertyPriceChange) IsDrop() bool {
+	return c.NewPrice < c.OldPrice
+}
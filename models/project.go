@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Project is a building or development whose units are listed one by one. The
+// units share its location, amenities and photos; a unit is a Property with
+// ProjectID set that only keeps its own price, size, rooms and floor.
+type Project struct {
+	gorm.Model
+	OwnerID     uint   `gorm:"index;not null" json:"owner_id"`
+	Owner       User   `gorm:"foreignKey:OwnerID" json:"owner"`
+	Name        string `gorm:"type:varchar(255);not null" json:"name"`
+	Description string `gorm:"type:text;not null" json:"description"`
+
+	CountryID  uint32   `json:"country_id"`
+	Country    Country  `gorm:"foreignKey:CountryID" json:"country"`
+	DivisionID uint32   `json:"division_id"`
+	Division   Division `gorm:"foreignKey:DivisionID" json:"division"`
+	DistrictID uint32   `gorm:"index" json:"district_id"`
+	District   District `gorm:"foreignKey:DistrictID" json:"district"`
+
+	Address   string   `gorm:"type:varchar(255);not null" json:"address"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+
+	// CompletionDate is when the building is (or was) handed over
+	CompletionDate *time.Time `gorm:"type:date" json:"completion_date"`
+	TotalFloors    int        `json:"total_floors"`
+
+	// Shared amenities, stored like PropertyFeature
+	Features         pq.StringArray `gorm:"type:text[]" json:"features"`
+	Amenities        []byte         `gorm:"type:jsonb" json:"-"`
+	SecurityFeature  []byte         `gorm:"type:jsonb" json:"-"`
+	CommunityFeature []byte         `gorm:"type:jsonb" json:"-"`
+	UtilsFeature     []byte         `gorm:"type:jsonb" json:"-"`
+	EnergyFeature    []byte         `gorm:"type:jsonb" json:"-"`
+
+	AmenitiesData        Amenities        `gorm:"-" json:"amenities"`
+	SecurityFeatureData  SecurityFeature  `gorm:"-" json:"securityFeature"`
+	CommunityFeatureData CommunityFeature `gorm:"-" json:"communityFeature"`
+	UtilsFeatureData     UtilsFeature     `gorm:"-" json:"utilsFeature"`
+	EnergyFeatureData    EnergyFeature    `gorm:"-" json:"energyFeature"`
+
+	Media []PropertyMedia `gorm:"foreignKey:ProjectID" json:"media"`
+}
+
+// featureColumns pairs each jsonb column with the struct it holds
+func (p *Project) featureColumns() map[*[]byte]interface{} {
+	return map[*[]byte]interface{}{
+		&p.Amenities:        &p.AmenitiesData,
+		&p.SecurityFeature:  &p.SecurityFeatureData,
+		&p.CommunityFeature: &p.CommunityFeatureData,
+		&p.UtilsFeature:     &p.UtilsFeatureData,
+		&p.EnergyFeature:    &p.EnergyFeatureData,
+	}
+}
+
+func (p *Project) AfterFind(tx *gorm.DB) error {
+	for column, data := range p.featureColumns() {
+		if len(*column) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(*column, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Project) BeforeSave(tx *gorm.DB) error {
+	for column, data := range p.featureColumns() {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		*column = encoded
+	}
+	return nil
+}
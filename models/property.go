@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/farhapartex/real_estate_be/lib/money"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
@@ -15,27 +16,27 @@ const (
 	PurposeRent PropertyString = "rent"
 )
 
-type PropertyStatus string
-
-const (
-	StatusActive  PropertyStatus = "active"
-	StatusDraft   PropertyStatus = "draft"
-	StatusPending PropertyStatus = "pending"
-)
-
 type Property struct {
 	gorm.Model
-	OwnerID      uint           `json:"owner_id"`
+	OwnerID      uint           `gorm:"uniqueIndex:idx_property_owner_external_ref,priority:1" json:"owner_id"`
 	Owner        User           `gorm:"foreignKey:OwnerID" json:"owner"`
 	Title        string         `gorm:"type:varchar(255);not null" json:"title"`
 	Purpose      PropertyString `gorm:"type:varchar(20);not null" json:"purpose"`
-	Price        float64        `gorm:"not null" json:"price"`
-	Status       PropertyStatus `gorm:"type:varchar(20);default:draft" json:"status"`
+	Price        money.Amount   `gorm:"type:numeric(16,2);not null" json:"price"`
+	Currency     string         `gorm:"type:varchar(3);not null;index" json:"currency"` // ISO 4217 code of Price
+	Status       PropertyStatus `gorm:"type:varchar(20);default:draft;check:chk_properties_status,status IN ('draft','pending_review','active','rejected','under_offer','sold','rented','expired','archived')" json:"status"`
 	PropertyType string         `gorm:"type:varchar(50);not null" json:"property_type"`
 	Bedrooms     int            `gorm:"not null" json:"bedrooms"`
 	Bathrooms    int            `gorm:"not null" json:"bathrooms"`
 	Size         float64        `gorm:"not null" json:"size"`
 	BuiltYear    int            `json:"built_year"`
+	// ExternalRef is the owner's own reference, unique per owner
+	ExternalRef *string `gorm:"type:varchar(100);uniqueIndex:idx_property_owner_external_ref,priority:2" json:"external_ref"`
+
+	// ProjectID is set on the units of a project, which take its location
+	ProjectID *uint    `gorm:"index" json:"project_id"`
+	Project   *Project `gorm:"foreignKey:ProjectID" json:"project"`
+	Floor     *int     `json:"floor"`
 
 	CountryID  uint32   `json:"country_id"`
 	Country    Country  `gorm:"foreignKey:CountryID" json:"country"`
@@ -44,12 +45,36 @@ type Property struct {
 	DistrictID uint32   `json:"district_id"`
 	District   District `gorm:"foreignKey:DistrictID" json:"district"`
 
-	Address string `gorm:"type:varchar(255);not null" json:"address"`
-
-	Description  string     `gorm:"type:text;not null" json:"description"`
-	ApprovedAt   *time.Time `json:"approved_at"`
-	ApprovedByID *uint      `json:"approved_by_id"`
-	ApprovedBy   *User      `gorm:"foreignKey:ApprovedByID" json:"approved_by"`
+	Address   string   `gorm:"type:varchar(255);not null" json:"address"`
+	Latitude  *float64 `gorm:"index:idx_property_geo,priority:1" json:"latitude"`
+	Longitude *float64 `gorm:"index:idx_property_geo,priority:2" json:"longitude"`
+
+	// Distance is only populated by radius searches
+	Distance *float64 `gorm:"->;-:migration" json:"-"`
+	// Views is the all-time view count, loaded from the daily stats when listing
+	Views int64 `gorm:"-" json:"-"`
+	// Inquiries is the number of inquiries received, loaded when listing
+	Inquiries int64 `gorm:"-" json:"-"`
+	// Favorites is how many users shortlisted the listing, IsFavorited whether
+	// the current user did; both are loaded when listing
+	Favorites   int64 `gorm:"-" json:"-"`
+	IsFavorited bool  `gorm:"-" json:"-"`
+	// OriginalPrice is the price before the first recorded change, nil if the
+	// price never changed; loaded for the "reduced by" badge
+	OriginalPrice *money.Amount `gorm:"-" json:"-"`
+
+	Description     string     `gorm:"type:text;not null" json:"description"`
+	SubmittedAt     *time.Time `json:"submitted_at"`
+	ApprovedAt      *time.Time `json:"approved_at"`
+	ApprovedByID    *uint      `json:"approved_by_id"`
+	ApprovedBy      *User      `gorm:"foreignKey:ApprovedByID" json:"approved_by"`
+	RejectedAt      *time.Time `json:"rejected_at"`
+	RejectionReason *string    `gorm:"type:text" json:"rejection_reason"`
+
+	Media        []PropertyMedia       `gorm:"foreignKey:PropertyID" json:"media"`
+	PriceHistory []PropertyPriceChange `gorm:"foreignKey:PropertyID" json:"price_history"`
+	// RentalTerms are only kept for rent listings
+	RentalTerms *PropertyRentalTerms `gorm:"foreignKey:PropertyID" json:"rental_terms"`
 }
 
 type Amenities struct {
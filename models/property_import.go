@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+type PropertyImportStatus string
+
+const (
+	ImportPending    PropertyImportStatus = "pending"
+	ImportProcessing PropertyImportStatus = "processing"
+	ImportCompleted  PropertyImportStatus = "completed"
+	ImportFailed     PropertyImportStatus = "failed"
+)
+
+type ImportRowResult string
+
+const (
+	ImportRowCreated ImportRowResult = "created"
+	ImportRowUpdated ImportRowResult = "updated"
+	ImportRowFailed  ImportRowResult = "failed"
+)
+
+// PropertyImport is an uploaded CSV or XLSX file of listings, processed in the
+// background. A dry run validates every row without writing listings; its
+// row results say what a real import would do.
+type PropertyImport struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	OwnerID   uint      `gorm:"index;not null" json:"owner_id"`
+	FileName  string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	Format    string    `gorm:"type:varchar(10);not null" json:"format"`
+	// FileKey is where the upload is stored until it has been processed
+	FileKey string `gorm:"type:varchar(512);not null" json:"-"`
+	DryRun  bool   `gorm:"default:false" json:"dry_run"`
+	// RequestID is the upload request, recorded on the audit trail of every listing written
+	RequestID   string               `gorm:"type:varchar(64)" json:"request_id"`
+	Status      PropertyImportStatus `gorm:"type:varchar(20);default:pending;index" json:"status"`
+	TotalRows   int                  `gorm:"default:0" json:"total_rows"`
+	CreatedRows int                  `gorm:"default:0" json:"created_rows"`
+	UpdatedRows int                  `gorm:"default:0" json:"updated_rows"`
+	FailedRows  int                  `gorm:"default:0" json:"failed_rows"`
+	Error       *string              `gorm:"type:text" json:"error"`
+	StartedAt   *time.Time           `json:"started_at"`
+	FinishedAt  *time.Time           `json:"finished_at"`
+}
+
+// PropertyImportRow is the outcome of one data row of an import
+type PropertyImportRow struct {
+	ID          uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	ImportID    uint            `gorm:"uniqueIndex:idx_import_row;not null" json:"import_id"`
+	RowNumber   int             `gorm:"uniqueIndex:idx_import_row;not null" json:"row_number"`
+	ExternalRef *string         `gorm:"type:varchar(100)" json:"external_ref"`
+	Result      ImportRowResult `gorm:"type:varchar(20);not null" json:"result"`
+	PropertyID  *uint           `json:"property_id"`
+	Error       *string         `gorm:"type:text" json:"error"`
+}
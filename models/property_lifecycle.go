@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type PropertyStatus string
+
+const (
+	StatusDraft         PropertyStatus = "draft"
+	StatusPendingReview PropertyStatus = "pending_review"
+	StatusActive        PropertyStatus = "active"
+	StatusRejected      PropertyStatus = "rejected"
+	StatusUnderOffer    PropertyStatus = "under_offer"
+	StatusSold          PropertyStatus = "sold"
+	StatusRented        PropertyStatus = "rented"
+	StatusExpired       PropertyStatus = "expired"
+	StatusArchived      PropertyStatus = "archived"
+)
+
+// TransitionActor is who is allowed to move a property between two statuses
+type TransitionActor string
+
+const (
+	ActorOwner  TransitionActor = "owner"
+	ActorAdmin  TransitionActor = "admin"
+	ActorSystem TransitionActor = "system"
+)
+
+// propertyTransitions is the single source of truth for the listing lifecycle:
+// from status -> to status -> actors allowed to perform it
+var propertyTransitions = map[PropertyStatus]map[PropertyStatus][]TransitionActor{
+	StatusDraft: {
+		StatusPendingReview: {ActorOwner, ActorAdmin},
+		StatusArchived:      {ActorOwner, ActorAdmin},
+	},
+	StatusPendingReview: {
+		StatusActive:   {ActorAdmin},
+		StatusRejected: {ActorAdmin},
+		StatusDraft:    {ActorOwner},
+	},
+	StatusRejected: {
+		StatusPendingReview: {ActorOwner},
+		StatusDraft:         {ActorOwner},
+		StatusArchived:      {ActorOwner, ActorAdmin},
+	},
+	StatusActive: {
+		StatusPendingReview: {ActorSystem, ActorAdmin},
+		StatusUnderOffer:    {ActorOwner},
+		StatusSold:          {ActorOwner, ActorAdmin},
+		StatusRented:        {ActorOwner, ActorAdmin},
+		StatusExpired:       {ActorSystem, ActorAdmin},
+		StatusArchived:      {ActorOwner, ActorAdmin},
+	},
+	StatusUnderOffer: {
+		StatusActive:   {ActorOwner},
+		StatusSold:     {ActorOwner, ActorAdmin},
+		StatusRented:   {ActorOwner, ActorAdmin},
+		StatusArchived: {ActorOwner, ActorAdmin},
+	},
+	StatusSold: {
+		StatusArchived: {ActorOwner, ActorAdmin},
+	},
+	StatusRented: {
+		StatusDraft:    {ActorOwner},
+		StatusArchived: {ActorOwner, ActorAdmin},
+	},
+	StatusExpired: {
+		StatusPendingReview: {ActorOwner},
+		StatusArchived:      {ActorOwner, ActorAdmin},
+	},
+	StatusArchived: {
+		StatusDraft: {ActorOwner, ActorAdmin},
+	},
+}
+
+// SearchableStatuses are the statuses shown in public search
+var SearchableStatuses = []PropertyStatus{StatusActive, StatusUnderOffer}
+
+func (s PropertyStatus) IsValid() bool {
+	_, ok := propertyTransitions[s]
+	return ok
+}
+
+// IsSearchable reports whether listings in this status are publicly visible
+func (s PropertyStatus) IsSearchable() bool {
+	for _, status := range SearchableStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePropertyTransition checks a status change against the lifecycle rules
+func ValidatePropertyTransition(from, to PropertyStatus, purpose PropertyString, actor TransitionActor) error {
+	if !to.IsValid() {
+		return errors.New("unknown property status")
+	}
+
+	actors, ok := propertyTransitions[from][to]
+	if !ok {
+		return errors.New("cannot move property from " + string(from) + " to " + string(to))
+	}
+
+	allowed := false
+	for _, a := range actors {
+		if a == actor {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return errors.New("not allowed to move property from " + string(from) + " to " + string(to))
+	}
+
+	if to == StatusSold && purpose != PurposeSale {
+		return errors.New("only sale listings can be marked as sold")
+	}
+
+	if to == StatusRented && purpose != PurposeRent {
+		return errors.New("only rent listings can be marked as rented")
+	}
+
+	return nil
+}
+
+// PropertyStatusHistory records every lifecycle transition of a property
+type PropertyStatusHistory struct {
+	ID         uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt  time.Time       `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	PropertyID uint            `gorm:"index;not null" json:"property_id"`
+	FromStatus PropertyStatus  `gorm:"type:varchar(20);not null" json:"from_status"`
+	ToStatus   PropertyStatus  `gorm:"type:varchar(20);not null" json:"to_status"`
+	ActorType  TransitionActor `gorm:"type:varchar(20);not null" json:"actor_type"`
+	ActorID    *uint           `json:"actor_id"`
+	Actor      *User           `gorm:"foreignKey:ActorID" json:"actor"`
+	Reason     *string         `gorm:"type:text" json:"reason"`
+}
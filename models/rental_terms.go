@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+)
+
+type RentPeriod string
+
+const (
+	RentPeriodMonthly RentPeriod = "monthly"
+	RentPeriodYearly  RentPeriod = "yearly"
+)
+
+type TenantPreference string
+
+const (
+	TenantFamily   TenantPreference = "family"
+	TenantBachelor TenantPreference = "bachelor"
+	TenantAny      TenantPreference = "any"
+)
+
+// PropertyRentalTerms are the terms of a rent listing. Amounts are in the
+// listing currency; the price of the listing is the rent per RentPeriod.
+type PropertyRentalTerms struct {
+	ID              uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+	PropertyID      uint         `gorm:"uniqueIndex;not null" json:"property_id"`
+	RentPeriod      RentPeriod   `gorm:"type:varchar(10);not null;check:chk_property_rental_terms_rent_period,rent_period IN ('monthly','yearly')" json:"rent_period"`
+	SecurityDeposit money.Amount `gorm:"type:numeric(16,2);not null;default:0" json:"security_deposit"`
+	// AdvanceMonths is how many months of rent are paid up front
+	AdvanceMonths int `gorm:"not null;default:0" json:"advance_months"`
+	// ServiceCharge is paid every rent period on top of the rent
+	ServiceCharge  money.Amount `gorm:"type:numeric(16,2);not null;default:0" json:"service_charge"`
+	MinLeaseMonths int          `gorm:"not null;default:0" json:"min_lease_months"`
+	// AvailableFrom is the move-in date, nil when it is available right away
+	AvailableFrom    *time.Time       `gorm:"type:date;index" json:"available_from"`
+	TenantPreference TenantPreference `gorm:"type:varchar(10);not null;default:any;check:chk_property_rental_terms_tenant_preference,tenant_preference IN ('family','bachelor','any')" json:"tenant_preference"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/farhapartex/real_estate_be/lib/money"
+)
+
+type AlertFrequency string
+
+const (
+	AlertInstant AlertFrequency = "instant"
+	AlertDaily   AlertFrequency = "daily"
+	AlertWeekly  AlertFrequency = "weekly"
+)
+
+// Interval is the minimum time between two digests of a saved search
+func (f AlertFrequency) Interval() time.Duration {
+	switch f {
+	case AlertDaily:
+		return 24 * time.Hour
+	case AlertWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// SearchCriteria are the property search filters a user saved. Zero values mean
+// "any", like in the search itself.
+type SearchCriteria struct {
+	Purpose      string       `gorm:"type:varchar(20)" json:"purpose"`
+	PropertyType string       `gorm:"type:varchar(50)" json:"property_type"`
+	MinPrice     money.Amount `gorm:"type:numeric(16,2)" json:"min_price"`
+	MaxPrice     money.Amount `gorm:"type:numeric(16,2)" json:"max_price"`
+	Currency     string       `gorm:"type:varchar(3);not null" json:"currency"` // of MinPrice and MaxPrice
+	BedRooms     int          `json:"bedrooms"`
+	BathRooms    int          `json:"bathrooms"`
+	MinSize      float64      `json:"min_size"`
+	MaxSize      float64      `json:"max_size"`
+	CountryID    uint32       `json:"country_id"`
+	DivisionID   uint32       `json:"division_id"`
+	DistrictID   uint32       `json:"district_id"`
+	Near         string       `gorm:"type:varchar(64)" json:"near"`
+	RadiusKm     float64      `json:"radius_km"`
+	BBox         string       `gorm:"type:varchar(128)" json:"bbox"`
+
+	// Rental terms; any of them limits matches to rent listings that have terms
+	RentPeriod       string       `gorm:"type:varchar(10)" json:"rent_period"`
+	TenantPreference string       `gorm:"type:varchar(10)" json:"tenant_preference"`
+	AvailableBefore  string       `gorm:"type:varchar(10)" json:"available_before"` // 2006-01-02
+	MaxDeposit       money.Amount `gorm:"type:numeric(16,2)" json:"max_deposit"`    // in Currency
+	MaxAdvanceMonths int          `json:"max_advance_months"`
+	LeaseMonths      int          `json:"lease_months"` // the minimum lease is at most this long
+}
+
+type SavedSearch struct {
+	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt     time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	UserID        uint           `gorm:"index;not null" json:"user_id"`
+	User          User           `gorm:"foreignKey:UserID" json:"user"`
+	Name          string         `gorm:"size:150;not null" json:"name"`
+	Criteria      SearchCriteria `gorm:"embedded" json:"criteria"`
+	Frequency     AlertFrequency `gorm:"type:varchar(10);not null;default:daily" json:"frequency"`
+	AlertsEnabled bool           `gorm:"not null;default:true" json:"alerts_enabled"`
+	// UnsubscribeToken authorizes the one-click unsubscribe link in alert emails
+	UnsubscribeToken string `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	// MatchedUntil is how far listing changes have been matched against the search
+	MatchedUntil time.Time  `gorm:"not null;index" json:"-"`
+	LastAlertAt  *time.Time `json:"last_alert_at"`
+}
+
+// SavedSearchMatch is a listing that matched a saved search. A listing is only
+// sent once per search, even if it changes and matches again, unless its price
+// drops: then it is queued again with the price it had before.
+type SavedSearchMatch struct {
+	ID            uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt     time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	SavedSearchID uint          `gorm:"uniqueIndex:idx_saved_search_match;not null" json:"saved_search_id"`
+	PropertyID    uint          `gorm:"uniqueIndex:idx_saved_search_match;not null" json:"property_id"`
+	Property      Property      `gorm:"foreignKey:PropertyID" json:"property"`
+	SentAt        *time.Time    `gorm:"index" json:"sent_at"`
+	PreviousPrice *money.Amount `gorm:"type:numeric(16,2)" json:"previous_price"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// StreamTicket opens the event stream for clients that cannot send headers,
+// such as the browser's EventSource. It travels in the query string, so it is
+// short-lived and can be used once.
+type StreamTicket struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"` // SHA-256 of the ticket
+	ExpiresAt time.Time  `gorm:"index;not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
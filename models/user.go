@@ -31,6 +31,11 @@ type User struct {
 	VerifiedAt        *time.Time `json:"verified_at"`
 }
 
+// IsAdmin reports whether the user may use the admin API
+func (u User) IsAdmin() bool {
+	return u.Role == AdminRole || u.IsSuperuser
+}
+
 type OwnerProfile struct {
 	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	UserID      uint      `json:"user_id"`
@@ -40,6 +45,10 @@ type OwnerProfile struct {
 	Website     *string   `gorm:"size:255;default:null" json:"website"`
 	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// WatermarkEnabled lets an agency opt its photos in or out of the site
+	// watermark. nil follows the site setting.
+	WatermarkEnabled *bool `gorm:"default:null" json:"watermark_enabled"`
 }
 
 type VerificationToken struct {
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type WatermarkSource string
+
+const (
+	WatermarkLogo        WatermarkSource = "logo"
+	WatermarkCompanyName WatermarkSource = "company_name"
+)
+
+// WatermarkSetting holds the site-wide watermark configuration. There is a single
+// row, see WatermarkSettingID.
+type WatermarkSetting struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	Enabled     bool            `gorm:"default:false" json:"enabled"`
+	Source      WatermarkSource `gorm:"type:varchar(20);default:logo" json:"source"`
+	LogoKey     *string         `gorm:"type:varchar(512)" json:"logo_key"`
+	Position    string          `gorm:"type:varchar(20);default:bottom_right" json:"position"`
+	Opacity     float64         `gorm:"default:0.5" json:"opacity"`
+	UpdatedByID *uint           `json:"updated_by_id"`
+	UpdatedAt   time.Time       `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+const WatermarkSettingID = 1
@@ -2,12 +2,23 @@ package routes
 
 import (
 	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/lib/storage"
 	"github.com/farhapartex/real_estate_be/middlewares"
 	"github.com/farhapartex/real_estate_be/views"
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterRoute(r *gin.Engine, authController *controllers.AuthController) {
+	// presigned uploads and downloads for the local disk storage backend
+	if local, ok := authController.Storage.(*storage.LocalStorage); ok {
+		r.PUT(storage.LocalUploadPath+"*key", func(ctx *gin.Context) {
+			views.LocalStorageUpload(ctx, local)
+		})
+		r.GET(storage.LocalDownloadPath+"*key", func(ctx *gin.Context) {
+			views.LocalStorageDownload(ctx, local)
+		})
+	}
+
 	publicApi := r.Group("/api/v1")
 	{
 		auth := publicApi.Group("/auth")
@@ -36,15 +47,148 @@ func RegisterRoute(r *gin.Engine, authController *controllers.AuthController) {
 			web.GET("/divisions/:division_id/districts", func(ctx *gin.Context) {
 				views.DistrictPublicList(ctx, authController)
 			})
+			web.GET("/exchange-rates", func(ctx *gin.Context) {
+				views.ExchangeRateList(ctx, authController)
+			})
+			web.GET("/saved-searches/unsubscribe", func(ctx *gin.Context) {
+				views.UnsubscribeSavedSearch(ctx, authController)
+			})
+			web.POST("/saved-searches/unsubscribe", func(ctx *gin.Context) {
+				views.UnsubscribeSavedSearch(ctx, authController)
+			})
+			web.GET("/properties", middlewares.OptionalAuthMiddleware(), func(ctx *gin.Context) {
+				views.PublicPropertyList(ctx, authController)
+			})
+			web.GET("/properties/:id", middlewares.OptionalAuthMiddleware(), func(ctx *gin.Context) {
+				views.PublicPropertyDetails(ctx, authController)
+			})
+			web.GET("/projects", func(ctx *gin.Context) {
+				views.PublicProjectList(ctx, authController)
+			})
+			web.GET("/projects/:id", func(ctx *gin.Context) {
+				views.PublicProjectDetails(ctx, authController)
+			})
+			web.POST("/properties/:id/inquiries", middlewares.OptionalAuthMiddleware(), func(ctx *gin.Context) {
+				views.CreateInquiry(ctx, authController)
+			})
+			web.GET("/properties/:id/attachments", middlewares.OptionalAuthMiddleware(), func(ctx *gin.Context) {
+				views.PublicPropertyAttachmentList(ctx, authController)
+			})
+			web.GET("/properties/:id/attachments/:attachment_id/download", middlewares.OptionalAuthMiddleware(), func(ctx *gin.Context) {
+				views.PropertyAttachmentDownload(ctx, authController)
+			})
 		}
 	}
 
+	// listing feeds for partner portals, authenticated with the partner's token
+	feeds := publicApi.Group("/feeds")
+	{
+		feeds.GET("/listings.xml", func(ctx *gin.Context) {
+			views.PartnerFeedXML(ctx, authController)
+		})
+		feeds.GET("/listings.json", func(ctx *gin.Context) {
+			views.PartnerFeedJSON(ctx, authController)
+		})
+	}
+
+	// read-only RESO Web API (OData v4) over the same listings as the partner feeds
+	reso := publicApi.Group("/reso")
+	{
+		reso.GET("", func(ctx *gin.Context) {
+			views.ResoService(ctx, authController)
+		})
+		reso.GET("/$metadata", func(ctx *gin.Context) {
+			views.ResoMetadata(ctx, authController)
+		})
+		reso.GET("/:resource", func(ctx *gin.Context) {
+			views.ResoResource(ctx, authController)
+		})
+	}
+
+	// real-time events; EventSource cannot send headers, so it opens the stream
+	// with a single-use ticket in the query instead of the access token
+	r.GET("/api/v1/stream", middlewares.StreamAuthMiddleware(authController.RedeemStreamTicket), func(ctx *gin.Context) {
+		views.EventStream(ctx, authController)
+	})
+	r.POST("/api/v1/stream/ticket", middlewares.AuthMiddleware(), func(ctx *gin.Context) {
+		views.CreateStreamTicket(ctx, authController)
+	})
+
 	protectedAPI := r.Group("/api/v1")
 	protectedAPI.Use(middlewares.AuthMiddleware())
 	{
 		protectedAPI.GET("/me", func(ctx *gin.Context) {
 			views.Me(ctx, authController)
 		})
+
+		// favorites
+		protectedAPI.GET("/me/favorites", func(ctx *gin.Context) {
+			views.FavoriteList(ctx, authController)
+		})
+		protectedAPI.POST("/me/favorites/:property_id", func(ctx *gin.Context) {
+			views.AddFavorite(ctx, authController)
+		})
+		protectedAPI.DELETE("/me/favorites/:property_id", func(ctx *gin.Context) {
+			views.RemoveFavorite(ctx, authController)
+		})
+
+		// saved searches with alerts
+		protectedAPI.GET("/me/saved-searches", func(ctx *gin.Context) {
+			views.SavedSearchList(ctx, authController)
+		})
+		protectedAPI.POST("/me/saved-searches", func(ctx *gin.Context) {
+			views.CreateSavedSearch(ctx, authController)
+		})
+		protectedAPI.PUT("/me/saved-searches/:id", func(ctx *gin.Context) {
+			views.UpdateSavedSearch(ctx, authController)
+		})
+		protectedAPI.DELETE("/me/saved-searches/:id", func(ctx *gin.Context) {
+			views.DeleteSavedSearch(ctx, authController)
+		})
+
+		// notification center
+		protectedAPI.GET("/me/notifications", func(ctx *gin.Context) {
+			views.NotificationList(ctx, authController)
+		})
+		protectedAPI.POST("/me/notifications/read-all", func(ctx *gin.Context) {
+			views.MarkAllNotificationsRead(ctx, authController)
+		})
+		protectedAPI.POST("/me/notifications/:id/read", func(ctx *gin.Context) {
+			views.MarkNotificationRead(ctx, authController)
+		})
+		protectedAPI.GET("/me/notification-preferences", func(ctx *gin.Context) {
+			views.NotificationPreferences(ctx, authController)
+		})
+		protectedAPI.PUT("/me/notification-preferences", func(ctx *gin.Context) {
+			views.UpdateNotificationPreferences(ctx, authController)
+		})
+
+		// conversations between customers and owners
+		protectedAPI.GET("/conversations", func(ctx *gin.Context) {
+			views.ConversationList(ctx, authController)
+		})
+		protectedAPI.POST("/conversations", func(ctx *gin.Context) {
+			views.StartConversation(ctx, authController)
+		})
+		protectedAPI.GET("/conversations/unread", func(ctx *gin.Context) {
+			views.UnreadMessageCount(ctx, authController)
+		})
+		protectedAPI.GET("/conversations/:id", func(ctx *gin.Context) {
+			views.ConversationDetails(ctx, authController)
+		})
+		protectedAPI.GET("/conversations/:id/messages", func(ctx *gin.Context) {
+			views.ConversationMessageList(ctx, authController)
+		})
+		protectedAPI.POST("/conversations/:id/messages", func(ctx *gin.Context) {
+			views.SendMessage(ctx, authController)
+		})
+		protectedAPI.POST("/conversations/:id/read", func(ctx *gin.Context) {
+			views.MarkConversationRead(ctx, authController)
+		})
+		protectedAPI.PUT("/conversations/:id/contact-sharing", func(ctx *gin.Context) {
+			views.SetContactSharing(ctx, authController)
+		})
+
 		protectedAPI.GET("/admin/countries", func(ctx *gin.Context) {
 			views.CountryList(ctx, authController)
 		})
@@ -97,12 +241,33 @@ func RegisterRoute(r *gin.Engine, authController *controllers.AuthController) {
 		protectedAPI.POST("/owner/properties", func(ctx *gin.Context) {
 			views.CreateProperty(ctx, authController)
 		})
+		protectedAPI.GET("/owner/properties/export", func(ctx *gin.Context) {
+			views.ExportPropertyList(ctx, authController)
+		})
 		protectedAPI.GET("/owner/properties/:id", func(ctx *gin.Context) {
 			views.PropertyDetails(ctx, authController)
 		})
 		protectedAPI.PATCH("/owner/properties/:id", func(ctx *gin.Context) {
 			views.PropertyUpdate(ctx, authController)
 		})
+		protectedAPI.DELETE("/owner/properties/:id", func(ctx *gin.Context) {
+			views.DeleteProperty(ctx, authController)
+		})
+		protectedAPI.GET("/owner/property-imports", func(ctx *gin.Context) {
+			views.PropertyImportList(ctx, authController)
+		})
+		protectedAPI.POST("/owner/property-imports", func(ctx *gin.Context) {
+			views.CreatePropertyImport(ctx, authController)
+		})
+		protectedAPI.GET("/owner/property-imports/columns", func(ctx *gin.Context) {
+			views.PropertyImportColumns(ctx, authController)
+		})
+		protectedAPI.GET("/owner/property-imports/:id", func(ctx *gin.Context) {
+			views.PropertyImportDetails(ctx, authController)
+		})
+		protectedAPI.GET("/owner/property-imports/:id/report", func(ctx *gin.Context) {
+			views.PropertyImportReport(ctx, authController)
+		})
 		protectedAPI.POST("/owner/properties/:id/features", func(ctx *gin.Context) {
 			views.CreatePropertyFeature(ctx, authController)
 		})
@@ -114,5 +279,185 @@ func RegisterRoute(r *gin.Engine, authController *controllers.AuthController) {
 		protectedAPI.DELETE("/owner/properties/:id/features", func(ctx *gin.Context) {
 			views.DeletePropertyFeature(ctx, authController)
 		})
+		protectedAPI.POST("/owner/properties/:id/submit", func(ctx *gin.Context) {
+			views.SubmitPropertyForReview(ctx, authController)
+		})
+		protectedAPI.POST("/owner/properties/:id/transitions", func(ctx *gin.Context) {
+			views.TransitionProperty(ctx, authController, false)
+		})
+		protectedAPI.POST("/owner/properties/:id/archive", func(ctx *gin.Context) {
+			views.ArchiveProperty(ctx, authController)
+		})
+		protectedAPI.GET("/owner/properties/:id/history", func(ctx *gin.Context) {
+			views.PropertyStatusHistory(ctx, authController, false)
+		})
+
+		protectedAPI.GET("/owner/properties/:id/media", func(ctx *gin.Context) {
+			views.PropertyMediaList(ctx, authController)
+		})
+		protectedAPI.POST("/owner/properties/:id/media", func(ctx *gin.Context) {
+			views.CreatePropertyMediaUpload(ctx, authController)
+		})
+		protectedAPI.PUT("/owner/properties/:id/media/order", func(ctx *gin.Context) {
+			views.ReorderPropertyMedia(ctx, authController)
+		})
+		protectedAPI.POST("/owner/properties/:id/media/:media_id/confirm", func(ctx *gin.Context) {
+			views.ConfirmPropertyMediaUpload(ctx, authController)
+		})
+		protectedAPI.POST("/owner/properties/:id/media/:media_id/cover", func(ctx *gin.Context) {
+			views.SetPropertyCover(ctx, authController)
+		})
+		protectedAPI.DELETE("/owner/properties/:id/media/:media_id", func(ctx *gin.Context) {
+			views.DeletePropertyMedia(ctx, authController)
+		})
+
+		// projects, their units are listed with /properties?project_id=
+		protectedAPI.GET("/owner/projects", func(ctx *gin.Context) {
+			views.OwnerProjectList(ctx, authController)
+		})
+		protectedAPI.POST("/owner/projects", func(ctx *gin.Context) {
+			views.CreateProject(ctx, authController)
+		})
+		protectedAPI.GET("/owner/projects/:id", func(ctx *gin.Context) {
+			views.OwnerProjectDetails(ctx, authController)
+		})
+		protectedAPI.PATCH("/owner/projects/:id", func(ctx *gin.Context) {
+			views.UpdateProject(ctx, authController)
+		})
+		protectedAPI.DELETE("/owner/projects/:id", func(ctx *gin.Context) {
+			views.DeleteProject(ctx, authController)
+		})
+		protectedAPI.POST("/owner/projects/:id/units", func(ctx *gin.Context) {
+			views.CreateProjectUnit(ctx, authController)
+		})
+		protectedAPI.GET("/owner/projects/:id/media", func(ctx *gin.Context) {
+			views.ProjectMediaList(ctx, authController)
+		})
+		protectedAPI.POST("/owner/projects/:id/media", func(ctx *gin.Context) {
+			views.CreateProjectMediaUpload(ctx, authController)
+		})
+		protectedAPI.PUT("/owner/projects/:id/media/order", func(ctx *gin.Context) {
+			views.ReorderProjectMedia(ctx, authController)
+		})
+		protectedAPI.POST("/owner/projects/:id/media/:media_id/confirm", func(ctx *gin.Context) {
+			views.ConfirmProjectMediaUpload(ctx, authController)
+		})
+		protectedAPI.POST("/owner/projects/:id/media/:media_id/cover", func(ctx *gin.Context) {
+			views.SetProjectCover(ctx, authController)
+		})
+		protectedAPI.DELETE("/owner/projects/:id/media/:media_id", func(ctx *gin.Context) {
+			views.DeleteProjectMedia(ctx, authController)
+		})
+
+		protectedAPI.GET("/owner/properties/:id/attachments", func(ctx *gin.Context) {
+			views.PropertyAttachmentList(ctx, authController)
+		})
+		protectedAPI.POST("/owner/properties/:id/attachments", func(ctx *gin.Context) {
+			views.CreatePropertyAttachmentUpload(ctx, authController)
+		})
+		protectedAPI.POST("/owner/properties/:id/attachments/:attachment_id/confirm", func(ctx *gin.Context) {
+			views.ConfirmPropertyAttachmentUpload(ctx, authController)
+		})
+		protectedAPI.PATCH("/owner/properties/:id/attachments/:attachment_id", func(ctx *gin.Context) {
+			views.UpdatePropertyAttachment(ctx, authController)
+		})
+		protectedAPI.DELETE("/owner/properties/:id/attachments/:attachment_id", func(ctx *gin.Context) {
+			views.DeletePropertyAttachment(ctx, authController)
+		})
+
+		protectedAPI.GET("/owner/inquiries", func(ctx *gin.Context) {
+			views.OwnerInquiryList(ctx, authController)
+		})
+		protectedAPI.GET("/owner/inquiries/:id", func(ctx *gin.Context) {
+			views.OwnerInquiryDetails(ctx, authController)
+		})
+		protectedAPI.POST("/owner/inquiries/:id/reply", func(ctx *gin.Context) {
+			views.ReplyToInquiry(ctx, authController)
+		})
+		protectedAPI.POST("/owner/inquiries/:id/close", func(ctx *gin.Context) {
+			views.CloseInquiry(ctx, authController)
+		})
+
+		protectedAPI.PUT("/owner/watermark", func(ctx *gin.Context) {
+			views.UpdateOwnerWatermark(ctx, authController)
+		})
+	}
+
+	adminAPI := protectedAPI.Group("/admin")
+	adminAPI.Use(middlewares.AdminMiddleware())
+	{
+		// listing moderation
+		adminAPI.GET("/users/export", func(ctx *gin.Context) {
+			views.ExportUserList(ctx, authController)
+		})
+		adminAPI.GET("/properties/pending", func(ctx *gin.Context) {
+			views.PendingPropertyList(ctx, authController)
+		})
+		adminAPI.POST("/properties/:id/approve", func(ctx *gin.Context) {
+			views.ApproveProperty(ctx, authController)
+		})
+		adminAPI.POST("/properties/:id/reject", func(ctx *gin.Context) {
+			views.RejectProperty(ctx, authController)
+		})
+		adminAPI.GET("/properties/deleted", func(ctx *gin.Context) {
+			views.DeletedPropertyList(ctx, authController)
+		})
+		adminAPI.POST("/properties/:id/restore", func(ctx *gin.Context) {
+			views.RestoreDeletedProperty(ctx, authController)
+		})
+		adminAPI.POST("/properties/:id/transitions", func(ctx *gin.Context) {
+			views.TransitionProperty(ctx, authController, true)
+		})
+		adminAPI.GET("/properties/:id/history", func(ctx *gin.Context) {
+			views.PropertyStatusHistory(ctx, authController, true)
+		})
+		adminAPI.GET("/properties/:id/audit", func(ctx *gin.Context) {
+			views.PropertyAuditLog(ctx, authController)
+		})
+		adminAPI.POST("/properties/:id/audit/:audit_id/restore", func(ctx *gin.Context) {
+			views.RestorePropertyVersion(ctx, authController)
+		})
+
+		// partner portals
+		adminAPI.GET("/partners", func(ctx *gin.Context) {
+			views.PartnerList(ctx, authController)
+		})
+		adminAPI.POST("/partners", func(ctx *gin.Context) {
+			views.CreatePartner(ctx, authController)
+		})
+		adminAPI.PATCH("/partners/:id", func(ctx *gin.Context) {
+			views.UpdatePartner(ctx, authController)
+		})
+		adminAPI.POST("/partners/:id/token", func(ctx *gin.Context) {
+			views.RotatePartnerToken(ctx, authController)
+		})
+		adminAPI.DELETE("/partners/:id", func(ctx *gin.Context) {
+			views.DeletePartner(ctx, authController)
+		})
+
+		// exchange rates, kept as a history
+		adminAPI.GET("/exchange-rates", func(ctx *gin.Context) {
+			views.ExchangeRateList(ctx, authController)
+		})
+		adminAPI.GET("/exchange-rates/history", func(ctx *gin.Context) {
+			views.ExchangeRateHistory(ctx, authController)
+		})
+		adminAPI.POST("/exchange-rates", func(ctx *gin.Context) {
+			views.CreateExchangeRate(ctx, authController)
+		})
+
+		// photo watermarking
+		adminAPI.GET("/watermark", func(ctx *gin.Context) {
+			views.WatermarkSetting(ctx, authController)
+		})
+		adminAPI.PUT("/watermark", func(ctx *gin.Context) {
+			views.UpdateWatermarkSetting(ctx, authController)
+		})
+		adminAPI.POST("/watermark/logo", func(ctx *gin.Context) {
+			views.UploadWatermarkLogo(ctx, authController)
+		})
+		adminAPI.POST("/watermark/rerender", func(ctx *gin.Context) {
+			views.RerenderWatermarks(ctx, authController)
+		})
 	}
 }
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const EarthRadiusKm = 6371.0
+
+type LatLng struct {
+	Lat float64
+	Lng float64
+}
+
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// ValidCoordinates reports whether lat/lng fall inside the WGS84 ranges
+func ValidCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// HaversineKm returns the great-circle distance between two points in kilometers
+func HaversineKm(a, b LatLng) float64 {
+	dLat := toRadians(b.Lat - a.Lat)
+	dLng := toRadians(b.Lng - a.Lng)
+
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(toRadians(a.Lat))*math.Cos(toRadians(b.Lat))*math.Pow(math.Sin(dLng/2), 2)
+
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBoxAround returns the box enclosing a circle of radiusKm around center.
+// It is used to narrow a radius search down to an index range before the exact
+// haversine check runs.
+func BoundingBoxAround(center LatLng, radiusKm float64) BoundingBox {
+	latDelta := radiusKm / EarthRadiusKm * 180 / math.Pi
+
+	cosLat := math.Cos(toRadians(center.Lat))
+	lngDelta := 180.0
+	if cosLat > 1e-9 {
+		lngDelta = math.Min(180, latDelta/cosLat)
+	}
+
+	return BoundingBox{
+		MinLat: math.Max(-90, center.Lat-latDelta),
+		MaxLat: math.Min(90, center.Lat+latDelta),
+		MinLng: math.Max(-180, center.Lng-lngDelta),
+		MaxLng: math.Min(180, center.Lng+lngDelta),
+	}
+}
+
+// ParseLatLng parses a "lat,lng" string
+func ParseLatLng(value string) (LatLng, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return LatLng{}, errors.New("location must be in lat,lng format")
+	}
+
+	numbers, err := parseFloats(parts)
+	if err != nil {
+		return LatLng{}, errors.New("location must be in lat,lng format")
+	}
+
+	point := LatLng{Lat: numbers[0], Lng: numbers[1]}
+	if !ValidCoordinates(point.Lat, point.Lng) {
+		return LatLng{}, errors.New("location is out of range")
+	}
+
+	return point, nil
+}
+
+// ParseBoundingBox parses a "min_lng,min_lat,max_lng,max_lat" string
+func ParseBoundingBox(value string) (BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, errors.New("bbox must be in min_lng,min_lat,max_lng,max_lat format")
+	}
+
+	numbers, err := parseFloats(parts)
+	if err != nil {
+		return BoundingBox{}, errors.New("bbox must be in min_lng,min_lat,max_lng,max_lat format")
+	}
+
+	box := BoundingBox{
+		MinLng: numbers[0],
+		MinLat: numbers[1],
+		MaxLng: numbers[2],
+		MaxLat: numbers[3],
+	}
+
+	if !ValidCoordinates(box.MinLat, box.MinLng) || !ValidCoordinates(box.MaxLat, box.MaxLng) {
+		return BoundingBox{}, errors.New("bbox is out of range")
+	}
+
+	if box.MinLat > box.MaxLat || box.MinLng > box.MaxLng {
+		return BoundingBox{}, errors.New("bbox minimum must not exceed maximum")
+	}
+
+	return box, nil
+}
+
+func parseFloats(parts []string) ([]float64, error) {
+	numbers := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+
+	return numbers, nil
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+	blankLines     = regexp.MustCompile(`\n{3,}`)
+
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// phonePattern matches 9 or more digits starting with + or 0, allowing the usual
+	// separators in between. Requiring the prefix keeps prices from matching.
+	phonePattern = regexp.MustCompile(`(?:\+|\b0)\d(?:[\s\-.()]*\d){7,}`)
+)
+
+// SanitizeMessage turns user input into plain text: HTML tags and control
+// characters are removed and runs of blank lines are collapsed
+func SanitizeMessage(value string) string {
+	value = htmlTagPattern.ReplaceAllString(value, "")
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+
+	value = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, value)
+
+	value = blankLines.ReplaceAllString(value, "\n\n")
+	return strings.TrimSpace(value)
+}
+
+// ContainsContactInfo reports whether value contains an email address or phone number
+func ContainsContactInfo(value string) bool {
+	return emailPattern.MatchString(value) || phonePattern.MatchString(value)
+}
+
+// MaskContactInfo replaces email addresses and phone numbers with mask
+func MaskContactInfo(value, mask string) string {
+	value = emailPattern.ReplaceAllString(value, mask)
+	return phonePattern.ReplaceAllString(value, mask)
+}
@@ -0,0 +1,172 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentRouteParams reads the property id, optional attachment id and current user
+func attachmentRouteParams(ctx *gin.Context, withAttachment bool) (uint32, uint, uint, bool) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return 0, 0, 0, false
+	}
+
+	var attachmentId uint64
+	if withAttachment {
+		attachmentId, err = strconv.ParseUint(ctx.Param("attachment_id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+			return 0, 0, 0, false
+		}
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, 0, false
+	}
+
+	return uint32(propertyId), uint(attachmentId), uint(user.(models.User).ID), true
+}
+
+// optionalUser returns the signed in user on routes using OptionalAuthMiddleware
+func optionalUser(ctx *gin.Context) *models.User {
+	user, exists := ctx.Get("user")
+	if !exists {
+		return nil
+	}
+
+	userModel := user.(models.User)
+	return &userModel
+}
+
+func PropertyAttachmentList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, _, userID, ok := attachmentRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.PropertyAttachmentList(propertyId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func CreatePropertyAttachmentUpload(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, _, userID, ok := attachmentRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyAttachmentUploadRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.CreatePropertyAttachmentUpload(propertyId, userID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func ConfirmPropertyAttachmentUpload(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, attachmentId, userID, ok := attachmentRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.ConfirmPropertyAttachmentUpload(propertyId, attachmentId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func UpdatePropertyAttachment(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, attachmentId, userID, ok := attachmentRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyAttachmentUpdateRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.UpdatePropertyAttachment(propertyId, attachmentId, userID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func DeletePropertyAttachment(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, attachmentId, userID, ok := attachmentRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	if err := authContoller.DeletePropertyAttachment(propertyId, attachmentId, userID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+func PublicPropertyAttachmentList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	response, err := authContoller.PublicPropertyAttachments(uint32(propertyId), optionalUser(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func PropertyAttachmentDownload(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	attachmentId, err := strconv.ParseUint(ctx.Param("attachment_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	response, err := authContoller.PropertyAttachmentDownload(uint32(propertyId), uint(attachmentId), optionalUser(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,58 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// requestID is the id RequestIDMiddleware gave the current request
+func requestID(ctx *gin.Context) string {
+	return ctx.GetString("requestId")
+}
+
+func PropertyAuditLog(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.PropertyAuditLog(uint32(propertyId), page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func RestorePropertyVersion(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	auditId, err := strconv.ParseUint(ctx.Param("audit_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid audit record ID"})
+		return
+	}
+
+	user, _ := ctx.Get("user")
+	admin := user.(models.User)
+
+	response, err := authContoller.RestorePropertyVersion(uint32(propertyId), uint32(auditId), admin, requestID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,173 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// conversationRouteParams reads the conversation id and the current user id
+func conversationRouteParams(ctx *gin.Context) (uint, uint, bool) {
+	conversationId, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return 0, 0, false
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, false
+	}
+
+	return uint(conversationId), user.(models.User).ID, true
+}
+
+func StartConversation(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request dto.ConversationStartRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.StartConversation(user.(models.User), request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func ConversationList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.Conversations(user.(models.User).ID, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func UnreadMessageCount(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	response, err := authContoller.UnreadMessageCount(user.(models.User).ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func ConversationDetails(ctx *gin.Context, authContoller *controllers.AuthController) {
+	conversationId, userId, ok := conversationRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.Conversation(userId, conversationId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func ConversationMessageList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	conversationId, userId, ok := conversationRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.ConversationMessages(userId, conversationId, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func SendMessage(ctx *gin.Context, authContoller *controllers.AuthController) {
+	conversationId, userId, ok := conversationRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	var request dto.MessageRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.SendMessage(userId, conversationId, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func MarkConversationRead(ctx *gin.Context, authContoller *controllers.AuthController) {
+	conversationId, userId, ok := conversationRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.MarkConversationRead(userId, conversationId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func SetContactSharing(ctx *gin.Context, authContoller *controllers.AuthController) {
+	conversationId, userId, ok := conversationRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	var request dto.ContactSharingRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.SetContactSharing(userId, conversationId, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
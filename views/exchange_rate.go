@@ -0,0 +1,54 @@
+package views
+
+import (
+	"net/http"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+func ExchangeRateList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	response, err := authContoller.ExchangeRates()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func ExchangeRateHistory(ctx *gin.Context, authContoller *controllers.AuthController) {
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.ExchangeRateHistory(ctx.Query("currency"), page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func CreateExchangeRate(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request dto.ExchangeRateRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.CreateExchangeRate(request, user.(models.User))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
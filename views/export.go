@@ -0,0 +1,82 @@
+package views
+
+import (
+	"net/http"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportPropertyList exports the owner's listings matched by the same filters as PropertieList
+func ExportPropertyList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var filters dto.PropertyFilterDTO
+	if err := ctx.ShouldBindQuery(&filters); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter parameters", "details": err.Error()})
+		return
+	}
+
+	request, ok := bindExportRequest(ctx)
+	if !ok {
+		return
+	}
+
+	filters.OwerID = user.(models.User).ID
+
+	export, err := authContoller.ExportProperties(filters, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeExport(ctx, export)
+}
+
+// ExportUserList exports the users matched by the same filters as SystemAllUserListView
+func ExportUserList(ctx *gin.Context, authController *controllers.AuthController) {
+	filter, ok := bindUserFilter(ctx)
+	if !ok {
+		return
+	}
+
+	request, ok := bindExportRequest(ctx)
+	if !ok {
+		return
+	}
+
+	export, err := authController.ExportUsers(filter, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeExport(ctx, export)
+}
+
+func bindExportRequest(ctx *gin.Context) (dto.ExportRequestDTO, bool) {
+	var request dto.ExportRequestDTO
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return request, false
+	}
+	return request, true
+}
+
+// writeExport streams the file as a download. X-Accel-Buffering stops nginx
+// from collecting the whole file before passing it on.
+func writeExport(ctx *gin.Context, export *controllers.Export) {
+	ctx.Header("Content-Type", export.ContentType)
+	ctx.Header("Content-Disposition", `attachment; filename="`+export.FileName+`"`)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	export.Write(ctx.Request.Context(), ctx.Writer)
+}
@@ -0,0 +1,74 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// favoriteRouteParams reads the property id and the current user id
+func favoriteRouteParams(ctx *gin.Context) (uint32, uint, bool) {
+	propertyId, err := strconv.ParseUint(ctx.Param("property_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return 0, 0, false
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, false
+	}
+
+	return uint32(propertyId), user.(models.User).ID, true
+}
+
+func FavoriteList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.Favorites(user.(models.User).ID, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func AddFavorite(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, userId, ok := favoriteRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.AddFavorite(userId, propertyId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func RemoveFavorite(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, userId, ok := favoriteRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	if err := authContoller.RemoveFavorite(userId, propertyId); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
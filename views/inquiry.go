@@ -0,0 +1,125 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+func CreateInquiry(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	var request dto.InquiryRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	if _, err := authContoller.CreateInquiry(uint32(propertyId), optionalUser(ctx), ctx.ClientIP(), request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Spam that is dropped silently gets the same answer
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Inquiry sent"})
+}
+
+func OwnerInquiryList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var filter dto.InquiryFilterDTO
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter parameters", "details": err.Error()})
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.OwnerInquiries(user.(models.User).ID, filter, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// inquiryRouteParams reads the inquiry id and the current user
+func inquiryRouteParams(ctx *gin.Context) (uint, models.User, bool) {
+	inquiryId, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inquiry ID"})
+		return 0, models.User{}, false
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, models.User{}, false
+	}
+
+	return uint(inquiryId), user.(models.User), true
+}
+
+func OwnerInquiryDetails(ctx *gin.Context, authContoller *controllers.AuthController) {
+	inquiryId, user, ok := inquiryRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.OwnerInquiry(user.ID, inquiryId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func ReplyToInquiry(ctx *gin.Context, authContoller *controllers.AuthController) {
+	inquiryId, user, ok := inquiryRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	var request dto.InquiryReplyRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.ReplyToInquiry(user, inquiryId, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func CloseInquiry(ctx *gin.Context, authContoller *controllers.AuthController) {
+	inquiryId, user, ok := inquiryRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.CloseInquiry(user.ID, inquiryId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
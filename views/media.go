@@ -0,0 +1,144 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// mediaRouteParams reads the property id, optional media id and current user
+func mediaRouteParams(ctx *gin.Context, withMedia bool) (uint32, uint, uint, bool) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return 0, 0, 0, false
+	}
+
+	var mediaId uint64
+	if withMedia {
+		mediaId, err = strconv.ParseUint(ctx.Param("media_id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media ID"})
+			return 0, 0, 0, false
+		}
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, 0, false
+	}
+
+	return uint32(propertyId), uint(mediaId), uint(user.(models.User).ID), true
+}
+
+func PropertyMediaList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, _, userID, ok := mediaRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.PropertyMediaList(propertyId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func CreatePropertyMediaUpload(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, _, userID, ok := mediaRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyMediaUploadRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.CreatePropertyMediaUpload(propertyId, userID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func ConfirmPropertyMediaUpload(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, mediaId, userID, ok := mediaRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyMediaConfirmRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.ConfirmPropertyMediaUpload(propertyId, mediaId, userID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func ReorderPropertyMedia(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, _, userID, ok := mediaRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyMediaOrderRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.ReorderPropertyMedia(propertyId, userID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func SetPropertyCover(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, mediaId, userID, ok := mediaRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.SetPropertyCover(propertyId, mediaId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func DeletePropertyMedia(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, mediaId, userID, ok := mediaRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	if err := authContoller.DeletePropertyMedia(propertyId, mediaId, userID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
@@ -0,0 +1,165 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/gin-gonic/gin"
+)
+
+func PartnerList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.Partners(page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func CreatePartner(ctx *gin.Context, authContoller *controllers.AuthController) {
+	var request dto.PartnerRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.CreatePartner(request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func UpdatePartner(ctx *gin.Context, authContoller *controllers.AuthController) {
+	partnerId, ok := partnerRouteParam(ctx)
+	if !ok {
+		return
+	}
+
+	var request dto.PartnerUpdateDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.UpdatePartner(partnerId, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func RotatePartnerToken(ctx *gin.Context, authContoller *controllers.AuthController) {
+	partnerId, ok := partnerRouteParam(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.RotatePartnerToken(partnerId)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func DeletePartner(ctx *gin.Context, authContoller *controllers.AuthController) {
+	partnerId, ok := partnerRouteParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := authContoller.DeletePartner(partnerId); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func PartnerFeedXML(ctx *gin.Context, authContoller *controllers.AuthController) {
+	partnerFeed(ctx, authContoller, controllers.PartnerFeedXML)
+}
+
+func PartnerFeedJSON(ctx *gin.Context, authContoller *controllers.AuthController) {
+	partnerFeed(ctx, authContoller, controllers.PartnerFeedJSON)
+}
+
+// partnerFeed serves a feed to the partner whose token comes in the
+// Authorization header or, for portals that can only be given a URL, the
+// token query parameter
+func partnerFeed(ctx *gin.Context, authContoller *controllers.AuthController, format string) {
+	partner, err := authContoller.PartnerByToken(partnerToken(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var since *time.Time
+	if value := ctx.Query("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time, like the feed's generated_at"})
+			return
+		}
+		since = &parsed
+	}
+
+	feed, err := authContoller.PartnerFeed(*partner, format, since)
+	if errors.Is(err, controllers.ErrPartnerFeedNotReady) {
+		ctx.Header("Retry-After", "60")
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The full feed only changes when it is rebuilt
+	if since == nil {
+		if modifiedSince, err := http.ParseTime(ctx.GetHeader("If-Modified-Since")); err == nil &&
+			!feed.GeneratedAt.Truncate(time.Second).After(modifiedSince) {
+			ctx.Status(http.StatusNotModified)
+			return
+		}
+		ctx.Header("Last-Modified", feed.GeneratedAt.UTC().Format(http.TimeFormat))
+	}
+
+	ctx.Header("Content-Type", feed.ContentType)
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Status(http.StatusOK)
+
+	feed.Write(ctx.Request.Context(), ctx.Writer)
+}
+
+func partnerToken(ctx *gin.Context) string {
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		token = ctx.Query("token")
+	}
+	return token
+}
+
+func partnerRouteParam(ctx *gin.Context) (uint32, bool) {
+	partnerId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partner ID"})
+		return 0, false
+	}
+
+	return uint32(partnerId), true
+}
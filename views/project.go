@@ -0,0 +1,289 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// projectRouteParams reads the project id, optional media id and current user
+func projectRouteParams(ctx *gin.Context, withMedia bool) (uint32, uint, uint, bool) {
+	projectId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return 0, 0, 0, false
+	}
+
+	var mediaId uint64
+	if withMedia {
+		mediaId, err = strconv.ParseUint(ctx.Param("media_id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media ID"})
+			return 0, 0, 0, false
+		}
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, 0, false
+	}
+
+	return uint32(projectId), uint(mediaId), uint(user.(models.User).ID), true
+}
+
+func OwnerProjectList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.OwnerProjects(uint(user.(models.User).ID), page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func CreateProject(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request dto.ProjectRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.CreateProject(request, uint(user.(models.User).ID))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func OwnerProjectDetails(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, _, userID, ok := projectRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.OwnerProjectDetails(projectId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func UpdateProject(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, _, userID, ok := projectRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	var request dto.ProjectRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.UpdateProject(projectId, userID, request, requestID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func DeleteProject(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, _, userID, ok := projectRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	if err := authContoller.DeleteProject(projectId, userID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+func CreateProjectUnit(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, _, userID, ok := projectRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	var request dto.ProjectUnitRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.CreateProjectUnit(projectId, userID, request, requestID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func PublicProjectList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	var filter dto.ProjectFilterDTO
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.PublicProjects(filter, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func PublicProjectDetails(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	response, err := authContoller.PublicProjectDetails(uint32(projectId))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func ProjectMediaList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, _, userID, ok := projectRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.ProjectMediaList(projectId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func CreateProjectMediaUpload(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, _, userID, ok := projectRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyMediaUploadRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.CreateProjectMediaUpload(projectId, userID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func ConfirmProjectMediaUpload(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, mediaId, userID, ok := projectRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyMediaConfirmRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.ConfirmProjectMediaUpload(projectId, mediaId, userID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func ReorderProjectMedia(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, _, userID, ok := projectRouteParams(ctx, false)
+	if !ok {
+		return
+	}
+
+	var request dto.PropertyMediaOrderRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.ReorderProjectMedia(projectId, userID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func SetProjectCover(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, mediaId, userID, ok := projectRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.SetProjectCover(projectId, mediaId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func DeleteProjectMedia(ctx *gin.Context, authContoller *controllers.AuthController) {
+	projectId, mediaId, userID, ok := projectRouteParams(ctx, true)
+	if !ok {
+		return
+	}
+
+	if err := authContoller.DeleteProjectMedia(projectId, mediaId, userID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
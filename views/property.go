@@ -28,12 +28,17 @@ func PropertieList(ctx *gin.Context, authContoller *controllers.AuthController)
 		pageSize = 10 // Set reasonable limits
 	}
 
-	filters := dto.PropertyFilterDTO{
-		OwerID:  userID,
-		Page:    page,
-		PerPage: pageSize,
+	var filters dto.PropertyFilterDTO
+	if err := ctx.ShouldBindQuery(&filters); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter parameters", "details": err.Error()})
+		return
 	}
 
+	filters.OwerID = userID
+	filters.ViewerID = userID
+	filters.Page = page
+	filters.PerPage = pageSize
+
 	response, err := authContoller.GetProperties(filters)
 
 	if err != nil {
@@ -44,6 +49,62 @@ func PropertieList(ctx *gin.Context, authContoller *controllers.AuthController)
 	ctx.JSON(http.StatusOK, response)
 }
 
+func PublicPropertyList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	page, pageSize := GetPaginationParams(ctx)
+
+	var filters dto.PropertyFilterDTO
+	if err := ctx.ShouldBindQuery(&filters); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter parameters", "details": err.Error()})
+		return
+	}
+
+	// Visitors only ever see searchable listings, from any owner
+	filters.OwerID = 0
+	filters.Status = ""
+	filters.Statuses = nil
+	for _, status := range models.SearchableStatuses {
+		filters.Statuses = append(filters.Statuses, string(status))
+	}
+	filters.Page = page
+	filters.PerPage = pageSize
+	if user := optionalUser(ctx); user != nil {
+		filters.ViewerID = user.ID
+	}
+
+	response, err := authContoller.GetProperties(filters)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func PublicPropertyDetails(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	// Signed in users are counted once per day, anonymous visitors once per IP
+	// address. Client-sent ids are not trusted, a script could send a new one on
+	// every request.
+	user := optionalUser(ctx)
+	visitor := "ip:" + ctx.ClientIP()
+	if user != nil {
+		visitor = "user:" + strconv.FormatUint(uint64(user.ID), 10)
+	}
+
+	response, err := authContoller.PublicPropertyDetails(uint32(propertyId), user, visitor, ctx.Request.UserAgent(), ctx.Query("currency"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
 func CreateProperty(ctx *gin.Context, authContoller *controllers.AuthController) {
 	user, exists := ctx.Get("user")
 	if !exists {
@@ -59,7 +120,7 @@ func CreateProperty(ctx *gin.Context, authContoller *controllers.AuthController)
 		return
 	}
 
-	response, err := authContoller.CreateProperty(request, userID)
+	response, err := authContoller.CreateProperty(request, userID, requestID(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -104,7 +165,7 @@ func PropertyUpdate(ctx *gin.Context, authContoller *controllers.AuthController)
 		return
 	}
 
-	response, err := authContoller.PropertyPatch(uint32(propertyId), userID, request)
+	response, err := authContoller.PropertyPatch(uint32(propertyId), userID, request, requestID(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -113,6 +174,29 @@ func PropertyUpdate(ctx *gin.Context, authContoller *controllers.AuthController)
 	ctx.JSON(http.StatusOK, response)
 }
 
+func DeleteProperty(ctx *gin.Context, authContoller *controllers.AuthController) {
+	propertyId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userID := uint(user.(models.User).ID)
+
+	if err := authContoller.DeleteProperty(uint32(propertyId), userID, requestID(ctx)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
 func CreatePropertyFeature(ctx *gin.Context, authContoller *controllers.AuthController) {
 	idParam := ctx.Param("id")
 	propertyId, _ := strconv.ParseUint(idParam, 10, 32)
@@ -133,7 +217,7 @@ func CreatePropertyFeature(ctx *gin.Context, authContoller *controllers.AuthCont
 
 	request.PropertyID = uint(propertyId)
 
-	response, err := authContoller.CreatePropertyFeature(request, userID)
+	response, err := authContoller.CreatePropertyFeature(request, userID, requestID(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -175,7 +259,7 @@ func DeletePropertyFeature(ctx *gin.Context, authContoller *controllers.AuthCont
 
 	userID := uint(user.(models.User).ID)
 
-	err := authContoller.DeletePropertyFeature(uint32(propertyId), userID)
+	err := authContoller.DeletePropertyFeature(uint32(propertyId), userID, requestID(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
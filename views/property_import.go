@@ -0,0 +1,103 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+func PropertyImportColumns(ctx *gin.Context, authContoller *controllers.AuthController) {
+	ctx.JSON(http.StatusOK, authContoller.PropertyImportColumns())
+}
+
+func CreatePropertyImport(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(ctx.PostForm("dry_run"))
+
+	response, err := authContoller.CreatePropertyImport(user.(models.User).ID, file, dryRun, requestID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, response)
+}
+
+func PropertyImportList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.PropertyImports(user.(models.User).ID, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func PropertyImportDetails(ctx *gin.Context, authContoller *controllers.AuthController) {
+	importId, userID, ok := propertyImportRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := authContoller.PropertyImportDetails(importId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func PropertyImportReport(ctx *gin.Context, authContoller *controllers.AuthController) {
+	importId, userID, ok := propertyImportRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	report, fileName, err := authContoller.PropertyImportReport(importId, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", report)
+}
+
+func propertyImportRouteParams(ctx *gin.Context) (uint32, uint, bool) {
+	importId, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import ID"})
+		return 0, 0, false
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, false
+	}
+
+	return uint32(importId), user.(models.User).ID, true
+}
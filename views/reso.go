@@ -0,0 +1,104 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// resoResourcePath matches a collection, Property, or one record, Property('7') or Property(7)
+var resoResourcePath = regexp.MustCompile(`^(\w+)(?:\('?(\w+)'?\))?$`)
+
+// resoError answers in the OData error format
+func resoError(ctx *gin.Context, status int, code, message string) {
+	ctx.Header("OData-Version", "4.0")
+	ctx.JSON(status, gin.H{"error": gin.H{"code": code, "message": message}})
+}
+
+func resoJSON(ctx *gin.Context, body interface{}) {
+	ctx.Header("OData-Version", "4.0")
+	ctx.Header("Content-Type", "application/json; odata.metadata=minimal")
+	ctx.JSON(http.StatusOK, body)
+}
+
+// resoPartner authenticates the request with a partner token, as the listing feeds do
+func resoPartner(ctx *gin.Context, authContoller *controllers.AuthController) (*models.Partner, bool) {
+	partner, err := authContoller.PartnerByToken(partnerToken(ctx))
+	if err != nil {
+		resoError(ctx, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return nil, false
+	}
+	return partner, true
+}
+
+// resoQuery returns the query options without the token, which is not an OData option
+func resoQuery(ctx *gin.Context) url.Values {
+	values := ctx.Request.URL.Query()
+	values.Del("token")
+	return values
+}
+
+func ResoService(ctx *gin.Context, authContoller *controllers.AuthController) {
+	if _, ok := resoPartner(ctx, authContoller); !ok {
+		return
+	}
+
+	resoJSON(ctx, authContoller.ResoService())
+}
+
+func ResoMetadata(ctx *gin.Context, authContoller *controllers.AuthController) {
+	if _, ok := resoPartner(ctx, authContoller); !ok {
+		return
+	}
+
+	ctx.Header("OData-Version", "4.0")
+	ctx.Data(http.StatusOK, "application/xml", authContoller.ResoMetadata())
+}
+
+// ResoResource answers /reso/Property, /reso/Media and their single records
+func ResoResource(ctx *gin.Context, authContoller *controllers.AuthController) {
+	partner, ok := resoPartner(ctx, authContoller)
+	if !ok {
+		return
+	}
+
+	match := resoResourcePath.FindStringSubmatch(ctx.Param("resource"))
+	if match == nil {
+		resoError(ctx, http.StatusNotFound, "NotFound", "Resource not found")
+		return
+	}
+	resource, key := match[1], match[2]
+	values := resoQuery(ctx)
+
+	var body interface{}
+	var err error
+	switch {
+	case resource == "Property" && key == "":
+		body, err = authContoller.ResoProperties(*partner, values)
+	case resource == "Property":
+		body, err = authContoller.ResoProperty(*partner, key, values)
+	case resource == "Media" && key == "":
+		body, err = authContoller.ResoMediaList(*partner, values)
+	case resource == "Media":
+		body, err = authContoller.ResoMedia(*partner, key, values)
+	default:
+		resoError(ctx, http.StatusNotFound, "NotFound", "Resource not found")
+		return
+	}
+
+	if errors.Is(err, controllers.ErrResoNotFound) {
+		resoError(ctx, http.StatusNotFound, "NotFound", err.Error())
+		return
+	}
+	if err != nil {
+		resoError(ctx, http.StatusBadRequest, "BadRequest", err.Error())
+		return
+	}
+
+	resoJSON(ctx, body)
+}
@@ -0,0 +1,115 @@
+package views
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// savedSearchRouteParams reads the saved search id and the current user id
+func savedSearchRouteParams(ctx *gin.Context) (uint, uint, bool) {
+	searchId, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return 0, 0, false
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, false
+	}
+
+	return uint(searchId), user.(models.User).ID, true
+}
+
+func SavedSearchList(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, pageSize := GetPaginationParams(ctx)
+
+	response, err := authContoller.SavedSearches(user.(models.User).ID, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func CreateSavedSearch(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request dto.SavedSearchRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.CreateSavedSearch(user.(models.User).ID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func UpdateSavedSearch(ctx *gin.Context, authContoller *controllers.AuthController) {
+	searchId, userId, ok := savedSearchRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	var request dto.SavedSearchRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.UpdateSavedSearch(userId, searchId, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func DeleteSavedSearch(ctx *gin.Context, authContoller *controllers.AuthController) {
+	searchId, userId, ok := savedSearchRouteParams(ctx)
+	if !ok {
+		return
+	}
+
+	if err := authContoller.DeleteSavedSearch(userId, searchId); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+// UnsubscribeSavedSearch handles the link in alert emails. POST is accepted too
+// for mail clients that unsubscribe in the background.
+func UnsubscribeSavedSearch(ctx *gin.Context, authContoller *controllers.AuthController) {
+	response, err := authContoller.UnsubscribeSavedSearch(ctx.Query("token"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
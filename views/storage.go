@@ -0,0 +1,75 @@
+package views
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/farhapartex/real_estate_be/lib/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// LocalStorageUpload accepts a PUT to a presigned local upload URL, mirroring
+// what S3 does for a presigned PutObject
+func LocalStorageUpload(ctx *gin.Context, local *storage.LocalStorage) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	contentType, size, err := local.VerifyUpload(key, ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	if ctx.GetHeader("Content-Type") != contentType {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Content-Type does not match the signed upload"})
+		return
+	}
+
+	if ctx.Request.ContentLength != size {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Content-Length does not match the signed upload"})
+		return
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, size)
+	if err := local.Write(key, contentType, body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// LocalStorageDownload serves a file behind a presigned local download URL
+func LocalStorageDownload(ctx *gin.Context, local *storage.LocalStorage) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	if err := local.VerifyDownload(key, ctx.Request.URL.Query()); err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	info, err := local.Head(ctx.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := local.Open(key)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	if info.ContentType != "" {
+		ctx.Header("Content-Type", info.ContentType)
+	}
+
+	http.ServeContent(ctx.Writer, ctx.Request, path.Base(key), info.LastModified, io.ReadSeeker(file))
+}
@@ -0,0 +1,87 @@
+package views
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/lib/events"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+// streamHeartbeatInterval keeps proxies from closing an idle stream
+const streamHeartbeatInterval = 25 * time.Second
+
+// EventStream pushes the user's events as server-sent events. Clients resume
+// with the Last-Event-ID header (or last_event_id query parameter) after a
+// reconnect and get the events they missed, as long as they are recent.
+func EventStream(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if authContoller.Events == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Event stream is not available"})
+		return
+	}
+
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+
+	subscription := authContoller.Events.Subscribe(user.(models.User).ID, lastEventID)
+	defer authContoller.Events.Unsubscribe(subscription)
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	// Tell the browser how long to wait before reconnecting
+	fmt.Fprint(ctx.Writer, "retry: 3000\n\n")
+	for _, event := range subscription.Replay {
+		writeStreamEvent(ctx, event)
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event := <-subscription.C:
+			writeStreamEvent(ctx, event)
+		case <-heartbeat.C:
+			fmt.Fprint(ctx.Writer, "event: ping\ndata: {}\n\n")
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+func writeStreamEvent(ctx *gin.Context, event events.Event) {
+	fmt.Fprintf(ctx.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
+
+func CreateStreamTicket(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	response, err := authContoller.CreateStreamTicket(user.(models.User).ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
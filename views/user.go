@@ -13,6 +13,23 @@ import (
 func SystemAllUserListView(ctx *gin.Context, authController *controllers.AuthController) {
 	page, pageSize := GetPaginationParams(ctx)
 
+	filter, ok := bindUserFilter(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := authController.GetSystemAllUsers(page, pageSize, filter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+
+}
+
+// bindUserFilter reads the user list filters from the query string
+func bindUserFilter(ctx *gin.Context) (dto.UserFilterDTO, bool) {
 	var filter dto.UserFilterDTO
 
 	if err := ctx.ShouldBindQuery(&filter); err != nil {
@@ -20,7 +37,7 @@ func SystemAllUserListView(ctx *gin.Context, authController *controllers.AuthCon
 			"error":   "Invalid filter parameters",
 			"details": err.Error(),
 		})
-		return
+		return filter, false
 	}
 
 	emailVerifiedStr := ctx.Query("email_verified")
@@ -42,12 +59,5 @@ func SystemAllUserListView(ctx *gin.Context, authController *controllers.AuthCon
 		filter.EmailVerified = nil
 	}
 
-	response, err := authController.GetSystemAllUsers(page, pageSize, filter)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	ctx.JSON(http.StatusOK, response)
-
+	return filter, true
 }
@@ -0,0 +1,90 @@
+package views
+
+import (
+	"net/http"
+
+	"github.com/farhapartex/real_estate_be/controllers"
+	"github.com/farhapartex/real_estate_be/dto"
+	"github.com/farhapartex/real_estate_be/models"
+	"github.com/gin-gonic/gin"
+)
+
+func WatermarkSetting(ctx *gin.Context, authContoller *controllers.AuthController) {
+	response, err := authContoller.GetWatermarkSetting()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func UpdateWatermarkSetting(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, _ := ctx.Get("user")
+	admin := user.(models.User)
+
+	var request dto.WatermarkSettingRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.UpdateWatermarkSetting(admin, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func UploadWatermarkLogo(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, _ := ctx.Get("user")
+	admin := user.(models.User)
+
+	file, err := ctx.FormFile("logo")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "logo file is required"})
+		return
+	}
+
+	response, err := authContoller.UploadWatermarkLogo(admin, file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func RerenderWatermarks(ctx *gin.Context, authContoller *controllers.AuthController) {
+	response, err := authContoller.RerenderWatermarks()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, response)
+}
+
+func UpdateOwnerWatermark(ctx *gin.Context, authContoller *controllers.AuthController) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var request dto.OwnerWatermarkRequestDTO
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data", "details": err.Error()})
+		return
+	}
+
+	response, err := authContoller.UpdateOwnerWatermark(user.(models.User).ID, request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, response)
+}